@@ -55,11 +55,10 @@ func (v VALUE) ARRAY() ARRAY {
 	}
 }
 
+// NewArray returns a new ARRAY of size zero values of TYPE r
 func NewArray(r *TYPE, size int) ARRAY {
-	t := FromReflectType(reflect.ArrayOf(size, toType(r)))
-	b := make([]byte, t.size)
-	p := **(**unsafe.Pointer)(unsafe.Pointer(&b))
-	return ARRAY{t, p, flagIndir | flagAddr | flag(Array)}
+	t := ArrayTypeOf(size, r)
+	return ARRAY{t, unsafe_New(t), flagIndir | flagAddr | flag(Array)}
 }
 
 // ------------------------------------------------------------ /
@@ -91,6 +90,17 @@ func (a ARRAY) index(i int) VALUE {
 	}.SetType()
 }
 
+// elem returns the value at index i of ARRAY,
+// elems of interface kind are not converted to their type
+func (a ARRAY) elem(i int) VALUE {
+	t := (*arrayType)(unsafe.Pointer(a.typ))
+	return VALUE{
+		t.elem,
+		unsafe.Pointer(uintptr(a.ptr) + uintptr(i)*t.elem.size),
+		a.flag&(flagIndir|flagAddr) | flag(t.elem.Kind()),
+	}
+}
+
 // ForEach executes function f on each item in ARRAY,
 // note: k equals "" at each item
 func (a ARRAY) ForEach(f func(i int, k string, v VALUE) (brake bool)) {
@@ -146,10 +156,15 @@ func (a ARRAY) Pointer() unsafe.Pointer {
 
 // Encode returns a gotype encoding of ARRAY
 func (a ARRAY) Encode() ENCODING {
-	l := a.Len()
-	e := append([]byte{a.typ.Kind().Byte(), (*arrayType)(unsafe.Pointer(a.typ)).elem.Kind().Byte()}, lenBytes(l)...)
+	return a.encode(&encoder{})
+}
+
+// encode returns the encoding of ARRAY with the pointers already encoded in enc
+func (a ARRAY) encode(enc *encoder) ENCODING {
+	l, t := a.Len(), (*arrayType)(unsafe.Pointer(a.typ)).elem
+	e := append([]byte{a.typ.Kind().Byte(), t.Kind().Byte()}, lenBytes(l)...)
 	for i := 0; i < l; i++ {
-		e = append(e, a.Index(i).Encode()...)
+		e = append(e, a.index(i).encodeElem(t, enc)...)
 	}
 	return e
 }
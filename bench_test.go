@@ -7,6 +7,8 @@ package gotype
 import (
 	"bytes"
 	"encoding/json"
+	"reflect"
+	"strconv"
 	"testing"
 	"time"
 )
@@ -118,6 +120,56 @@ func BenchmarkDecode(b *testing.B) {
 	}
 }
 
+func BenchmarkEncodeCompressed(b *testing.B) {
+	type event struct {
+		ID   int64
+		Kind string
+		At   time.Time
+		Seq  []uint64
+	}
+	t0 := time.Unix(1700000000, 0)
+	evs := make([]event, 1000)
+	for i := range evs {
+		evs[i] = event{int64(i), []string{"create", "update", "delete"}[i%3], t0.Add(time.Duration(i) * time.Second), []uint64{uint64(i), uint64(i + 1)}}
+	}
+	profiles := []struct {
+		name string
+		c    Compression
+	}{{"None", 0}, {"Varint", CompressVarint}, {"Varint|Delta", CompressVarint | CompressDelta}, {"Dict", CompressDict}, {"All", CompressAll}}
+	b.Run(STRING("Encode(Standard)").Width(28), func(b *testing.B) {
+		var e ENCODING
+		for i := 0; i < b.N; i++ {
+			e = Encode(evs)
+		}
+		b.ReportMetric(float64(len(e)), "bytes")
+	})
+	for _, p := range profiles {
+		b.Run(STRING("Encode("+p.name+")").Width(28), func(b *testing.B) {
+			var e ENCODING
+			for i := 0; i < b.N; i++ {
+				e = EncodeCompressed(evs, p.c)
+			}
+			b.ReportMetric(float64(len(e)), "bytes")
+		})
+	}
+	b.Run(STRING("Decode(Standard)").Width(28), func(b *testing.B) {
+		e := Encode(evs)
+		for i := 0; i < b.N; i++ {
+			var d []event
+			e.Decode(&d)
+		}
+	})
+	for _, p := range profiles {
+		b.Run(STRING("Decode("+p.name+")").Width(28), func(b *testing.B) {
+			e := EncodeCompressed(evs, p.c)
+			for i := 0; i < b.N; i++ {
+				var d []event
+				e.Decode(&d)
+			}
+		})
+	}
+}
+
 func BenchmarkCast(b *testing.B) {
 	type conv struct {
 		name string
@@ -209,4 +261,153 @@ func BenchmarkCast(b *testing.B) {
 	}
 }
 
-// Benchmark Cast
+func BenchmarkMap(b *testing.B) {
+	m := map[string]int{}
+	for i := 0; i < 100; i++ {
+		m[strconv.Itoa(i)] = i
+	}
+	g, r := MapOf(m), reflect.ValueOf(m)
+	b.Run(STRING("Len").Width(24), func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			g.Len()
+		}
+	})
+	b.Run(STRING("reflect.Len").Width(24), func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			r.Len()
+		}
+	})
+	b.Run(STRING("Index").Width(24), func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			g.Index("50")
+		}
+	})
+	k := reflect.ValueOf("50")
+	b.Run(STRING("reflect.MapIndex").Width(24), func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			r.MapIndex(k)
+		}
+	})
+	b.Run(STRING("Set").Width(24), func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			g.Set("50", 50)
+		}
+	})
+	v := reflect.ValueOf(50)
+	b.Run(STRING("reflect.SetMapIndex").Width(24), func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			r.SetMapIndex(k, v)
+		}
+	})
+	b.Run(STRING("ForEach").Width(24), func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			g.ForEach(func(i int, k string, v VALUE) (brake bool) {
+				return
+			})
+		}
+	})
+	b.Run(STRING("reflect.MapRange").Width(24), func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for it := r.MapRange(); it.Next(); {
+				it.Key()
+				it.Value()
+			}
+		}
+	})
+	b.Run(STRING("Keys").Width(24), func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			g.Keys()
+		}
+	})
+	b.Run(STRING("reflect.MapKeys").Width(24), func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			r.MapKeys()
+		}
+	})
+	b.Run(STRING("Values").Width(24), func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			g.Values()
+		}
+	})
+	b.Run(STRING("Encode").Width(24), func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			g.Encode()
+		}
+	})
+	b.Run(STRING("Marshal").Width(24), func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			(VALUE)(g).Marshal(JsonMarshaler)
+		}
+	})
+}
+
+func BenchmarkCore(b *testing.B) {
+	s := string_struct{"s", "s"}
+	var a any = s
+	v, p := ValueOf(&s), ValueOf(&a).Elem()
+	t, c := TypeOf(s), TypeOf(cacheStringer{})
+	b.Run(STRING("ValueOf").Width(24), func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			ValueOf(a)
+		}
+	})
+	b.Run(STRING("reflect.ValueOf").Width(24), func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			FromReflect(reflect.ValueOf(a))
+		}
+	})
+	b.Run(STRING("Elem").Width(24), func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			v.Elem()
+		}
+	})
+	b.Run(STRING("reflect.Elem").Width(24), func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			FromReflect(v.Reflect().Elem())
+		}
+	})
+	b.Run(STRING("SetType").Width(24), func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			p.SetType()
+		}
+	})
+	b.Run(STRING("reflect.SetType").Width(24), func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			FromReflect(p.Reflect().Elem())
+		}
+	})
+	b.Run(STRING("PtrType").Width(24), func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			t.PtrType()
+		}
+	})
+	b.Run(STRING("reflect.PtrTo").Width(24), func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			FromReflectType(reflect.PtrTo(toType(t)))
+		}
+	})
+	b.Run(STRING("TagValues").Width(24), func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			t.TagValues("json")
+		}
+	})
+	b.Run(STRING("reflect.Tag").Width(24), func(b *testing.B) {
+		r := toType(t)
+		for i := 0; i < b.N; i++ {
+			for j := 0; j < r.NumField(); j++ {
+				r.Field(j).Tag.Get("json")
+			}
+		}
+	})
+	b.Run(STRING("Method").Width(24), func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			c.method("String")
+		}
+	})
+	b.Run(STRING("reflect.MethodByName").Width(24), func(b *testing.B) {
+		r := toType(c)
+		for i := 0; i < b.N; i++ {
+			r.MethodByName("String")
+		}
+	})
+}
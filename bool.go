@@ -48,10 +48,14 @@ func (v VALUE) Bool() bool {
 			return UINT(*(*uint32)(v.ptr)).Bool()
 		case Uint64:
 			return UINT(*(*uint64)(v.ptr)).Bool()
+		case Uintptr:
+			return *(*uintptr)(v.ptr) != 0
 		case Float32:
 			return FLOAT(*(*float32)(v.ptr)).Bool()
 		case Float64:
 			return FLOAT(*(*float64)(v.ptr)).Bool()
+		case Complex64, Complex128:
+			return v.COMPLEX().Bool()
 		case Array:
 			return (ARRAY)(v).Bool()
 		case Map:
@@ -125,20 +125,27 @@ func (b BYTES) Uint() uint {
 	return i
 }
 
-// Float64 returns gotype BYTES as Float64
+// Float64 returns gotype BYTES as Float64, reading 4 bytes
+// as the bits of a float32 and 8 bytes as the bits of a float64
 func (b BYTES) Float64() float64 {
-	i := float64(0)
 	l := len(b)
 	switch l {
-	case 1, 2, 4, 8:
-		p := unsafe.Pointer(&i)
+	case 4:
+		f := float32(0)
+		p := unsafe.Pointer(&f)
 		for n := 0; n < l; n++ {
 			*(*uint8)(offseti(p, n)) = b[n]
 		}
-	default:
-		panic("cannot convert to float64")
+		return float64(f)
+	case 8:
+		f := float64(0)
+		p := unsafe.Pointer(&f)
+		for n := 0; n < l; n++ {
+			*(*uint8)(offseti(p, n)) = b[n]
+		}
+		return f
 	}
-	return i
+	panic("cannot convert to float64")
 }
 
 // Float returns gotype BYTES as a gotype FLOAT
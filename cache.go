@@ -0,0 +1,140 @@
+// Copyright 2023 james dotter. All rights reserved.
+// Use of this source code is governed by a
+// license that can be found in the gotype LICENSE file.
+
+package gotype
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// ------------------------------------------------------------ /
+// TYPE METADATA CACHE IMPLEMENTATION
+// metadata of TYPEs computed on first use and cached for
+// the life of the program, including pointer types, field
+// names, offsets and tags, tag values, method tables,
+// encoding schemas and validation rules
+// ------------------------------------------------------------ /
+
+// typeCache is a map of *TYPE to *typeMeta
+var typeCache sync.Map
+
+// typeMeta is the cached metadata of a TYPE
+type typeMeta struct {
+	ptr     atomic.Pointer[TYPE] // TYPE of a pointer to the TYPE
+	once    sync.Once            // guards fields
+	fields  []fieldMeta          // fields of a struct TYPE
+	tags    sync.Map             // map of tag name to *tagMeta
+	methods sync.Map             // map of method name to *methodMeta
+	schema  atomic.Pointer[typeSchema]
+	rules   atomic.Pointer[[]fieldRules] // validate rules of the fields of a struct TYPE
+}
+
+// fieldMeta is the cached metadata of a struct field
+type fieldMeta struct {
+	typ    *TYPE
+	offset uintptr
+	name_  name
+	name   string
+	rawtag string
+}
+
+// tagMeta is the cached values of a tag across struct fields
+type tagMeta struct {
+	vals []string // value of the tag in each field
+	has  bool     // true if every field has a value for the tag
+}
+
+// methodMeta is the cached lookup of a method by name
+type methodMeta struct {
+	index int     // index of the method in the method set, -1 if not found
+	numIn int     // number of input parameters including the receiver
+	out   []*TYPE // TYPEs of the output parameters
+}
+
+// noMethod is the methodMeta of a method not found
+var noMethod = &methodMeta{index: -1}
+
+// meta returns the cached metadata of the TYPE
+func (t *TYPE) meta() *typeMeta {
+	if m, ok := typeCache.Load(t); ok {
+		return m.(*typeMeta)
+	}
+	m, _ := typeCache.LoadOrStore(t, &typeMeta{})
+	return m.(*typeMeta)
+}
+
+// ptrType returns the cached TYPE of a pointer to the TYPE
+func (t *TYPE) ptrType() *TYPE {
+	if t.ptrToThis != 0 {
+		return (*TYPE)(resolveTypeOff(unsafe.Pointer(t), int32(t.ptrToThis)))
+	}
+	m := t.meta()
+	if p := m.ptr.Load(); p != nil {
+		return p
+	}
+	p := FromReflectType(reflect.PtrTo(toType(t)))
+	m.ptr.Store(p)
+	return p
+}
+
+// fieldMetas returns the cached metadata of the fields of a struct TYPE
+func (t *TYPE) fieldMetas() []fieldMeta {
+	m := t.meta()
+	m.once.Do(func() {
+		fs := (*structType)(unsafe.Pointer(t)).fields
+		m.fields = make([]fieldMeta, len(fs))
+		for i, f := range fs {
+			m.fields[i] = fieldMeta{f.typ, f.offset, f.name, f.name.name(), f.name.tag()}
+		}
+	})
+	return m.fields
+}
+
+// tagValues returns the cached values of tag across the fields
+// of a struct TYPE, has is false if any field lacks a value
+func (t *TYPE) tagValues(tag string) *tagMeta {
+	m := t.meta()
+	if tm, ok := m.tags.Load(tag); ok {
+		return tm.(*tagMeta)
+	}
+	fs := t.fieldMetas()
+	tm := &tagMeta{make([]string, len(fs)), true}
+	for i, f := range fs {
+		if tm.vals[i] = getTagValue(f.rawtag, tag, 34); tm.vals[i] == "" {
+			tm.has = false
+		}
+	}
+	m.tags.Store(tag, tm)
+	return tm
+}
+
+// method returns the cached lookup of the method
+// with name in the method set of the TYPE
+func (t *TYPE) method(name string) *methodMeta {
+	if t.tflag&tflagUncommon == 0 && t.Kind() != Interface {
+		return noMethod // types without an uncommon type have no methods
+	}
+	m := t.meta()
+	if mm, ok := m.methods.Load(name); ok {
+		return mm.(*methodMeta)
+	}
+	mm := &methodMeta{index: -1}
+	if meth, ok := toType(t).MethodByName(name); ok {
+		mm.index, mm.numIn = meth.Index, meth.Type.NumIn()
+		for i := 0; i < meth.Type.NumOut(); i++ {
+			mm.out = append(mm.out, FromReflectType(meth.Type.Out(i)))
+		}
+	}
+	m.methods.Store(name, mm)
+	return mm
+}
+
+// callString calls the method at index of VALUE v, which takes no
+// inputs, and returns its first output as a string
+func (v VALUE) callString(index int) string {
+	return v.Reflect().Method(index).Call(nil)[0].String()
+}
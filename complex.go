@@ -0,0 +1,264 @@
+// Copyright 2023 james dotter. All rights reserved.
+// Use of this source code is governed by a
+// license that can be found in the gotype LICENSE file.
+
+package gotype
+
+import (
+	"math"
+	"math/cmplx"
+	"strconv"
+	"unsafe"
+)
+
+// ------------------------------------------------------------ /
+// GOTYPE CUSTOM TYPE IMPLEMENTATION
+// implementation of custom type of complex128
+// enabling seemless type conversion
+// consolidated standard golang funtionality in single pkg
+// and expanded transformation and computation functionality
+// ------------------------------------------------------------ /
+
+type COMPLEX complex128
+
+// COMPLEX returns gotype VALUE as gotype COMPLEX
+func (v VALUE) COMPLEX() COMPLEX {
+	return COMPLEX(v.Complex128())
+}
+
+// Complex128 returns gotype VALUE as complex128, converting
+// numbers to their real part, strings such as "1+2i" and
+// arrays or slices of two numbers [real, imag]
+func (v VALUE) Complex128() complex128 {
+	switch v.Kind() {
+	case Complex128:
+		return *(*complex128)(v.ptr)
+	case Complex64:
+		return complex128(*(*complex64)(v.ptr))
+	case Pointer:
+		return v.Elem().Complex128()
+	case Interface:
+		if e := v.SetType(); e.Kind() != Interface {
+			return e.Complex128()
+		}
+	default:
+		switch k := v.KIND(); k {
+		case String:
+			return (*STRING)(v.ptr).Complex128()
+		case Bytes:
+			return STRING(*(*[]byte)(v.ptr)).Complex128()
+		case Array, Slice:
+			if v.Len() == 2 {
+				if k == Array {
+					return complex((ARRAY)(v).index(0).Float64(), (ARRAY)(v).index(1).Float64())
+				}
+				return complex((SLICE)(v).index(0).Float64(), (SLICE)(v).index(1).Float64())
+			}
+		case Uintptr:
+			return complex(float64(*(*uintptr)(v.ptr)), 0)
+		default:
+			if k.IsNumeric() || k == Bool {
+				return complex(v.Float64(), 0)
+			}
+		}
+	}
+	panic("cannot convert value to complex")
+}
+
+// Complex64 returns gotype VALUE as complex64
+func (v VALUE) Complex64() complex64 {
+	return v.COMPLEX().Complex64()
+}
+
+// isComplexKind returns true if k is Complex64 or Complex128
+func isComplexKind(k KIND) bool {
+	return k == Complex64 || k == Complex128
+}
+
+// ------------------------------------------------------------ /
+// TYPE CONVERSION FUNCTIONS
+// implementation of functions to convert values to new types
+// ------------------------------------------------------------ /
+
+// Native returns gotype COMPLEX as a golang complex128
+func (c COMPLEX) Native() complex128 {
+	return complex128(c)
+}
+
+// Interface returns gotype COMPLEX as a golang interface{}
+func (c COMPLEX) Interface() any {
+	return c.Native()
+}
+
+// VALUE returns gotype COMPLEX as gotype VALUE
+func (c COMPLEX) VALUE() VALUE {
+	return ValueOf(complex128(c))
+}
+
+// Encode returns a gotype encoding of COMPLEX
+func (c COMPLEX) Encode() ENCODING {
+	return append([]byte{byte(Complex128)}, c.Bytes()...)
+}
+
+// Bytes returns gotype COMPLEX as []byte
+func (c COMPLEX) Bytes() []byte {
+	b := make([]byte, 16)
+	p := unsafe.Pointer(&c)
+	for i := range b {
+		b[i] = *(*uint8)(offset(p, uintptr(i)))
+	}
+	return b
+}
+
+// String returns gotype COMPLEX as a string of the form "1+2i"
+func (c COMPLEX) String() string {
+	return formatComplex(complex128(c), 128)
+}
+
+// formatComplex returns c as a string of the form "1+2i",
+// with the precision of complex numbers of bitSize
+func formatComplex(c complex128, bitSize int) string {
+	s := strconv.FormatComplex(c, 'f', -1, bitSize)
+	return s[1 : len(s)-1]
+}
+
+// STRING returns gotype COMPLEX as a gotype STRING
+func (c COMPLEX) STRING() STRING {
+	return STRING(c.String())
+}
+
+// Bool returns gotype COMPLEX as a bool
+// false if 0, otherwise true
+func (c COMPLEX) Bool() bool {
+	return c != 0
+}
+
+// BOOL returns gotype COMPLEX as a gotype BOOL
+// false if 0, otherwise true
+func (c COMPLEX) BOOL() BOOL {
+	return BOOL(c.Bool())
+}
+
+// Complex64 returns golang complex64 of gotype COMPLEX
+func (c COMPLEX) Complex64() complex64 {
+	if math.Abs(real(c)) > math.MaxFloat32 && !math.IsInf(real(c), 0) ||
+		math.Abs(imag(c)) > math.MaxFloat32 && !math.IsInf(imag(c), 0) {
+		panic("overflow error: Complex greater than max complex64")
+	}
+	return complex64(c)
+}
+
+// Complex128 returns golang complex128 of gotype COMPLEX
+func (c COMPLEX) Complex128() complex128 {
+	return complex128(c)
+}
+
+// Float64 returns the real part of gotype COMPLEX as float64,
+// panics if COMPLEX has an imaginary part
+func (c COMPLEX) Float64() float64 {
+	if imag(c) != 0 {
+		panic("cannot convert complex with imaginary part to float64")
+	}
+	return real(c)
+}
+
+// FLOAT returns the real part of gotype COMPLEX as a gotype FLOAT,
+// panics if COMPLEX has an imaginary part
+func (c COMPLEX) FLOAT() FLOAT {
+	return FLOAT(c.Float64())
+}
+
+// Array returns gotype COMPLEX as [real, imag]
+func (c COMPLEX) Array() [2]float64 {
+	return [2]float64{real(c), imag(c)}
+}
+
+// ------------------------------------------------------------ /
+// EXPANDED FUNCTIONS
+// implementations of new functions for
+// complex128
+// referenced packages: math/cmplx
+// ------------------------------------------------------------ /
+
+// Real returns the real part of COMPLEX
+func (c COMPLEX) Real() float64 {
+	return real(c)
+}
+
+// Imag returns the imaginary part of COMPLEX
+func (c COMPLEX) Imag() float64 {
+	return imag(c)
+}
+
+// Abs returns the absolute value (modulus) of COMPLEX
+func (c COMPLEX) Abs() float64 {
+	return cmplx.Abs(complex128(c))
+}
+
+// Phase returns the phase (argument) of COMPLEX in radians
+func (c COMPLEX) Phase() float64 {
+	return cmplx.Phase(complex128(c))
+}
+
+// Conj returns the complex conjugate of COMPLEX
+func (c COMPLEX) Conj() complex128 {
+	return cmplx.Conj(complex128(c))
+}
+
+// IsNaN returns true if either part of COMPLEX is NaN and neither is infinite
+func (c COMPLEX) IsNaN() bool {
+	return cmplx.IsNaN(complex128(c))
+}
+
+// IsInf returns true if either part of COMPLEX is infinite
+func (c COMPLEX) IsInf() bool {
+	return cmplx.IsInf(complex128(c))
+}
+
+// Add increases COMPLEX by val, panics if exceeds overflow limit
+func (c COMPLEX) Add(val complex128) complex128 {
+	MustFloatAdd(real(c), real(val))
+	MustFloatAdd(imag(c), imag(val))
+	return complex128(c) + val
+}
+
+// Sub decreases COMPLEX by val, panics if exceeds overflow limit
+func (c COMPLEX) Sub(val complex128) complex128 {
+	MustFloatAdd(real(c), -real(val))
+	MustFloatAdd(imag(c), -imag(val))
+	return complex128(c) - val
+}
+
+// Multiply multiplies COMPLEX by val, panics if exceeds overflow limit
+func (c COMPLEX) Multiply(val complex128) complex128 {
+	r := complex128(c) * val
+	if cmplx.IsInf(r) && !c.IsInf() && !COMPLEX(val).IsInf() {
+		panic("overflow error: greater than max complex")
+	}
+	return r
+}
+
+// Divide divides COMPLEX by val
+func (c COMPLEX) Divide(val complex128) complex128 {
+	return complex128(c) / val
+}
+
+// Pow returns COMPLEX raised to val
+func (c COMPLEX) Pow(val complex128) complex128 {
+	return cmplx.Pow(complex128(c), val)
+}
+
+// Sqrt returns the square root of COMPLEX
+func (c COMPLEX) Sqrt() complex128 {
+	return cmplx.Sqrt(complex128(c))
+}
+
+// Exp returns e raised to COMPLEX
+func (c COMPLEX) Exp() complex128 {
+	return cmplx.Exp(complex128(c))
+}
+
+// Log returns the natural logarithm of COMPLEX
+func (c COMPLEX) Log() complex128 {
+	return cmplx.Log(complex128(c))
+}
@@ -0,0 +1,574 @@
+// Copyright 2023 james dotter. All rights reserved.
+// Use of this source code is governed by a
+// license that can be found in the gotype LICENSE file.
+
+package gotype
+
+import (
+	"encoding/binary"
+)
+
+// ------------------------------------------------------------ /
+// GOTYPE COMPRESSED ENCODING
+// encoding profile trading encode and decode speed for size,
+// with variable length integers, delta encoded sorted slices
+// and a dictionary of repeated strings
+// ------------------------------------------------------------ /
+
+// COMPRESSED ENCODING FORMAT
+// []byte{CompressedHeader, Compression, Encoding...}
+//
+// Encoding is the gotype encoding, including any schema header,
+// with values replaced by the options of Compression:
+//
+// Option			Values									Compressed
+// CompressVarint	Int, Int8, Int16, Int32, Int64, Time	Kind, zig-zag varint of the value
+//					Uint, Uint8, ..., Uint64, Uintptr		Kind, uvarint of the value
+//					Len of all values						uvarint of the Len
+// CompressDelta	Slice, Array of ints, uints and Time	Kind, ElemKind, Len, 0x0, Elems... if not sorted ascending
+//															Kind, ElemKind, Len, 0x1, varint of the first elem,
+//															uvarint of the difference of each following elem
+// CompressDict		String									Kind, uvarint of Len+1, bytes... on first use
+//															Kind, 0x0, uvarint of Id on repeat use of strings of
+//															at least 2 bytes, where Id counts the first uses from 0
+//
+// Compressed encodings are decompressed to the gotype encoding when
+// decoded, so that decoding is slower and allocates the decompressed encoding.
+// BenchmarkEncodeCompressed measures the size and speed of each option
+
+const CompressedHeader byte = 0x83 // first byte of a compressed encoding
+
+// Compression is the set of options of a compressed encoding
+type Compression byte
+
+const (
+	CompressVarint Compression = 1 << iota // encode integers, times and lengths as varints
+	CompressDelta                          // delta encode sorted slices and arrays of integers and times
+	CompressDict                           // encode repeated strings as ids of a dictionary
+
+	CompressAll = CompressVarint | CompressDelta | CompressDict
+)
+
+// dictMinLen is the minimum length of strings added to the dictionary
+const dictMinLen = 2
+
+// EncodeCompressed returns the encoding of VALUE compressed by the options c
+func (v VALUE) EncodeCompressed(c Compression) ENCODING {
+	return v.Encode().Compress(c)
+}
+
+// EncodeCompressed encodes any value to bytes compressed by the options c
+func EncodeCompressed(a any, c Compression) ENCODING {
+	return ValueOfV(a).EncodeCompressed(c)
+}
+
+// isCompressed returns true if the encoding is compressed
+func (e ENCODING) isCompressed() bool {
+	return len(e) > 0 && e[0] == CompressedHeader
+}
+
+// Compress returns the encoding compressed by the options c, see
+// COMPRESSED ENCODING FORMAT. Compressed encodings are decompressed
+// before compressing by c, panics if the encoding is corrupt
+func (e ENCODING) Compress(c Compression) ENCODING {
+	if e.isCompressed() {
+		d, _, err := e.decompress()
+		if err != nil {
+			panic(err)
+		}
+		e = d
+	}
+	z := &compressor{c: c, e: e, b: make(ENCODING, 2, len(e))}
+	z.b[0], z.b[1] = CompressedHeader, byte(c)
+	i := 0
+	if e.hasHeader() {
+		_, _, i = e.header()
+		z.b = append(z.b, e[:i]...)
+	}
+	z.value(i)
+	return z.b
+}
+
+// Decompress returns the gotype encoding of a compressed encoding,
+// or the encoding if it is not compressed. Returns a DecodeError if
+// the encoding is truncated or corrupt or exceeds the decode limits
+func (e ENCODING) Decompress() (ENCODING, error) {
+	if !e.isCompressed() {
+		return e, nil
+	}
+	d, _, err := e.decompress()
+	return d, err
+}
+
+// decompress returns the gotype encoding of the compressed encoding
+// and the number of bytes of the compressed encoding decompressed
+func (e ENCODING) decompress() (d ENCODING, n int, err error) {
+	if err = e.need(2); err != nil {
+		return
+	}
+	c := Compression(e[1])
+	if c&^CompressAll != 0 {
+		return nil, 0, &DecodeError{1, ErrCorrupt}
+	}
+	z := &decompressor{c: c, e: e, b: make(ENCODING, 0, 2*len(e))}
+	i := 2
+	if e[i:].hasHeader() {
+		h, err := e[i:].validateHeader()
+		if err != nil {
+			if de, ok := err.(*DecodeError); ok {
+				de.Offset += i
+			}
+			return nil, 0, err
+		}
+		z.b = append(z.b, e[i:i+h]...)
+		i += h
+	}
+	if n, err = z.value(i, 0); err != nil {
+		return nil, 0, err
+	}
+	return z.b, n, nil
+}
+
+// compressor holds the state of compressing an encoding
+type compressor struct {
+	c    Compression
+	e    ENCODING       // encoding compressed
+	b    ENCODING       // compressed encoding
+	dict map[string]int // ids of the strings of the dictionary
+}
+
+// value compresses the value at offset i of the encoding
+// and returns the offset of the end of the value
+func (z *compressor) value(i int) int {
+	t := z.e[i]
+	z.b = append(z.b, t)
+	switch t {
+	case encRef:
+		l, n := z.e.decodeLen(uintptr(i + 1))
+		z.len(l)
+		return n
+	case encTyped:
+		l, n := z.e.decodeLen(uintptr(i + 1))
+		z.len(l)
+		return z.value(z.raw(n, l))
+	}
+	switch k := KIND(t); k {
+	case Invalid:
+		return i + 1
+	case Pointer:
+		return z.value(i + 1)
+	case Int, Int8, Int16, Int32, Int64, Uint, Uint8, Uint16, Uint32, Uint64, Uintptr, Time:
+		return z.int(k, i+1)
+	case Bool:
+		return z.raw(i+1, 1)
+	case Float32, Float64, Complex64, Complex128:
+		return z.raw(i+1, int(k.Size()))
+	case Uuid:
+		return z.raw(i+1, 16)
+	case String:
+		return z.string(i + 1)
+	case Bytes:
+		l, n := z.e.decodeLen(uintptr(i + 1))
+		z.len(l)
+		return z.raw(n, l)
+	case Array, Slice:
+		ek := KIND(z.e[i+1])
+		z.b = append(z.b, z.e[i+1])
+		l, n := z.e.decodeLen(uintptr(i + 2))
+		z.len(l)
+		if z.c&CompressDelta != 0 && isIntKind(ek) {
+			return z.delta(ek, l, n)
+		}
+		return z.elems(l, n)
+	case Map:
+		z.b = append(z.b, z.e[i+1], z.e[i+2])
+		l, n := z.e.decodeLen(uintptr(i + 3))
+		z.len(l)
+		return z.elems(2*l, n)
+	case Struct:
+		l, n := z.e.decodeLen(uintptr(i + 1))
+		z.len(l)
+		return z.elems(l, n)
+	}
+	panic("cannot compress encoding of " + KIND(t).String())
+}
+
+// elems compresses l values from offset i of the encoding
+// and returns the offset of the end of the values
+func (z *compressor) elems(l int, i int) int {
+	for j := 0; j < l; j++ {
+		i = z.value(i)
+	}
+	return i
+}
+
+// raw copies l bytes from offset i of the encoding
+// and returns the offset of the end of the bytes
+func (z *compressor) raw(i int, l int) int {
+	z.b = append(z.b, z.e[i:i+l]...)
+	return i + l
+}
+
+// len compresses the length l
+func (z *compressor) len(l int) {
+	if z.c&CompressVarint != 0 {
+		z.b = binary.AppendUvarint(z.b, uint64(l))
+		return
+	}
+	z.b = append(z.b, lenBytes(l)...)
+}
+
+// int compresses the bytes at offset i of the encoding of an
+// integer of kind k and returns the offset of the end of the bytes
+func (z *compressor) int(k KIND, i int) int {
+	s := intSize(k)
+	if z.c&CompressVarint == 0 {
+		return z.raw(i, s)
+	}
+	z.b = appendVarint(z.b, k, encodedInt(z.e[i:i+s], k))
+	return i + s
+}
+
+// delta compresses l elems of kind k from offset i of the encoding
+// as differences if they are sorted, returns the offset of their end
+func (z *compressor) delta(k KIND, l int, i int) int {
+	s := intSize(k) + 1
+	sorted := true
+	for j, p := 1, i+1; j < l && sorted; j, p = j+1, p+s {
+		a, b := encodedInt(z.e[p:p+s-1], k), encodedInt(z.e[p+s:p+2*s-1], k)
+		if isSignedKind(k) {
+			sorted = int64(a) <= int64(b)
+		} else {
+			sorted = a <= b
+		}
+	}
+	if !sorted {
+		z.b = append(z.b, 0)
+		return z.elems(l, i)
+	}
+	z.b = append(z.b, 1)
+	var p uint64
+	for j := 0; j < l; j, i = j+1, i+s {
+		u := encodedInt(z.e[i+1:i+s], k)
+		if j == 0 {
+			z.b = appendVarint(z.b, k, u)
+		} else {
+			z.b = binary.AppendUvarint(z.b, u-p)
+		}
+		p = u
+	}
+	return i
+}
+
+// string compresses the string at offset i of the encoding
+// and returns the offset of the end of the string
+func (z *compressor) string(i int) int {
+	l, n := z.e.decodeLen(uintptr(i))
+	if z.c&CompressDict == 0 {
+		z.len(l)
+		return z.raw(n, l)
+	}
+	if l >= dictMinLen {
+		if id, ok := z.dict[string(z.e[n:n+l])]; ok {
+			z.b = binary.AppendUvarint(append(z.b, 0), uint64(id))
+			return n + l
+		}
+		if z.dict == nil {
+			z.dict = map[string]int{}
+		}
+		z.dict[string(z.e[n:n+l])] = len(z.dict)
+	}
+	z.b = binary.AppendUvarint(z.b, uint64(l)+1)
+	return z.raw(n, l)
+}
+
+// decompressor holds the state of decompressing an encoding
+type decompressor struct {
+	c    Compression
+	e    ENCODING // compressed encoding
+	b    ENCODING // decompressed encoding
+	dict [][]byte // strings of the dictionary by id
+	refd int      // bytes of strings decompressed from the dictionary
+}
+
+// value decompresses the value at offset i of the compressed encoding
+// at nesting depth and returns the offset of the end of the value
+func (z *decompressor) value(i int, depth int) (n int, err error) {
+	if depth > MaxDecodeDepth {
+		return i, &DecodeError{i, ErrMaxDepth}
+	}
+	if err = z.e.need(i + 1); err != nil {
+		return
+	}
+	t := z.e[i]
+	z.b = append(z.b, t)
+	var l int
+	switch t {
+	case encRef:
+		_, n, err = z.len(i+1, 0)
+		return
+	case encTyped:
+		if l, n, err = z.len(i+1, 1); err != nil {
+			return
+		}
+		if n, err = z.raw(n, l); err != nil {
+			return
+		}
+		return z.value(n, depth+1)
+	}
+	switch k := KIND(t); k {
+	case Invalid:
+		return i + 1, nil
+	case Pointer:
+		return z.value(i+1, depth+1)
+	case Int, Int8, Int16, Int32, Int64, Uint, Uint8, Uint16, Uint32, Uint64, Uintptr, Time:
+		return z.int(k, i+1)
+	case Bool:
+		return z.raw(i+1, 1)
+	case Float32, Float64, Complex64, Complex128:
+		return z.raw(i+1, int(k.Size()))
+	case Uuid:
+		return z.raw(i+1, 16)
+	case String:
+		return z.string(i + 1)
+	case Bytes:
+		if l, n, err = z.len(i+1, 1); err != nil {
+			return
+		}
+		return z.raw(n, l)
+	case Array, Slice:
+		if err = z.e.need(i + 2); err != nil {
+			return
+		}
+		ek := KIND(z.e[i+1])
+		if ek > Bytes {
+			return i, &DecodeError{i + 1, ErrCorrupt}
+		}
+		z.b = append(z.b, z.e[i+1])
+		if l, n, err = z.len(i+2, 1); err != nil {
+			return
+		}
+		if z.c&CompressDelta != 0 && isIntKind(ek) {
+			return z.delta(ek, l, n, depth)
+		}
+		return z.elems(l, n, depth)
+	case Map:
+		if err = z.e.need(i + 3); err != nil {
+			return
+		}
+		z.b = append(z.b, z.e[i+1], z.e[i+2])
+		if l, n, err = z.len(i+3, 2); err != nil {
+			return
+		}
+		return z.elems(2*l, n, depth)
+	case Struct:
+		if l, n, err = z.len(i+1, 1); err != nil {
+			return
+		}
+		return z.elems(l, n, depth)
+	}
+	return i, &DecodeError{i, ErrCorrupt}
+}
+
+// elems decompresses l values from offset i of the compressed encoding
+// at nesting depth and returns the offset of the end of the values
+func (z *decompressor) elems(l int, i int, depth int) (n int, err error) {
+	n = i
+	for j := 0; j < l && err == nil; j++ {
+		n, err = z.value(n, depth+1)
+	}
+	return
+}
+
+// raw copies l bytes from offset i of the compressed encoding
+// and returns the offset of the end of the bytes
+func (z *decompressor) raw(i int, l int) (int, error) {
+	if err := z.e.need(i + l); err != nil {
+		return i, err
+	}
+	z.b = append(z.b, z.e[i:i+l]...)
+	return i + l, nil
+}
+
+// varint returns the zig-zag varint at offset i of the compressed
+// encoding if signed or the uvarint, and the offset of its end
+func (z *decompressor) varint(i int, signed bool) (u uint64, n int, err error) {
+	if err = z.e.need(i + 1); err != nil {
+		return
+	}
+	if signed {
+		var v int64
+		v, n = binary.Varint(z.e[i:])
+		u = uint64(v)
+	} else {
+		u, n = binary.Uvarint(z.e[i:])
+	}
+	switch {
+	case n == 0:
+		err = &DecodeError{len(z.e), ErrTruncated}
+	case n < 0:
+		err = &DecodeError{i, ErrCorrupt}
+	}
+	return u, i + n, err
+}
+
+// len decompresses the length at offset i of the compressed encoding,
+// which counts values of at least min bytes each if min is not 0, and
+// returns the length and the offset of the end of the length
+func (z *decompressor) len(i int, min int) (l int, n int, err error) {
+	if z.c&CompressVarint == 0 {
+		l, n, err = z.e.validateLen(i, min)
+	} else {
+		var u uint64
+		if u, n, err = z.varint(i, false); err != nil {
+			return
+		}
+		switch {
+		case u > uint64(MaxDecodeLen):
+			err = &DecodeError{i, ErrMaxLen}
+		case min > 0 && int(u) > (len(z.e)-n)/min:
+			err = &DecodeError{len(z.e), ErrTruncated}
+		}
+		l = int(u)
+	}
+	if err == nil {
+		z.b = append(z.b, lenBytes(l)...)
+	}
+	return
+}
+
+// int decompresses the integer of kind k at offset i of the
+// compressed encoding and returns the offset of its end
+func (z *decompressor) int(k KIND, i int) (int, error) {
+	s := intSize(k)
+	if z.c&CompressVarint == 0 {
+		return z.raw(i, s)
+	}
+	u, n, err := z.varint(i, isSignedKind(k))
+	if err == nil {
+		z.b = appendInt(z.b, u, s)
+	}
+	return n, err
+}
+
+// delta decompresses l elems of kind k from offset i of the compressed
+// encoding at nesting depth and returns the offset of their end
+func (z *decompressor) delta(k KIND, l int, i int, depth int) (n int, err error) {
+	if err = z.e.need(i + 1); err != nil {
+		return
+	}
+	switch z.e[i] {
+	case 0:
+		return z.elems(l, i+1, depth)
+	case 1:
+	default:
+		return i, &DecodeError{i, ErrCorrupt}
+	}
+	s, n := intSize(k), i+1
+	var u, p uint64
+	for j := 0; j < l; j++ {
+		if u, n, err = z.varint(n, j == 0 && isSignedKind(k)); err != nil {
+			return
+		}
+		if j > 0 {
+			u += p
+		}
+		z.b = appendInt(append(z.b, byte(k)), u, s)
+		p = u
+	}
+	return
+}
+
+// string decompresses the string at offset i of the compressed
+// encoding and returns the offset of the end of the string
+func (z *decompressor) string(i int) (n int, err error) {
+	var l int
+	if z.c&CompressDict == 0 {
+		if l, n, err = z.len(i, 1); err != nil {
+			return
+		}
+		return z.raw(n, l)
+	}
+	var u uint64
+	if u, n, err = z.varint(i, false); err != nil {
+		return
+	}
+	if u == 0 {
+		if u, n, err = z.varint(n, false); err != nil {
+			return
+		}
+		if u >= uint64(len(z.dict)) {
+			return i, &DecodeError{i, ErrCorrupt}
+		}
+		s := z.dict[u]
+		if z.refd += len(s); z.refd > MaxDecodeLen {
+			return i, &DecodeError{i, ErrMaxLen}
+		}
+		z.b = append(append(z.b, lenBytes(len(s))...), s...)
+		return
+	}
+	if u-1 > uint64(MaxDecodeLen) {
+		return i, &DecodeError{i, ErrMaxLen}
+	}
+	if l = int(u - 1); l >= dictMinLen && n+l <= len(z.e) {
+		z.dict = append(z.dict, z.e[n:n+l])
+	}
+	z.b = append(z.b, lenBytes(l)...)
+	return z.raw(n, l)
+}
+
+// isIntKind returns true if values of kind k are encoded as integers
+func isIntKind(k KIND) bool {
+	switch k {
+	case Int, Int8, Int16, Int32, Int64, Uint, Uint8, Uint16, Uint32, Uint64, Uintptr, Time:
+		return true
+	}
+	return false
+}
+
+// isSignedKind returns true if values of kind k are encoded as signed integers
+func isSignedKind(k KIND) bool {
+	switch k {
+	case Int, Int8, Int16, Int32, Int64, Time:
+		return true
+	}
+	return false
+}
+
+// intSize returns the number of bytes of the encoding
+// of an integer of kind k, excluding its kind
+func intSize(k KIND) int {
+	if k == Time {
+		return 8
+	}
+	return int(k.Size())
+}
+
+// encodedInt returns the little endian integer of kind k encoded
+// in bytes b, sign extended to 64 bits if k is signed
+func encodedInt(b []byte, k KIND) (u uint64) {
+	for j := len(b) - 1; j >= 0; j-- {
+		u = u<<8 | uint64(b[j])
+	}
+	if s := len(b); isSignedKind(k) && s < 8 && b[s-1]&0x80 != 0 {
+		u |= ^uint64(0) << (8 * s)
+	}
+	return
+}
+
+// appendVarint appends integer u of kind k to b as a
+// zig-zag varint if k is signed or as a uvarint
+func appendVarint(b []byte, k KIND, u uint64) []byte {
+	if isSignedKind(k) {
+		return binary.AppendVarint(b, int64(u))
+	}
+	return binary.AppendUvarint(b, u)
+}
+
+// appendInt appends the s little endian bytes of integer u to b
+func appendInt(b []byte, u uint64, s int) []byte {
+	for j := 0; j < s; j++ {
+		b = append(b, byte(u>>(8*j)))
+	}
+	return b
+}
@@ -0,0 +1,410 @@
+// Copyright 2023 james dotter. All rights reserved.
+// Use of this source code is governed by a
+// license that can be found in the gotype LICENSE file.
+
+package gotype
+
+import (
+	"errors"
+	goflag "flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unsafe"
+)
+
+// ------------------------------------------------------------ /
+// CONFIG IMPLEMENTATION
+// population of a config struct from layered sources:
+// default tags, json, yaml and toml files,
+// environment variables and command line flags
+// ------------------------------------------------------------ /
+
+// ConfigSource is a source of config values
+type ConfigSource uint8
+
+const (
+	SourceNone    ConfigSource = iota // field not set by any source
+	SourceDefault                     // field set from its default tag
+	SourceFile                        // field set from a config file
+	SourceEnv                         // field set from an environment variable
+	SourceFlag                        // field set from a command line flag
+)
+
+var configSourceNames = [...]string{"none", "default", "file", "env", "flag"}
+
+// String returns the name of the ConfigSource
+func (s ConfigSource) String() string {
+	if int(s) < len(configSourceNames) {
+		return configSourceNames[s]
+	}
+	return "unknown"
+}
+
+// ConfigOrigin identifies the source that set a config field
+type ConfigOrigin struct {
+	Source ConfigSource // the source of the field's value
+	Key    string       // the file path, env var or flag that set the field
+}
+
+// ConfigReport maps the path of each config field,
+// eg. "DB.Host", to the source that set it
+type ConfigReport map[string]ConfigOrigin
+
+// String returns the ConfigReport as sorted lines of "path: source key"
+func (r ConfigReport) String() string {
+	paths := make([]string, 0, len(r))
+	for p := range r {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	var b strings.Builder
+	for _, p := range paths {
+		o := r[p]
+		b.WriteString(p + ": " + o.Source.String())
+		if o.Key != "" {
+			b.WriteString(" " + o.Key)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// ConfigOption configures the sources used by LoadConfig
+type ConfigOption func(*configLoader)
+
+// ConfigEnvPrefix sets the prefix of the environment variables,
+// eg. "APP" reads field DB.Host from APP_DB_HOST
+func ConfigEnvPrefix(prefix string) ConfigOption {
+	return func(c *configLoader) {
+		c.prefix = prefix
+	}
+}
+
+// ConfigEnv sets the function used to look up environment variables,
+// defaults to os.LookupEnv
+func ConfigEnv(lookup func(key string) (string, bool)) ConfigOption {
+	return func(c *configLoader) {
+		c.lookup = lookup
+	}
+}
+
+// ConfigFiles adds config files to read, the format of each file
+// is determined by its extension: .json, .yaml, .yml or .toml.
+// Files are applied in order so later files override earlier files
+func ConfigFiles(paths ...string) ConfigOption {
+	return func(c *configLoader) {
+		c.files = append(c.files, paths...)
+	}
+}
+
+// ConfigArgs sets the command line arguments parsed for flags,
+// eg. os.Args[1:]. Flags are generated for each config field
+func ConfigArgs(args []string) ConfigOption {
+	return func(c *configLoader) {
+		c.args = args
+	}
+}
+
+// ConfigFlagSet sets the flag set the generated flags are added to,
+// defaults to a new flag set that returns parse errors
+func ConfigFlagSet(fs *goflag.FlagSet) ConfigOption {
+	return func(c *configLoader) {
+		c.flags = fs
+	}
+}
+
+// ConfigPrecedence sets the order in which sources are applied from
+// lowest to highest precedence, sources not listed are not applied.
+// Defaults to SourceDefault, SourceFile, SourceEnv, SourceFlag
+func ConfigPrecedence(sources ...ConfigSource) ConfigOption {
+	return func(c *configLoader) {
+		c.order = sources
+	}
+}
+
+// LoadConfig populates the struct pointed to by dest from the sources
+// configured and returns a report of the source that set each field.
+// Fields are configured with the tags:
+//
+//	default:"value"  the default value of the field
+//	env:"NAME"       the env var name of the field, or of the nested struct
+//	                 prefixing its fields, defaults to the upper snake case name
+//	flag:"name"      the flag name of the field, defaults to the lower snake case
+//	                 path with struct names separated by '.', eg. "db.host"
+//	usage:"text"     the usage of the field's flag
+//	json, yaml, toml the key of the field in config files, defaults to the field name
+//
+// and env:"-", flag:"-" or a file format tag of "-" excludes the field from the source
+func LoadConfig(dest any, opts ...ConfigOption) (report ConfigReport, err error) {
+	c := &configLoader{
+		lookup: os.LookupEnv,
+		order:  []ConfigSource{SourceDefault, SourceFile, SourceEnv, SourceFlag},
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	d := ValueOfV(dest)
+	if d.Kind() != Pointer || d.IsNil() || d.Elem().KIND() != Struct {
+		return nil, errors.New("config: dest must be a non nil pointer to a struct")
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("config: %v", r)
+		}
+	}()
+	c.fields((STRUCT)(d.Elem()), nil, "", "")
+	report = ConfigReport{}
+	for _, s := range c.order {
+		switch s {
+		case SourceDefault:
+			err = c.applyDefaults(report)
+		case SourceFile:
+			err = c.applyFiles(report)
+		case SourceEnv:
+			err = c.applyEnv(report)
+		case SourceFlag:
+			err = c.applyFlags(report)
+		}
+		if err != nil {
+			return
+		}
+	}
+	for _, f := range c.leaves {
+		if _, ok := report[f.path]; !ok {
+			report[f.path] = ConfigOrigin{}
+		}
+	}
+	return
+}
+
+type configLoader struct {
+	prefix string
+	lookup func(string) (string, bool)
+	files  []string
+	args   []string
+	flags  *goflag.FlagSet
+	order  []ConfigSource
+	leaves []configField
+}
+
+// configField is a configurable field of the config struct
+type configField struct {
+	path  string  // path of Go field names, eg. "DB.Host"
+	env   string  // env var name, "" if excluded
+	flag  string  // flag name, "" if excluded
+	keys  []FIELD // fields from the root struct to the field
+	field FIELD   // the field
+	value VALUE   // the addressable field value
+}
+
+// fields collects the configurable fields of STRUCT s, nested structs
+// are walked into and nil struct pointers are allocated,
+// env and fl are the env var and flag name prefixes of the fields
+func (c *configLoader) fields(s STRUCT, parents []FIELD, env, fl string) {
+	s.ForFields(true, func(i int, f FIELD) (brake bool) {
+		if !f.Visible() {
+			return
+		}
+		keys := append(parents[:len(parents):len(parents)], f)
+		fenv, ffl := f.Tag("env"), f.Tag("flag")
+		if fenv == "" {
+			fenv = STRING(STRING(f.name).ToSnake()).ToUpper()
+		}
+		if ffl == "" {
+			ffl = STRING(f.name).ToSnake()
+		}
+		fenv, ffl = configName(env, fenv, "_"), configName(fl, ffl, ".")
+		v := f.VALUE()
+		if v.Kind() == Pointer && v.typ.Elem().KIND() == Struct {
+			if v.IsNil() {
+				*(*unsafe.Pointer)(v.ptr) = v.typ.Elem().New().ptr
+			}
+			v = v.Elem()
+		}
+		if v.KIND() == Struct {
+			c.fields((STRUCT)(v), keys, fenv, ffl)
+			return
+		}
+		names := make([]string, len(keys))
+		for i, k := range keys {
+			names[i] = k.name
+		}
+		cf := configField{path: strings.Join(names, "."), keys: keys, field: f, value: v}
+		if fenv != "-" {
+			cf.env = configName(c.prefix, fenv, "_")
+		}
+		if ffl != "-" {
+			cf.flag = ffl
+		}
+		c.leaves = append(c.leaves, cf)
+		return
+	})
+}
+
+// applyDefaults sets zero value fields from their default tag
+func (c *configLoader) applyDefaults(report ConfigReport) error {
+	for _, f := range c.leaves {
+		if d, ok := f.field.Tags()["default"]; ok && f.value.IsZero() {
+			if err := setDefault(f.value, d); err != nil {
+				return fmt.Errorf("config: %s: %w", f.path, err)
+			}
+			report[f.path] = ConfigOrigin{SourceDefault, ""}
+		}
+	}
+	return nil
+}
+
+// applyFiles sets fields from the keys of each config file
+func (c *configLoader) applyFiles(report ConfigReport) error {
+	for _, path := range c.files {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("config: %w", err)
+		}
+		var data any
+		format := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+		switch format {
+		case "json":
+			m := JsonMarshaler.New()
+			m.UnmarshalTyped = true
+			data = m.Unmarshal(b).Value()
+		case "yaml", "yml":
+			format = "yaml"
+			m := YamlMarshaler.New()
+			m.UnmarshalTyped = true
+			data = m.Unmarshal(b).Value()
+		case "toml":
+			if data, err = parseToml(b); err != nil {
+				return fmt.Errorf("config: %s: %w", path, err)
+			}
+		default:
+			return errors.New("config: unsupported file format '" + path + "'")
+		}
+		mg := &merger{zeros: true, tag: "merge", keyTag: format}
+		for _, f := range c.leaves {
+			v, ok := configLookup(data, f.keys, format)
+			if !ok {
+				continue
+			}
+			if s, is := v.(string); is {
+				if err = setDefault(f.value, s); err != nil {
+					return fmt.Errorf("config: %s: %s: %w", path, f.path, err)
+				}
+			} else if err = mg.merge(f.value, ValueOf(v), f.path); err != nil {
+				return fmt.Errorf("config: %s: %w", path, err)
+			}
+			report[f.path] = ConfigOrigin{SourceFile, path}
+		}
+	}
+	return nil
+}
+
+// applyEnv sets fields from their environment variables
+func (c *configLoader) applyEnv(report ConfigReport) error {
+	for _, f := range c.leaves {
+		if f.env == "" {
+			continue
+		}
+		if s, ok := c.lookup(f.env); ok {
+			if err := setDefault(f.value, s); err != nil {
+				return fmt.Errorf("config: %s: %w", f.path, err)
+			}
+			report[f.path] = ConfigOrigin{SourceEnv, f.env}
+		}
+	}
+	return nil
+}
+
+// applyFlags generates flags for each field and sets
+// the fields from the flags in the command line arguments
+func (c *configLoader) applyFlags(report ConfigReport) error {
+	fs := c.flags
+	if fs == nil {
+		fs = goflag.NewFlagSet("config", goflag.ContinueOnError)
+	}
+	set := map[string]string{}
+	for _, f := range c.leaves {
+		if f.flag == "" {
+			continue
+		}
+		name := f.flag
+		usage := f.field.Tag("usage")
+		if d := f.field.Tag("default"); d != "" {
+			usage += " (default " + d + ")"
+		}
+		if f.value.KIND() == Bool {
+			fs.BoolFunc(name, usage, func(s string) error {
+				set[name] = s
+				return nil
+			})
+			continue
+		}
+		fs.Func(name, usage, func(s string) error {
+			set[name] = s
+			return nil
+		})
+	}
+	if err := fs.Parse(c.args); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	for _, f := range c.leaves {
+		if s, ok := set[f.flag]; ok && f.flag != "" {
+			if err := setDefault(f.value, s); err != nil {
+				return fmt.Errorf("config: %s: %w", f.path, err)
+			}
+			report[f.path] = ConfigOrigin{SourceFlag, "-" + f.flag}
+		}
+	}
+	return nil
+}
+
+// configName joins name to prefix with sep,
+// returns "-" if either is excluded with "-"
+func configName(prefix, name, sep string) string {
+	switch {
+	case prefix == "-" || name == "-":
+		return "-"
+	case prefix == "":
+		return name
+	}
+	return prefix + sep + name
+}
+
+// configLookup returns the value in the unmarshalled data
+// at the key path of fields, keys are matched using the tag
+// of the file format, the field name or its lower snake case
+func configLookup(data any, keys []FIELD, format string) (any, bool) {
+	for _, f := range keys {
+		m, ok := data.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		found := false
+		for _, k := range configKeys(f, format) {
+			if data, found = m[k]; found {
+				break
+			}
+		}
+		if !found {
+			return nil, false
+		}
+	}
+	return data, true
+}
+
+// configKeys returns the keys that may identify field f in a config file,
+// or none if the field is excluded from the file format
+func configKeys(f FIELD, format string) []string {
+	keys := make([]string, 0, 4)
+	switch k, _, _ := strings.Cut(f.Tag(format), ","); k {
+	case "-":
+		return nil
+	case "":
+	default:
+		keys = append(keys, k)
+	}
+	return append(keys, f.name, strings.ToLower(f.name), STRING(f.name).ToSnake())
+}
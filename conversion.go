@@ -0,0 +1,267 @@
+// Copyright 2023 james dotter. All rights reserved.
+// Use of this source code is governed by a
+// license that can be found in the gotype LICENSE file.
+
+package gotype
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// ------------------------------------------------------------ /
+// CONVERSION POLICY IMPLEMENTATION
+// handling of numeric conversions that overflow the target kind
+// or lose precision, applied by Cast, Set, Scan and UnmarshalInto
+// ------------------------------------------------------------ /
+
+// ConversionPolicy determines how numeric conversions handle
+// values that overflow the target kind or lose precision
+type ConversionPolicy uint8
+
+const (
+	ConvertDefault              ConversionPolicy = iota // apply the DefaultConversion policy
+	ConvertTruncate                                     // wrap overflowing values and truncate fractions
+	ConvertSaturate                                     // clamp overflowing values to the min or max of the kind
+	ConvertErrorOnOverflow                              // error on overflowing values, truncate fractions
+	ConvertErrorOnPrecisionLoss                         // error on overflowing values, fractions and inexact floats
+)
+
+// DefaultConversion is the ConversionPolicy applied by Cast, Set and Scan,
+// which panic with a ConversionError on failure, and by conversions using
+// ConvertDefault. Defaults to ConvertTruncate, set to a stricter policy to
+// opt in to overflow errors. Should be set before conversions are run
+// concurrently
+var DefaultConversion = ConvertTruncate
+
+var (
+	ErrOverflow      = errors.New("value overflows kind")
+	ErrPrecisionLoss = errors.New("value loses precision")
+)
+
+// policy returns the policy applied for ConversionPolicy p
+func (p ConversionPolicy) policy() ConversionPolicy {
+	if p == ConvertDefault {
+		if DefaultConversion == ConvertDefault {
+			return ConvertTruncate
+		}
+		return DefaultConversion
+	}
+	return p
+}
+
+// CastWith returns VALUE cast to KIND k, converting numbers using the
+// ConversionPolicy p. Returns a ConversionError if v cannot be cast
+func (v VALUE) CastWith(k KIND, p ConversionPolicy) (r any, err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			r, err = nil, conversionError(v, k, e)
+		}
+	}()
+	return v.cast(k, p.policy()), nil
+}
+
+// SetWith sets the value of VALUE to a, converting numbers using
+// the ConversionPolicy p. Returns a ConversionError if a cannot be set
+func (v VALUE) SetWith(a any, p ConversionPolicy) (r VALUE, err error) {
+	n := ValueOfV(a)
+	v = v.SetType()
+	defer func() {
+		if e := recover(); e != nil {
+			r, err = v, conversionError(n, v.tryKind(), e)
+		}
+	}()
+	return v.set(n, p.policy()), nil
+}
+
+// conversionError returns the recovered panic e of converting
+// VALUE v to KIND k as a ConversionError
+func conversionError(v VALUE, k KIND, e any) error {
+	if err, ok := e.(*ConversionError); ok {
+		return err
+	}
+	var in any
+	if v.typ != nil {
+		in = valueInterface(v)
+	}
+	err, ok := e.(error)
+	if !ok {
+		err = fmt.Errorf("%v", e)
+	}
+	return &ConversionError{v.tryKind(), k, in, err}
+}
+
+// number is a numeric value read from a VALUE
+type number struct {
+	class uint8 // numInt, numUint or numFloat
+	i     int64
+	u     uint64
+	f     float64
+}
+
+const (
+	numInt uint8 = iota
+	numUint
+	numFloat
+)
+
+// numberOf reads the number held in VALUE n to convert to KIND k,
+// strings are parsed as ints, uints or floats, bytes are read as the
+// class of k and other kinds are converted to int
+func numberOf(n VALUE, k KIND) (num number, err error) {
+	n = n.SetType()
+	if n.Kind() == Pointer {
+		n = n.ElemDeep()
+	}
+	switch n.KIND() {
+	case Int, Int8, Int16, Int32, Int64:
+		return number{class: numInt, i: int64(n.Int())}, nil
+	case Uint, Uint8, Uint16, Uint32, Uint64, Uintptr:
+		return number{class: numUint, u: uint64(n.Uint())}, nil
+	case Float32, Float64:
+		return number{class: numFloat, f: n.Float64()}, nil
+	case Complex64, Complex128:
+		c := n.Complex128()
+		if imag(c) != 0 {
+			return num, fmt.Errorf("%w: imaginary part of %v", ErrPrecisionLoss, c)
+		}
+		return number{class: numFloat, f: real(c)}, nil
+	case Bytes:
+		defer func() {
+			if e := recover(); e != nil {
+				err = fmt.Errorf("%v", e)
+			}
+		}()
+		switch k {
+		case Float32, Float64:
+			return number{class: numFloat, f: n.Float64()}, nil
+		case Uint, Uint8, Uint16, Uint32, Uint64, Uintptr:
+			return number{class: numUint, u: uint64(n.Uint())}, nil
+		}
+		return number{class: numInt, i: int64(n.Int())}, nil
+	case String:
+		s := n.String()
+		if i, e := strconv.ParseInt(s, 10, 64); e == nil {
+			return number{class: numInt, i: i}, nil
+		}
+		if u, e := strconv.ParseUint(s, 10, 64); e == nil {
+			return number{class: numUint, u: u}, nil
+		}
+		f, e := strconv.ParseFloat(s, 64)
+		if e != nil {
+			return num, e
+		}
+		return number{class: numFloat, f: f}, nil
+	}
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("%v", e)
+		}
+	}()
+	return number{class: numInt, i: int64(n.Int())}, nil
+}
+
+// convertNumber sets the addressable numeric VALUE d to the number
+// held by VALUE n, applying ConversionPolicy p on overflow or
+// precision loss, returns a ConversionError if p does not permit it
+func convertNumber(d, n VALUE, p ConversionPolicy) error {
+	k := d.KIND()
+	num, err := numberOf(n, k)
+	if err != nil {
+		return conversionError(n, k, err)
+	}
+	fail := func(e error) error {
+		return &ConversionError{n.tryKind(), k, valueInterface(n), e}
+	}
+	if k == Float32 || k == Float64 {
+		f := num.f
+		switch num.class {
+		case numInt:
+			f = float64(num.i)
+			if p == ConvertErrorOnPrecisionLoss && (f >= 0x1p63 || int64(f) != num.i) {
+				return fail(ErrPrecisionLoss)
+			}
+		case numUint:
+			f = float64(num.u)
+			if p == ConvertErrorOnPrecisionLoss && (f >= 0x1p64 || uint64(f) != num.u) {
+				return fail(ErrPrecisionLoss)
+			}
+		}
+		if k == Float64 {
+			*(*float64)(d.ptr) = f
+			return nil
+		}
+		if math.Abs(f) > math.MaxFloat32 && !math.IsInf(f, 0) {
+			switch p {
+			case ConvertSaturate:
+				f = math.Copysign(math.MaxFloat32, f)
+			case ConvertErrorOnOverflow, ConvertErrorOnPrecisionLoss:
+				return fail(ErrOverflow)
+			}
+		} else if p == ConvertErrorOnPrecisionLoss && float64(float32(f)) != f && !math.IsNaN(f) {
+			return fail(ErrPrecisionLoss)
+		}
+		*(*float32)(d.ptr) = float32(f)
+		return nil
+	}
+	bits := int(d.typ.size * 8)
+	signed := k == Int || k == Int8 || k == Int16 || k == Int32 || k == Int64
+	var lo int64
+	hi := uint64(math.MaxUint64) >> (64 - bits)
+	if signed {
+		lo, hi = -1<<(bits-1), hi>>1
+	}
+	var r uint64
+	over, lost := false, false
+	switch num.class {
+	case numInt:
+		r = uint64(num.i)
+		over = num.i < lo || (num.i > 0 && uint64(num.i) > hi)
+	case numUint:
+		r = num.u
+		over = num.u > hi
+	case numFloat:
+		t := math.Trunc(num.f)
+		lost = t != num.f && !math.IsNaN(num.f)
+		switch {
+		case math.IsNaN(num.f):
+			over = true
+		case signed:
+			over = t < -math.Ldexp(1, bits-1) || t >= math.Ldexp(1, bits-1)
+			r = uint64(int64(t))
+		default:
+			over = t < 0 || t >= math.Ldexp(1, bits)
+			r = uint64(t)
+		}
+	}
+	if over {
+		switch p {
+		case ConvertSaturate:
+			switch {
+			case math.IsNaN(num.f) && num.class == numFloat:
+				r = 0
+			case (num.class == numInt && num.i < 0) || (num.class == numFloat && num.f < 0):
+				r = uint64(lo)
+			default:
+				r = hi
+			}
+		case ConvertErrorOnOverflow, ConvertErrorOnPrecisionLoss:
+			return fail(ErrOverflow)
+		}
+	} else if lost && p == ConvertErrorOnPrecisionLoss {
+		return fail(ErrPrecisionLoss)
+	}
+	switch d.typ.size {
+	case 1:
+		*(*uint8)(d.ptr) = uint8(r)
+	case 2:
+		*(*uint16)(d.ptr) = uint16(r)
+	case 4:
+		*(*uint32)(d.ptr) = uint32(r)
+	default:
+		*(*uint64)(d.ptr) = r
+	}
+	return nil
+}
@@ -0,0 +1,331 @@
+// Copyright 2023 james dotter. All rights reserved.
+// Use of this source code is governed by a
+// license that can be found in the gotype LICENSE file.
+
+package gotype
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// ------------------------------------------------------------ /
+// GOTYPE SAFE DECODING
+// validation of untrusted encodings read from disk or the
+// network before decoding, returning errors rather than
+// panicking on truncated, corrupt or oversized encodings
+// ------------------------------------------------------------ /
+
+// MaxDecodeDepth and MaxDecodeLen limit the encodings decoded by DecodeE
+// and Validate. Should be set before encodings are decoded concurrently
+var (
+	MaxDecodeDepth = 64      // maximum nesting depth of containers
+	MaxDecodeLen   = 1 << 24 // maximum length of strings, bytes and containers
+)
+
+var (
+	ErrTruncated = errors.New("encoding is truncated")
+	ErrCorrupt   = errors.New("encoding is corrupt")
+	ErrMaxDepth  = errors.New("encoding exceeds max decode depth")
+	ErrMaxLen    = errors.New("encoding exceeds max decode length")
+)
+
+// DecodeError is returned by DecodeE and Validate when
+// an encoding cannot be decoded to the destination
+type DecodeError struct {
+	Offset int   // offset of the error in the encoding, -1 if not at an offset
+	Err    error // the reason decoding failed
+}
+
+// Error returns the DecodeError as a string
+func (e *DecodeError) Error() string {
+	if e.Offset < 0 {
+		return "cannot decode: " + e.Err.Error()
+	}
+	return fmt.Sprintf("cannot decode at byte %d: %s", e.Offset, e.Err)
+}
+
+// Unwrap returns the reason decoding failed
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeE decodes e to pointer dest and returns the number of
+// bytes decoded, see ENCODING.DecodeE
+func DecodeE(e ENCODING, dest any) (int, error) {
+	return e.DecodeE(dest)
+}
+
+// DecodeE validates the encoding and decodes it into pointer dest, returns
+// the number of bytes decoded. Returns a DecodeError if the encoding is
+// truncated, corrupt or exceeds the decode limits or if dest does not match
+// the encoding, a SchemaError if dest is incompatible with the schema header
+// of the encoding or a ConversionError if a value cannot be set to dest
+func (e ENCODING) DecodeE(dest any) (n int, err error) {
+	if e.isCompressed() {
+		var d ENCODING
+		if d, n, err = e.decompress(); err == nil {
+			_, err = d.DecodeE(dest)
+		}
+		if err != nil {
+			n = 0
+		}
+		return
+	}
+	if _, err = e.Validate(); err != nil {
+		return
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			n, err = 0, decodeError(r)
+		}
+	}()
+	return e.Decode(dest), nil
+}
+
+// decodeError returns the recovered panic r of decoding as an error
+func decodeError(r any) error {
+	switch err := r.(type) {
+	case *DecodeError, *SchemaError, *ConversionError:
+		return err.(error)
+	case error:
+		return &DecodeError{-1, err}
+	}
+	return &DecodeError{-1, fmt.Errorf("%v", r)}
+}
+
+// Validate checks that the encoding is well formed and within
+// MaxDecodeDepth and MaxDecodeLen, returns the number of bytes
+// of the encoded value or a DecodeError if it is not valid.
+// Compressed encodings are validated after decompressing
+func (e ENCODING) Validate() (int, error) {
+	if e.isCompressed() {
+		d, n, err := e.decompress()
+		if err == nil {
+			_, err = d.Validate()
+		}
+		if err != nil {
+			return 0, err
+		}
+		return n, nil
+	}
+	i, err := e.validateHeader()
+	if err != nil {
+		return 0, err
+	}
+	ptrs := 0
+	return e.validate(i, 0, &ptrs)
+}
+
+// validateHeader validates the schema header of the encoding and
+// returns the offset of the end of the header, 0 if it has no header
+func (e ENCODING) validateHeader() (int, error) {
+	if !e.hasHeader() {
+		return 0, nil
+	}
+	if len(e) < 11 {
+		return 0, &DecodeError{len(e), ErrTruncated}
+	}
+	if e[1] == 0 || e[1] > EncodingVersion {
+		return 0, &DecodeError{1, ErrEncodingVersion}
+	}
+	n, err := e.validateSchema(10, 0)
+	if err != nil {
+		return 0, err
+	}
+	if fingerprint(e[10:n]) != binary.LittleEndian.Uint64(e[2:10]) {
+		return 0, &DecodeError{2, ErrCorrupt}
+	}
+	return n, nil
+}
+
+// need returns a DecodeError if the encoding has less than n bytes
+func (e ENCODING) need(n int) error {
+	if n > len(e) || n < 0 {
+		return &DecodeError{len(e), ErrTruncated}
+	}
+	return nil
+}
+
+// validateLen validates the length at offset i of the encoding, which
+// counts elems of at least min bytes each if min is not 0, and returns
+// the length and offset of the end of the length
+func (e ENCODING) validateLen(i int, min int) (l int, n int, err error) {
+	if err = e.need(i + 1); err != nil {
+		return
+	}
+	switch KIND(e[i]) {
+	case Uint8:
+		n = i + 2
+	case Uint16:
+		n = i + 3
+	case Uint32:
+		n = i + 5
+	case Uint:
+		n = i + 9
+	default:
+		return 0, 0, &DecodeError{i, ErrCorrupt}
+	}
+	if err = e.need(n); err != nil {
+		return
+	}
+	l, _ = e.decodeLen(uintptr(i))
+	switch {
+	case l < 0:
+		err = &DecodeError{i, ErrCorrupt}
+	case l > MaxDecodeLen:
+		err = &DecodeError{i, ErrMaxLen}
+	case min > 0 && l > (len(e)-n)/min:
+		err = &DecodeError{len(e), ErrTruncated}
+	}
+	return
+}
+
+// validate validates the value encoded at offset i of the encoding at
+// nesting depth, where ptrs is the number of pointers validated, nil
+// to not validate pointer references or negative to reject them, and
+// returns the offset of the end of the value
+func (e ENCODING) validate(i int, depth int, ptrs *int) (n int, err error) {
+	if depth > MaxDecodeDepth {
+		return i, &DecodeError{i, ErrMaxDepth}
+	}
+	if err = e.need(i + 1); err != nil {
+		return
+	}
+	var l int
+	switch e[i] {
+	case encRef:
+		if ptrs != nil && *ptrs < 0 {
+			return i, &DecodeError{i, ErrViewRef}
+		}
+		if l, n, err = e.validateLen(i+1, 0); err == nil && ptrs != nil && l >= *ptrs {
+			err = &DecodeError{i, ErrCorrupt}
+		}
+		return
+	case encTyped:
+		if l, n, err = e.validateLen(i+1, 1); err != nil {
+			return
+		}
+		return e.validate(n+l, depth+1, ptrs)
+	}
+	switch k := KIND(e[i]); k {
+	case Invalid:
+		n = i + 1
+	case Pointer:
+		if ptrs != nil && *ptrs >= 0 {
+			*ptrs++
+		}
+		return e.validate(i+1, depth+1, ptrs)
+	case Bool:
+		n = i + 2
+	case Int, Int8, Int16, Int32, Int64, Uint, Uint8, Uint16, Uint32, Uint64, Uintptr, Float32, Float64, Complex64, Complex128:
+		n = i + 1 + int(k.Size())
+	case Time:
+		n = i + 9
+	case Uuid:
+		n = i + 17
+		if err = e.need(n); err == nil && !BYTES(e[i+1:n]).CanUuid() {
+			err = &DecodeError{i, ErrCorrupt}
+		}
+	case Bytes, String:
+		if l, n, err = e.validateLen(i+1, 1); err == nil {
+			n += l
+		}
+	case Array, Slice, Map:
+		var kk, ek KIND
+		if k == Map {
+			if err = e.need(i + 3); err != nil {
+				return
+			}
+			if kk = KIND(e[i+1]); !kk.IsBasic() {
+				return i, &DecodeError{i + 1, ErrCorrupt}
+			}
+			i++
+		}
+		if err = e.need(i + 2); err != nil {
+			return
+		}
+		if ek = KIND(e[i+1]); ek > Bytes {
+			return i, &DecodeError{i + 1, ErrCorrupt}
+		}
+		if l, n, err = e.validateLen(i+2, 1); err != nil {
+			return
+		}
+		for j := 0; j < l && err == nil; j++ {
+			if k == Map {
+				if n, err = e.validateElem(n, kk, depth+1, ptrs); err != nil {
+					return
+				}
+			}
+			n, err = e.validateElem(n, ek, depth+1, ptrs)
+		}
+		return
+	case Struct:
+		if l, n, err = e.validateLen(i+1, 1); err != nil {
+			return
+		}
+		for j := 0; j < l && err == nil; j++ {
+			n, err = e.validate(n, depth+1, ptrs)
+		}
+		return
+	default:
+		return i, &DecodeError{i, ErrCorrupt}
+	}
+	if err == nil {
+		err = e.need(n)
+	}
+	return
+}
+
+// validateElem validates the container elem at offset i of the
+// encoding, which must be of KIND k if k is a basic kind
+func (e ENCODING) validateElem(i int, k KIND, depth int, ptrs *int) (int, error) {
+	if err := e.need(i + 1); err != nil {
+		return i, err
+	}
+	if k.IsBasic() && KIND(e[i]) != k {
+		return i, &DecodeError{i, ErrCorrupt}
+	}
+	return e.validate(i, depth, ptrs)
+}
+
+// validateSchema validates the schema at offset i of the encoding at
+// nesting depth and returns the offset of the end of the schema
+func (e ENCODING) validateSchema(i int, depth int) (n int, err error) {
+	if depth > MaxDecodeDepth {
+		return i, &DecodeError{i, ErrMaxDepth}
+	}
+	if err = e.need(i + 1); err != nil {
+		return
+	}
+	switch k := KIND(e[i]); {
+	case k > Bytes:
+		return i, &DecodeError{i, ErrCorrupt}
+	case k == Array || k == Slice:
+		return e.validateSchema(i+1, depth+1)
+	case k == Map:
+		if err = e.need(i + 2); err != nil {
+			return
+		}
+		if !KIND(e[i+1]).IsBasic() {
+			return i, &DecodeError{i + 1, ErrCorrupt}
+		}
+		return e.validateSchema(i+2, depth+1)
+	case k == Struct:
+		var l, nl int
+		if l, n, err = e.validateLen(i+1, 3); err != nil {
+			return
+		}
+		for j := 0; j < l; j++ {
+			if nl, n, err = e.validateLen(n, 1); err != nil {
+				return
+			}
+			if n, err = e.validateSchema(n+nl, depth+1); err != nil {
+				return
+			}
+		}
+		return
+	}
+	return i + 1, nil
+}
@@ -0,0 +1,153 @@
+// Copyright 2023 james dotter. All rights reserved.
+// Use of this source code is governed by a
+// license that can be found in the gotype LICENSE file.
+
+package gotype
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unsafe"
+)
+
+// ------------------------------------------------------------ /
+// DEFAULTS IMPLEMENTATION
+// population of zero value struct fields from the default tag
+// eg. `default:"8080"`, `default:"5s"` or `default:"a,b"`
+// ------------------------------------------------------------ /
+
+var durationType = TypeOf(time.Duration(0))
+
+// ApplyDefaults sets the zero value fields of the struct pointed to by ptr
+// to the value in the field's default tag, converted to the field's kind.
+// Slices are set from comma separated values, eg. `default:"a,b"`,
+// maps from comma separated key:value pairs, eg. `default:"a:1,b:2"`
+// and nested structs are populated recursively. Default tags of struct,
+// array and interface fields, which cannot be set from a string, are skipped.
+// Panics if ptr is not a pointer to a struct or a default cannot be converted
+func ApplyDefaults(ptr any) {
+	v := ValueOfV(ptr)
+	if v.Kind() != Pointer || v.IsNil() {
+		panic("ApplyDefaults requires a non nil pointer to a struct")
+	}
+	v = v.Elem()
+	if v.KIND() != Struct {
+		panic("ApplyDefaults requires a non nil pointer to a struct")
+	}
+	applyDefaults((STRUCT)(v))
+}
+
+// applyDefaults sets the zero value fields of STRUCT s to their defaults
+func applyDefaults(s STRUCT) {
+	s.ForFields(true, func(i int, f FIELD) (brake bool) {
+		if f.Visible() {
+			applyFieldDefault(f)
+		}
+		return
+	})
+}
+
+// applyFieldDefault sets field f to its default if zero
+// and applies the defaults of nested structs
+func applyFieldDefault(f FIELD) {
+	v := f.VALUE()
+	if d, ok := f.Tags()["default"]; ok && v.IsZero() {
+		if setDefault(v, d) == nil {
+			return
+		}
+	}
+	applyNestedDefaults(v)
+}
+
+// applyNestedDefaults applies the defaults of the structs
+// held in VALUE v, its pointers, slices and arrays
+func applyNestedDefaults(v VALUE) {
+	switch v.KIND() {
+	case Struct:
+		applyDefaults((STRUCT)(v))
+	case Pointer:
+		if !v.IsNil() {
+			applyNestedDefaults(v.Elem())
+		}
+	case Slice:
+		t, h := v.typ.Elem(), (*sliceHeader)(v.ptr)
+		for i := 0; i < h.Len; i++ {
+			applyNestedDefaults(VALUE{t, offset(h.Data, uintptr(i)*t.size), flagAddr | flagIndir | flag(t.Kind())})
+		}
+	case Array:
+		t := v.typ.Elem()
+		for i := 0; i < (ARRAY)(v).Len(); i++ {
+			applyNestedDefaults(VALUE{t, offset(v.ptr, uintptr(i)*t.size), v.flag&(flagIndir|flagAddr) | flag(t.Kind())})
+		}
+	}
+}
+
+// setDefault sets the addressable VALUE v to the default string d
+// converted to the kind of v, returns an error if the kind of v
+// cannot be set from a string
+func setDefault(v VALUE, d string) error {
+	s := STRING(d)
+	switch k := v.KIND(); {
+	case v.typ == durationType:
+		p, err := time.ParseDuration(d)
+		if err != nil {
+			panic("cannot convert default '" + d + "' to duration")
+		}
+		*(*time.Duration)(v.ptr) = p
+	case k == Bool:
+		v.Set(s.Bool())
+	case k == Int, k == Int8, k == Int16, k == Int32, k == Int64:
+		v.Set(s.Int())
+	case k == Uint, k == Uint8, k == Uint16, k == Uint32, k == Uint64, k == Uintptr:
+		v.Set(s.Uint())
+	case k == Float32, k == Float64:
+		v.Set(s.Float64())
+	case k == Time:
+		*(*TIME)(v.ptr) = s.ParseTime()
+	case k == Uuid:
+		*(*UUID)(v.ptr) = s.UUID()
+	case k == Bytes:
+		*(*[]byte)(v.ptr) = []byte(d)
+	case k == Pointer:
+		e := v.typ.Elem().New()
+		if err := setDefault(e.Elem(), d); err != nil {
+			return err
+		}
+		*(*unsafe.Pointer)(v.ptr) = e.ptr
+	case k == Slice:
+		items := strings.Split(d, ",")
+		*(*sliceHeader)(v.ptr) = sliceHeader{}
+		(SLICE)(v).Extend(len(items))
+		t, h := v.typ.Elem(), (*sliceHeader)(v.ptr)
+		for i, item := range items {
+			if err := setDefault(VALUE{t, offset(h.Data, uintptr(i)*t.size), flagAddr | flagIndir | flag(t.Kind())}, strings.TrimSpace(item)); err != nil {
+				*h = sliceHeader{}
+				return err
+			}
+		}
+	case k == Map:
+		t := v.typ.Elem()
+		items := strings.Split(d, ",")
+		es := make([]VALUE, len(items))
+		for i, item := range items {
+			_, val, _ := strings.Cut(item, ":")
+			es[i] = t.New().Elem()
+			if err := setDefault(es[i], strings.TrimSpace(val)); err != nil {
+				return err
+			}
+		}
+		if *(*unsafe.Pointer)(v.ptr) == nil {
+			*(*unsafe.Pointer)(v.ptr) = makemap(v.typ, 0, nil)
+		}
+		for i, item := range items {
+			key, _, _ := strings.Cut(item, ":")
+			(MAP)(v).Set(strings.TrimSpace(key), es[i])
+		}
+	case k == String:
+		v.Set(d)
+	default:
+		return errors.New("cannot set default of " + v.typ.String())
+	}
+	return nil
+}
@@ -7,6 +7,7 @@ package gotype
 import (
 	b "bytes"
 	"encoding/gob"
+	"fmt"
 	"math"
 	"unsafe"
 )
@@ -22,7 +23,7 @@ import (
 // []byte{Kind[, ElemKind[, KeyKind]][, Len], bytes...[, EndText[, bytes..., EndText, EndTrn]]}
 //
 // Elem Category	Kinds									Kind	ElemKind	KeyKind		Len		EndText		EndTrn
-// Fixed Len		Bool, Int, Uint, Float, Time, Uuid		√
+// Fixed Len		Bool, Int, Uint, Float, Complex, Time, Uuid	√
 // Variable Len		String, Bytes							√										√
 // Container		Slice, Array							√		√						√					√
 //					Map										√		√			√			√					√
@@ -31,6 +32,13 @@ import (
 // Kind: 			byte 0 to 30; always first element in []byte
 // EndText: 		0x3 (Control Character: End Of Text); denotes end of Variable Len elem
 // EndTrn:  		0x4 (Control Character: End Of Transmission); denotes end of Container elem
+// SchemaHeader:	0x80; optional prefix describing the encoded type, see EncodeSchema
+//
+// POINTERS AND INTERFACES:
+// Nil:				[]byte{Invalid}; nil pointer or interface
+// Pointer:			[]byte{Pointer, Elem...}; pointer to Elem, assigned the next id from 0
+// Ref:				[]byte{encRef, Id...}; pointer with Id encoded earlier, for shared or cyclic pointers
+// Typed:			[]byte{encTyped, Len, Name..., Elem...}; value of type registered by Name held in an interface
 //
 // FORMAT EXAMPLES:
 // Int32:			[]byte{Kind, byte, byte, byte, byte}
@@ -52,6 +60,11 @@ import (
 // decoding to golang types
 type ENCODING []byte
 
+const (
+	encRef   byte = 0x81 // back reference to a pointer encoded earlier
+	encTyped byte = 0x82 // value of a registered type held in an interface
+)
+
 type decodex struct {
 	k KIND  // data type kind of encoding
 	b int   // number of bytes decoded
@@ -68,33 +81,67 @@ func (d decodex) Json() string {
 	return MapOf(map[string]any{
 		"kind":  d.k.String(),
 		"bytes": INT(d.b).String(),
-		"value": d.v,
+		"value": d.v.Interface(),
 	}).String()
 }
 
+// Encode returns the gotype encoding of VALUE
 func (v VALUE) Encode() ENCODING {
+	return v.encode(&encoder{})
+}
+
+// encoder holds the pointers encoded in an encoding,
+// for encoding repeat pointers as back references
+type encoder struct {
+	ptrs map[encPtr]int // id of each pointer encoded
+}
+
+// encPtr is a pointer and the TYPE of its elem
+type encPtr struct {
+	p unsafe.Pointer
+	t *TYPE
+}
+
+// encode returns the encoding of VALUE with
+// the pointers already encoded in enc
+func (v VALUE) encode(enc *encoder) ENCODING {
+	if v.typ == nil {
+		return ENCODING{byte(Invalid)}
+	}
 	switch v.KIND() {
 	case Bool:
 		return (*BOOL)(v.ptr).Encode()
-	case Int, Int8, Int16, Int32, Int64, Uint, Uint8, Uint16, Uint32, Uint64, Float32, Float64:
+	case Int, Int8, Int16, Int32, Int64, Uint, Uint8, Uint16, Uint32, Uint64, Uintptr, Float32, Float64, Complex64, Complex128:
 		return v.EncodeNum()
 	case Array:
-		return (ARRAY)(v).Encode()
+		return (ARRAY)(v).encode(enc)
 	case Interface:
-		v = v.SetType()
-		if v.Kind() != Interface {
-			return v.Encode()
+		if e := v.SetType(); e.Kind() != Interface {
+			return e.encodeElem(v.typ, enc)
 		}
+		return ENCODING{byte(Invalid)}
 	case Map:
-		return (MAP)(v).Encode()
+		return (MAP)(v).encode(enc)
 	case Pointer:
-		return v.ElemDeep().Encode()
+		p := v.Pointer()
+		if p == nil {
+			return ENCODING{byte(Invalid)}
+		}
+		k := encPtr{p, v.typ.Elem()}
+		if id, ok := enc.ptrs[k]; ok {
+			return append(ENCODING{encRef}, lenBytes(id)...)
+		}
+		if enc.ptrs == nil {
+			enc.ptrs = map[encPtr]int{}
+		}
+		enc.ptrs[k] = len(enc.ptrs)
+		return append(ENCODING{byte(Pointer)}, v.Elem().encode(enc)...)
 	case Slice:
-		return (SLICE)(v).Encode()
+		return (SLICE)(v).encode(enc)
 	case String:
 		return (*STRING)(v.ptr).Encode()
 	case Struct:
-		return (STRUCT)(v).Encode()
+		return (STRUCT)(v).encode(enc)
 	case Time:
 		return (*TIME)(v.ptr).Encode()
 	case Uuid:
@@ -105,9 +152,30 @@ func (v VALUE) Encode() ENCODING {
 	panic("cannot convert to encode value")
 }
 
+// encodeElem returns the encoding of VALUE held in a container elem
+// of TYPE t, values held in interfaces are prefixed with the name
+// of their type if it is registered, see RegisterType
+func (v VALUE) encodeElem(t *TYPE, enc *encoder) ENCODING {
+	if t.Kind() == Interface && v.Kind() != Interface {
+		if n, ok := v.typ.RegisteredName(); ok {
+			e := append(append(ENCODING{encTyped}, lenBytes(len(n))...), n...)
+			return append(e, v.encode(enc)...)
+		}
+	}
+	return v.encode(enc)
+}
+
 // Kind returns the kind of the encoded value
 // panics if cannot determine kind
 func (e ENCODING) KIND() KIND {
+	if e.isCompressed() {
+		e.LenAtLeast(3)
+		return e[2:].KIND()
+	}
+	if e.hasHeader() {
+		_, _, n := e.header()
+		return KIND(e[n])
+	}
 	return KIND(e[0])
 }
 
@@ -206,25 +274,68 @@ func EncodeNumCompressed(i any) ENCODING {
 }
 
 // Decode decodes encoding and inserts values into pointer dest
-// panics if dest format does not match encoding
+// panics if dest format does not match encoding. Encodings with
+// a schema header are decoded by the rules of schema evolution
+// and compressed encodings are decompressed before decoding
 func (e ENCODING) Decode(dest any) int {
+	if e.isCompressed() {
+		d, n, err := e.decompress()
+		if err != nil {
+			panic(err)
+		}
+		d.Decode(dest)
+		return n
+	}
+	if e.hasHeader() {
+		return e.decodeWithSchema(dest)
+	}
 	enc := e.Decodex()
 	dVal := destValue(dest)
-	decodeValueSet(enc.v, dVal)
+	decodeValueSet(enc.v, dVal, decodeRefs{})
 	return enc.b
 }
 
+// decodeRefs maps the pointers of decoded values to
+// the destination pointers they were decoded to
+type decodeRefs map[unsafe.Pointer]VALUE
+
 // decodeValueSet cascades through decoded values eVal and
-// inserts values into destination dVal
-func decodeValueSet(eVal VALUE, dVal VALUE) {
-	eKind, dKind := eVal.Kind(), dVal.Kind()
-	// check if both are basic kinds
-	if (eKind.IsBasic() && dKind.IsBasic()) || dKind == Interface {
+// inserts values into destination dVal, pointers decoded
+// more than once are set to the same destination pointer
+func decodeValueSet(eVal VALUE, dVal VALUE, refs decodeRefs) {
+	if decodedNil(eVal) {
+		typedmemclr(dVal.typ, dVal.ptr)
+		return
+	}
+	eVal = eVal.SetType()
+	eKind, dKind := eVal.KIND(), dVal.KIND()
+	if eKind == Pointer {
+		p := eVal.Pointer()
+		switch dKind {
+		case Pointer:
+			r, ok := refs[p]
+			if !ok || r.typ != dVal.typ {
+				r = dVal.typ.Elem().New()
+				refs[p] = r
+				decodeValueSet(eVal.Elem(), r.Elem(), refs)
+			}
+			*(*unsafe.Pointer)(dVal.ptr) = r.ptr
+		case Interface:
+			// replace the value held by the interface with the pointer
+			dVal.set(eVal, ConvertDefault.policy())
+		default:
+			refs[p] = VALUE{dVal.typ.PtrType(), dVal.ptr, flag(Pointer)}
+			decodeValueSet(eVal.Elem(), dVal, refs)
+		}
+		return
+	}
+	// check if both are basic or complex kinds
+	if ((eKind.IsBasic() || isComplexKind(eKind)) && (dKind.IsBasic() || isComplexKind(dKind))) || dKind == Interface {
 		dVal.Set(eVal)
 		return
 	}
 	if dKind == Pointer {
-		decodeValueSet(eVal, dVal.Elem())
+		decodeValueSet(eVal, dVal.Init().Elem(), refs)
 		return
 	}
 	switch eKind {
@@ -238,7 +349,7 @@ func decodeValueSet(eVal VALUE, dVal VALUE) {
 				panic("cannot decode to array of differing length")
 			}
 			for i := 0; i < el; i++ {
-				decodeValueSet(ea.index(i), da.index(i))
+				decodeValueSet(ea.index(i), da.elem(i), refs)
 			}
 			return
 		case Slice:
@@ -248,7 +359,7 @@ func decodeValueSet(eVal VALUE, dVal VALUE) {
 				da.Extend(n)
 			}
 			for i := 0; i < el; i++ {
-				decodeValueSet(ea.index(i), da.index(i))
+				decodeValueSet(ea.index(i), da.elem(i), refs)
 			}
 			return
 		case Struct:
@@ -257,28 +368,28 @@ func decodeValueSet(eVal VALUE, dVal VALUE) {
 				panic("cannot decode to struct of differing length")
 			}
 			for i := 0; i < el; i++ {
-				decodeValueSet(ea.index(i), ds.index(i))
+				decodeValueSet(ea.index(i), ds.field(i), refs)
 			}
 			return
 		}
 	case Map:
 		if dKind == Map {
-			em, dm := eVal.MAP(), dVal.MAP()
+			em, dm := eVal.MAP(), dVal.Init().MAP()
 			t := (*mapType)(unsafe.Pointer(dm.typ)).elem
-			if dm.typ.Kind().IsBasic() {
-				em.ForEach(func(i int, k string, v VALUE) (brake bool) {
+			if t.Kind().IsBasic() {
+				em.ForEachKey(func(i int, k, v VALUE) (brake bool) {
 					dm.Set(k, v)
 					return
 				})
 				return
 			}
-			em.ForEach(func(i int, k string, v VALUE) (brake bool) {
-				dv := dm.Index(k)
-				if dv.ptr == nil {
-					dv = t.NewValue().Elem()
-					dm.Set(k, dv)
+			em.ForEachKey(func(i int, k, v VALUE) (brake bool) {
+				dv := t.New().Elem()
+				if p := dm.KeyPtr(k); p != nil {
+					typedmemmove(t, dv.ptr, p)
 				}
-				decodeValueSet(v, dv)
+				decodeValueSet(v, dv, refs)
+				dm.Set(k, dv)
 				return
 			})
 			return
@@ -287,24 +398,70 @@ func decodeValueSet(eVal VALUE, dVal VALUE) {
 	panic("dest format does not match encoding")
 }
 
-// destValue validates a pointer dest and
-// return a Value of the underlying destination
+// decodedNil returns true if the decoded VALUE v
+// is a nil pointer or interface after SetType
+func decodedNil(v VALUE) bool {
+	if v.typ == nil {
+		return true
+	}
+	k := v.Kind()
+	return k == Interface && v.iface().typ == nil || k == Pointer && v.Pointer() == nil
+}
+
+// destValue validates a pointer dest and return a Value of the
+// underlying destination, an interface destination is replaced
+// by the decoded value rather than set to it
 func destValue(dest any) VALUE {
 	v := ValueOfV(dest)
 	if v.Kind() != Pointer {
 		panic("dest must be a pointer")
 	}
-	return v.Elem().SetType()
+	return v.Elem()
 }
 
 // Decodex returns the Value and Kind and number of bytes of an encoding
 // returns structs as []any of the struct values and panics if
 // encoding cannot be decoded (or is corrupt)
 func (e ENCODING) Decodex() decodex {
+	if e.isCompressed() {
+		d, n, err := e.decompress()
+		if err != nil {
+			panic(err)
+		}
+		x := d.Decodex()
+		x.b = n
+		return x
+	}
+	if e.hasHeader() {
+		_, _, n := e.header()
+		d := e[n:].Decodex()
+		d.b += n
+		return d
+	}
+	return e.decodex(&decoder{})
+}
+
+// decoder holds the pointers decoded from an encoding by id
+type decoder struct {
+	refs []VALUE
+}
+
+// decodex returns the Value and Kind and number of bytes of an
+// encoding with the pointers already decoded in dec
+func (e ENCODING) decodex(dec *decoder) decodex {
+	e.LenAtLeast(1)
+	switch e[0] {
+	case encRef:
+		return e.decodexRef(dec)
+	case encTyped:
+		return e.decodexTyped(dec)
+	}
 	switch e.KIND() {
+	case Invalid:
+		return decodex{Invalid, 1, VALUE{}}
 	case Bool:
 		return e.decodexBool()
-	case Int, Int8, Int16, Int32, Int64, Uint, Uint8, Uint16, Uint32, Uint64, Float32, Float64:
+	case Int, Int8, Int16, Int32, Int64, Uint, Uint8, Uint16, Uint32, Uint64, Uintptr, Float32, Float64, Complex64, Complex128:
 		return e.decodexNum()
 	case Bytes, String:
 		return e.decodexBytes()
@@ -313,17 +470,110 @@ func (e ENCODING) Decodex() decodex {
 	case Uuid:
 		return e.decodexUuid()
 	case Array:
-		return e.decodexArray()
+		return e.decodexArray(dec)
 	case Map:
-		return e.decodexMap()
+		return e.decodexMap(dec)
+	case Pointer:
+		return e.decodexPointer(dec)
 	case Slice:
-		return e.decodexSlice()
+		return e.decodexSlice(dec)
 	case Struct:
-		return e.decodexStruct()
+		return e.decodexStruct(dec)
 	}
 	panic("cannot decode encoding of " + e.KIND().String())
 }
 
+// decodexType returns the TYPE of the Value decodex returns for the
+// encoding, with the pointers already decoded in dec
+func (e ENCODING) decodexType(dec *decoder) *TYPE {
+	e.LenAtLeast(1)
+	switch k := KIND(e[0]); {
+	case e[0] == encRef:
+		return e.decodexRef(dec).v.typ
+	case e[0] == encTyped:
+		return e.typedType()
+	case k == Invalid:
+		return TypeOf((*any)(nil)).Elem()
+	case k.IsBasic() || isComplexKind(k):
+		return k.NewValue().Elem().typ
+	case k == Slice:
+		e.LenAtLeast(2)
+		return elemKind(KIND(e[1])).NewSlice(0).typ
+	case k == Array:
+		e.LenAtLeast(3)
+		l, _ := e.decodeLen(2)
+		return ArrayTypeOf(l, elemKind(KIND(e[1])).NewSlice(0).typ.Elem())
+	case k == Map:
+		e.LenAtLeast(3)
+		return newKindMap(KIND(e[1]), KIND(e[2])).typ
+	case k == Pointer:
+		return e[1:].decodexType(dec).PtrType()
+	case k == Struct:
+		return TypeOf([]any(nil))
+	}
+	panic("cannot decode encoding of " + KIND(e[0]).String())
+}
+
+// decodexPointer decodes a pointer to the type of its elem and returns
+// the Value of the pointer and the number of bytes processed
+func (e ENCODING) decodexPointer(dec *decoder) (d decodex) {
+	e.LenAtLeast(2)
+	t := e[1:].decodexType(dec)
+	d.k, d.v = Pointer, t.New()
+	dec.refs = append(dec.refs, d.v)
+	n := e[1:].decodex(dec)
+	if n.v.typ == t {
+		typedmemmove(t, d.v.ptr, n.v.indirect())
+	} else if n.v.typ != nil {
+		panic("corrupt encoding")
+	}
+	d.b = n.b + 1
+	return
+}
+
+// decodexRef decodes a back reference to a pointer decoded earlier
+// and returns the Value of the pointer and the number of bytes processed
+func (e ENCODING) decodexRef(dec *decoder) (d decodex) {
+	e.LenAtLeast(2)
+	id, b := e.decodeLen(1)
+	if id < 0 || id >= len(dec.refs) {
+		panic("corrupt encoding")
+	}
+	return decodex{Pointer, b, dec.refs[id]}
+}
+
+// typedType returns the registered TYPE of a typed encoding and
+// the offset of the encoded value, panics if it is not registered
+func (e ENCODING) typedType() *TYPE {
+	t, _ := e.typed()
+	return t
+}
+
+// typed returns the registered TYPE of a typed encoding and the
+// offset of the encoded value, panics if it is not registered
+func (e ENCODING) typed() (*TYPE, int) {
+	e.LenAtLeast(2)
+	l, n := e.decodeLen(1)
+	e.LenAtLeast(n + l)
+	name := string(e[n : n+l])
+	t := TypeByName(name)
+	if t == nil {
+		panic(&DecodeError{0, fmt.Errorf("%w: %s", ErrUnregisteredType, name)})
+	}
+	return t, n + l
+}
+
+// decodexTyped decodes a value of a registered type held in an interface
+// and returns the Value of the type and the number of bytes processed
+func (e ENCODING) decodexTyped(dec *decoder) (d decodex) {
+	t, n := e.typed()
+	v := e[n:].decodex(dec)
+	d.v = t.New().Elem()
+	decodeValueSet(v.v, d.v, decodeRefs{})
+	d.k, d.b = d.v.KIND(), n+v.b
+	return
+}
+
 // decodexBool decodes a bool element and returns
 // the Value and the number of bytes processed
 func (e ENCODING) decodexBool() (d decodex) {
@@ -377,30 +627,27 @@ func (e ENCODING) decodexUuid() (d decodex) {
 // decodexTime decodes a time element to TIME and returns
 // the Value of the TIME and the number of bytes processed
 func (e ENCODING) decodexTime() (d decodex) {
-	e[0] = Int.Byte()
-	d = e.decodexNum()
-	d.k = e.KIND()
+	e.LenAtLeast(9)
+	d = append(ENCODING{Int.Byte()}, e[1:9]...).decodexNum()
+	d.k = Time
 	d.v = d.v.TIME().VALUE()
 	return
 }
 
 // decodexArray decodes an array to the array type and returns
 // the Value of the slice and the number of bytes processed
-func (e ENCODING) decodexArray() (d decodex) {
+func (e ENCODING) decodexArray(dec *decoder) (d decodex) {
 	var s ARRAY
 	d.k = e.KIND()
-	l, i, eKind := 0, 0, KIND(e[1])
-	if !eKind.IsBasic() {
-		eKind = Interface
-	}
+	l, i, eKind := 0, 0, elemKind(KIND(e[1]))
 	l, d.b = e.decodeLen(2)
 	s = eKind.NewArray(l)
 	for i < l {
-		n := e[d.b:].Decodex()
+		n := e[d.b:].decodex(dec)
 		if n.k != eKind && eKind != Interface {
 			panic("corrupt encoding")
 		}
-		if i < l {
+		if n.v.typ != nil {
 			s.index(i).Set(n.v)
 		}
 		d.b += n.b
@@ -412,55 +659,73 @@ func (e ENCODING) decodexArray() (d decodex) {
 
 // decodexMap decodes a map to the map type and returns the
 // Value of the map and the number of bytes processed
-func (e ENCODING) decodexMap() (d decodex) {
+func (e ENCODING) decodexMap(dec *decoder) (d decodex) {
 	var m MAP
 	d.k = e.KIND()
-	l, i, kKind, eKind := 0, 0, KIND(e[1]), KIND(e[2])
-	if kKind != String {
-		panic("map must have a key type of string")
-	}
-	if !eKind.IsBasic() {
-		eKind = Interface
-	}
-	m = eKind.NewMap()
+	l, i, kKind, eKind := 0, 0, KIND(e[1]), elemKind(KIND(e[2]))
+	m = newKindMap(kKind, eKind)
 	l, d.b = e.decodeLen(3)
 	for i < l {
 		// decode key
-		k := e[d.b:].Decodex()
-		if k.k != String {
+		k := e[d.b:].decodex(dec)
+		if k.k != kKind {
 			panic("corrupt encoding")
 		}
 		d.b += k.b
 		// decode value
-		v := e[d.b:].Decodex()
+		v := e[d.b:].decodex(dec)
 		if v.k != eKind && eKind != Interface {
 			panic("corrupt encoding")
 		}
 		d.b += v.b
-		m.Set(k.v.String(), v.v)
+		if v.v.typ == nil {
+			v.v = (*mapType)(unsafe.Pointer(m.typ)).elem.New().Elem()
+		}
+		m.Set(k.v, v.v)
 		i++
 	}
 	d.v = m.VALUE()
 	return
 }
 
+// elemKind returns the KIND of the elems of a decoded container
+// with elems of KIND k, which is Interface if k is not basic
+func elemKind(k KIND) KIND {
+	if !k.IsBasic() {
+		return Interface
+	}
+	return k
+}
+
+// newKindMap returns a new map of the decoded container with keys
+// of KIND kKind and elems of KIND eKind, panics if kKind is not basic
+func newKindMap(kKind, eKind KIND) MAP {
+	if !kKind.IsBasic() {
+		panic("map must have a key type of a basic kind")
+	}
+	m := elemKind(eKind).NewMap()
+	if kKind != String {
+		m = newMap(kKind.NewValue().Elem().typ, (*mapType)(unsafe.Pointer(m.typ)).elem)
+	}
+	return m
+}
+
 // decodexSlice decodes a slice to the slice type and returns
 // the Value of the slice and the number of bytes processed
-func (e ENCODING) decodexSlice() (d decodex) {
+func (e ENCODING) decodexSlice(dec *decoder) (d decodex) {
 	var s SLICE
 	d.k = e.KIND()
-	l, i, eKind := 0, 0, KIND(e[1])
-	if !eKind.IsBasic() {
-		eKind = Interface
-	}
+	l, i, eKind := 0, 0, elemKind(KIND(e[1]))
 	l, d.b = e.decodeLen(2)
 	s = eKind.NewSlice(l)
 	for i < l {
-		n := e[d.b:].Decodex()
+		n := e[d.b:].decodex(dec)
 		if n.k != eKind && eKind != Interface {
 			panic("corrupt encoding")
 		}
-		s.index(i).Set(n.v)
+		if n.v.typ != nil {
+			s.index(i).Set(n.v)
+		}
 		d.b += n.b
 		i++
 	}
@@ -470,18 +735,20 @@ func (e ENCODING) decodexSlice() (d decodex) {
 
 // decodexStruct decodes a struct to []any and returns the
 // Value of the Slice and the number of bytes processed
-func (e ENCODING) decodexStruct() (d decodex) {
+func (e ENCODING) decodexStruct(dec *decoder) (d decodex) {
 	d.k = e.KIND()
 	l, i := 0, 0
 	l, d.b = e.decodeLen(1)
 	a := make([]any, l)
 	s := SliceOf(&a)
 	for i < l {
-		f := e[d.b:].Decodex()
+		f := e[d.b:].decodex(dec)
 		if f.k == Interface {
 			panic("corrupt encoding")
 		}
-		s.index(i).Set(f.v)
+		if f.v.typ != nil {
+			s.index(i).Set(f.v)
+		}
 		d.b += f.b
 		i++
 	}
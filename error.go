@@ -44,3 +44,65 @@ func Source(i int) string {
 	}
 	return errorModule + errorDelim + `unknown.source`
 }
+
+// recoverable evaluates whether the recovered panic r was raised by
+// this package, which panics with strings and errors when a value
+// cannot convert or merge. Runtime errors are bugs and not recoverable
+func recoverable(r any) bool {
+	switch r.(type) {
+	case runtime.Error:
+		return false
+	case string, error:
+		return true
+	}
+	return false
+}
+
+// ConversionError is returned by the Try conversions when a value
+// cannot convert from its kind to the kind requested
+type ConversionError struct {
+	From  KIND  // kind of the value converted
+	To    KIND  // kind the value was converted to
+	Input any   // the value converted
+	Err   error // the reason the conversion failed
+}
+
+// Error returns the ConversionError as a string
+func (e *ConversionError) Error() string {
+	s := fmt.Sprintf("cannot convert %s '%v' to %s", e.From, e.Input, e.To)
+	if e.Err != nil {
+		s += ": " + e.Err.Error()
+	}
+	return s
+}
+
+// Unwrap returns the reason the conversion failed
+func (e *ConversionError) Unwrap() error {
+	return e.Err
+}
+
+// SchemaError is returned when an encoding with a schema header
+// cannot be decoded to a destination of an incompatible kind
+type SchemaError struct {
+	Path string // path of the value in the destination, eg. "A.B[2]"
+	From KIND   // kind of the encoded value
+	To   KIND   // kind of the destination
+}
+
+// Error returns the SchemaError as a string
+func (e *SchemaError) Error() string {
+	s := fmt.Sprintf("cannot decode %s to %s", e.From, e.To)
+	if e.Path != "" {
+		s += " at '" + e.Path + "'"
+	}
+	return s
+}
+
+// in prefixes the path of the SchemaError with the field or index p
+func (e *SchemaError) in(p string) *SchemaError {
+	if e.Path != "" && e.Path[0] != '[' {
+		p += "."
+	}
+	e.Path = p + e.Path
+	return e
+}
@@ -83,7 +83,8 @@ func (f FIELD) Tag(name string) string {
 	return getTagValue(f.rawtag, name, `"`[0])
 }
 
-// SubTags returns a map of key vaue pairs in a given tag in the field
+// SubTags returns a map of key vaue pairs in a given tag in the field,
+// flags without a value are mapped to "", eg. `validate:"required min:'1'"`
 func (f FIELD) SubTags(tag string) map[string]string {
 	return parseTags(f.Tag(tag), `'`[0])
 }
@@ -114,8 +115,9 @@ func parseTags(rawtag string, q byte) map[string]string {
 	inTag = true
 	for i := 0; i < l; i++ {
 		if inTag {
-			tag, i, inTag, inValue = parseTagName(rawtag, i, l)
-			tags[tag] = ""
+			if tag, i, inTag, inValue = parseTagName(rawtag, i, l); tag != "" {
+				tags[tag] = ""
+			}
 		} else if inValue {
 			value, i, inTag, inValue = parseTagValue(rawtag, i, l, q)
 			tags[tag] = value
@@ -144,11 +146,18 @@ func getTagValue(rawtag string, tagname string, q byte) string {
 	return ""
 }
 
+// parseTagName returns the name of the tag starting at start in t,
+// inValue is true if the name is followed by a value and inTag is
+// true if the name is a flag without a value, eg. "required"
 func parseTagName(t string, start int, l int) (tag string, end int, inTag bool, inValue bool) {
 	for end = start; end < l; end++ {
-		if t[end] == 58 {
+		switch t[end] {
+		case 58:
 			inValue, tag = true, t[start:end]
 			return
+		case 32:
+			inTag, tag = true, t[start:end]
+			return
 		}
 	}
 	tag = t[start:l]
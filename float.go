@@ -57,6 +57,8 @@ func (v VALUE) Float64() float64 {
 			return float64(*(*uint32)(v.ptr))
 		case Uint64:
 			return float64(*(*uint64)(v.ptr))
+		case Uintptr:
+			return float64(*(*uintptr)(v.ptr))
 		case Float32:
 			return float64(*(*float32)(v.ptr))
 		case String:
@@ -65,6 +67,8 @@ func (v VALUE) Float64() float64 {
 			return (*BYTES)(v.ptr).Float64()
 		case Time:
 			return (*(*TIME)(v.ptr)).Float64()
+		case Complex64, Complex128:
+			return v.COMPLEX().Float64()
 		}
 	}
 	panic("cannot convert value to int")
@@ -134,10 +138,11 @@ func (f FLOAT) BOOL() BOOL {
 
 // Int returns gotype Float as int
 func (f FLOAT) Int() int {
-	if f > math.MaxInt && f < -math.MaxInt {
-		panic("overflow error: Float greater than max uint or Float less than 0")
+	r := math.Round(float64(f))
+	if r >= 0x1p63 || r < -0x1p63 || math.IsNaN(r) {
+		panic("overflow error: Float greater than max int or Float less than min int")
 	}
-	return int(math.Round(float64(f)))
+	return int(r)
 }
 
 // INT returns gotype Float as a gotype INT
@@ -147,10 +152,11 @@ func (f FLOAT) INT() INT {
 
 // Uint returns gotype FLOAT as uint
 func (f FLOAT) Uint() uint {
-	if f < 0 || f > math.MaxUint {
+	r := math.Round(float64(f))
+	if r < 0 || r >= 0x1p64 || math.IsNaN(r) {
 		panic("overflow error: Float greater than max uint or Float less than 0")
 	}
-	return uint(math.Round(float64(f)))
+	return uint(r)
 }
 
 // UINT returns gotype Float as a gotype UINT
@@ -0,0 +1,355 @@
+// Copyright 2023 james dotter. All rights reserved.
+// Use of this source code is governed by a
+// license that can be found in the gotype LICENSE file.
+
+package gotype
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// ------------------------------------------------------------ /
+// FORM IMPLEMENTATION
+// encoding and decoding of url.Values, eg. html forms and
+// url query strings, to and from structs and maps
+// ------------------------------------------------------------ /
+
+// FormStyle is the style of the keys of nested values in url.Values
+type FormStyle uint8
+
+const (
+	FormBrackets FormStyle = iota // user[address][city]
+	FormDots                      // user.address.city
+)
+
+// MaxFormIndex limits the indexes of slices and arrays decoded by DecodeForm,
+// eg. items[1000], as a slice is grown to the largest index of its keys
+var MaxFormIndex = 1000
+
+// EncodeForm encodes the struct or map v as url.Values, nested structs
+// and maps are keyed in the style provided. Slices and arrays of basic
+// values are encoded as repeated keys, and of structs or maps by index,
+// eg. items[0][name]. Struct fields are keyed by their form tag or name,
+// fields tagged form:"-" are skipped and form:"name,omitempty" skips
+// zero values. Nil pointers and interfaces are skipped
+func EncodeForm(v any, style FormStyle) (values url.Values, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("form: %v", r)
+		}
+	}()
+	values = url.Values{}
+	e := formEncoder{values, style}
+	r := ValueOfV(v).SetType()
+	for r.Kind() == Pointer && !r.IsNil() {
+		r = r.Elem().SetType()
+	}
+	switch r.KIND() {
+	case Struct, Map:
+		e.encode(r, "")
+	default:
+		return nil, errors.New("form: cannot encode " + r.typ.String() + " as url.Values")
+	}
+	return
+}
+
+type formEncoder struct {
+	values url.Values
+	style  FormStyle
+}
+
+// key returns key k nested in prefix
+func (e formEncoder) key(prefix, k string) string {
+	switch {
+	case prefix == "":
+		return k
+	case e.style == FormDots:
+		return prefix + "." + k
+	}
+	return prefix + "[" + k + "]"
+}
+
+// encode adds VALUE v to the values at key
+func (e formEncoder) encode(v VALUE, key string) {
+	if v.typ == nil || v.IsNil() {
+		return
+	}
+	v = v.SetType()
+	switch v.KIND() {
+	case Pointer, Interface:
+		e.encode(v.Elem(), key)
+	case Struct:
+		(STRUCT)(v).ForFields(true, func(i int, f FIELD) (brake bool) {
+			if !f.Visible() {
+				return
+			}
+			name, opts, _ := strings.Cut(f.Tag("form"), ",")
+			if name == "-" {
+				return
+			}
+			if name == "" {
+				name = f.name
+			}
+			fv := f.VALUE()
+			if opts == "omitempty" && fv.IsZero() {
+				return
+			}
+			e.encode(fv, e.key(key, name))
+			return
+		})
+	case Map:
+		m := (MAP)(v)
+		keys := m.Keys()
+		sort.Strings(keys)
+		for _, k := range keys {
+			e.encode(m.Index(k), e.key(key, k))
+		}
+	case Slice, Array:
+		for i := 0; i < v.Len(); i++ {
+			item := v.Index(i)
+			if formNested(item) {
+				e.encode(item, e.key(key, strconv.Itoa(i)))
+			} else {
+				e.encode(item, key)
+			}
+		}
+	default:
+		e.values.Add(key, v.String())
+	}
+}
+
+// formNested evaluates whether VALUE v is encoded as nested keys
+func formNested(v VALUE) bool {
+	if v.typ == nil {
+		return false
+	}
+	v = v.SetType()
+	for v.Kind() == Pointer || v.Kind() == Interface {
+		if v.IsNil() {
+			return false
+		}
+		v = v.Elem().SetType()
+	}
+	switch v.KIND() {
+	case Struct, Map, Slice, Array:
+		return true
+	}
+	return false
+}
+
+// DecodeForm decodes the url.Values into the struct or map pointed to by
+// dest. Nested keys may be in bracket or dot style, eg. user[address][city]
+// or user.address.city, repeated keys are decoded into slices, and slices of
+// structs or maps are decoded from indexed keys, eg. items[0][name]. Struct
+// fields are matched by their form tag, name, lower case or snake case name,
+// and values are converted to the kind of the field. Decoding into a
+// map[string]any sets strings, []string for repeated keys, and nested maps
+func DecodeForm(values url.Values, dest any) (err error) {
+	d := ValueOfV(dest)
+	if d.Kind() != Pointer || d.Pointer() == nil {
+		return errors.New("form: dest must be a non nil pointer")
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("form: %v", r)
+		}
+	}()
+	root := &formNode{}
+	for k, vs := range values {
+		root.add(formKey(k), vs)
+	}
+	e := d.Elem()
+	switch e.KIND() {
+	case Struct, Map:
+		root.decode(e, "")
+	default:
+		return errors.New("form: cannot decode url.Values into " + e.typ.String())
+	}
+	return
+}
+
+// formKey splits a bracket or dot style key into its parts, dots within
+// brackets are part of the key, eg. "user[a.b]" is "user" and "a.b",
+// empty parts, eg. of "tags[]", are dropped
+func formKey(k string) (parts []string) {
+	s, inBracket := 0, false
+	for i := 0; i <= len(k); i++ {
+		if i < len(k) {
+			switch c := k[i]; {
+			case c == '[' && !inBracket:
+				inBracket = true
+			case c == ']' && inBracket:
+				inBracket = false
+			case c == '.' && !inBracket:
+			default:
+				continue
+			}
+		}
+		if i > s {
+			parts = append(parts, k[s:i])
+		}
+		s = i + 1
+	}
+	return
+}
+
+// formNode is a tree of the keys in url.Values
+type formNode struct {
+	values   []string
+	children map[string]*formNode
+}
+
+func (n *formNode) add(keys []string, values []string) {
+	for _, k := range keys {
+		if n.children == nil {
+			n.children = map[string]*formNode{}
+		}
+		c, ok := n.children[k]
+		if !ok {
+			c = &formNode{}
+			n.children[k] = c
+		}
+		n = c
+	}
+	n.values = append(n.values, values...)
+}
+
+// child returns the child of the node at the first of the keys found
+func (n *formNode) child(keys ...string) *formNode {
+	for _, k := range keys {
+		if c, ok := n.children[k]; ok {
+			return c
+		}
+	}
+	return nil
+}
+
+// items returns the children of the node keyed by index at the position
+// of their index, nil for missing indexes, or else a node for each of the
+// node's repeated values
+func (n *formNode) items(path string) (items []*formNode) {
+	last := -1
+	for k := range n.children {
+		i, err := strconv.Atoi(k)
+		if err != nil || i < 0 {
+			last = -1
+			break
+		}
+		last = max(last, i)
+	}
+	if last < 0 {
+		for _, s := range n.values {
+			items = append(items, &formNode{values: []string{s}})
+		}
+		return
+	}
+	if last > MaxFormIndex {
+		panic(fmt.Sprintf("%s: index %d exceeds MaxFormIndex", path, last))
+	}
+	items = make([]*formNode, last+1)
+	for k, c := range n.children {
+		i, _ := strconv.Atoi(k)
+		items[i] = c
+	}
+	return
+}
+
+// decode sets the addressable VALUE v from the node
+func (n *formNode) decode(v VALUE, path string) {
+	switch k := v.KIND(); {
+	case k == Pointer:
+		if *(*unsafe.Pointer)(v.ptr) == nil {
+			*(*unsafe.Pointer)(v.ptr) = v.typ.Elem().New().ptr
+		}
+		n.decode(v.Elem(), path)
+	case k == Struct:
+		(STRUCT)(v).ForFields(true, func(i int, f FIELD) (brake bool) {
+			if !f.Visible() {
+				return
+			}
+			name, _, _ := strings.Cut(f.Tag("form"), ",")
+			if name == "-" {
+				return
+			}
+			keys := []string{f.name, strings.ToLower(f.name), STRING(f.name).ToSnake()}
+			if name != "" {
+				keys = []string{name}
+			}
+			if c := n.child(keys...); c != nil {
+				c.decode(f.VALUE(), validatePath(path, f.name))
+			}
+			return
+		})
+	case k == Map:
+		if *(*unsafe.Pointer)(v.ptr) == nil {
+			*(*unsafe.Pointer)(v.ptr) = makemap(v.typ, 0, nil)
+		}
+		t := v.typ.Elem()
+		for key, c := range n.children {
+			e := t.New().Elem()
+			if t.Kind() == Interface {
+				*(*any)(e.ptr) = c.any()
+			} else {
+				c.decode(e, validatePath(path, key))
+			}
+			(MAP)(v).Set(key, e)
+		}
+	case k == Slice:
+		t, items := v.typ.Elem(), n.items(path)
+		*(*sliceHeader)(v.ptr) = sliceHeader{}
+		(SLICE)(v).Extend(len(items))
+		h := (*sliceHeader)(v.ptr)
+		for i, c := range items {
+			if c == nil {
+				continue
+			}
+			c.decode(VALUE{t, offset(h.Data, uintptr(i)*t.size), flagAddr | flagIndir | flag(t.Kind())}, validatePath(path, strconv.Itoa(i)))
+		}
+	case k == Array:
+		t, l, items := v.typ.Elem(), (ARRAY)(v).Len(), n.items(path)
+		for i := 0; i < len(items) && i < l; i++ {
+			if items[i] == nil {
+				continue
+			}
+			items[i].decode(VALUE{t, offset(v.ptr, uintptr(i)*t.size), flagAddr | flagIndir | flag(t.Kind())}, validatePath(path, strconv.Itoa(i)))
+		}
+	case k == Interface:
+		*(*any)(v.ptr) = n.any()
+	default:
+		if len(n.values) == 0 || (n.values[0] == "" && k != String) {
+			return // empty inputs of non string fields are left unset
+		}
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					panic(fmt.Sprintf("%s: cannot convert '%s' to %s", path, n.values[0], v.typ))
+				}
+			}()
+			if err := setDefault(v, n.values[0]); err != nil {
+				panic(err)
+			}
+		}()
+	}
+}
+
+// any returns the node as a string, []string for repeated
+// values, or map[string]any for nested keys
+func (n *formNode) any() any {
+	if len(n.children) > 0 {
+		m := make(map[string]any, len(n.children))
+		for k, c := range n.children {
+			m[k] = c.any()
+		}
+		return m
+	}
+	if len(n.values) == 1 {
+		return n.values[0]
+	}
+	return n.values
+}
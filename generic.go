@@ -0,0 +1,308 @@
+// Copyright 2023 james dotter. All rights reserved.
+// Use of this source code is governed by a
+// license that can be found in the gotype LICENSE file.
+
+package gotype
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// ------------------------------------------------------------ /
+// GENERIC IMPLEMENTATION
+// typed accessors converting values to concrete golang types
+// through the gotype conversion matrix
+// ------------------------------------------------------------ /
+
+// As returns v converted to type T, eg. As[int]("1") or As[TIME]("2023-01-01").
+// Pointer types are converted to a pointer to a new value converted to
+// the element type, and a nil v, or nil pointer or interface, returns the
+// zero value of T. Returns a ConversionError if v cannot be converted to T
+func As[T any](v any) (t T, err error) {
+	if r, ok := v.(T); ok || v == nil {
+		return r, nil
+	}
+	typ := TypeOf((*T)(nil)).Elem()
+	if typ.Kind() == Interface {
+		return t, &ConversionError{ValueOfV(v).KIND(), Interface, v, errors.New("does not implement " + typ.String())}
+	}
+	if d := valueDeref(ValueOfV(v)); d.typ == nil || d.flag&flagIndir != 0 && d.ptr == nil {
+		return t, nil
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			if !recoverable(r) {
+				panic(r)
+			}
+			err = &ConversionError{ValueOfV(v).KIND(), typ.KIND(), v, fmt.Errorf("%v", r)}
+		}
+	}()
+	return *(*T)(asValue(typ, v).ptr), nil
+}
+
+// Must returns v converted to type T, panics if v cannot be converted
+func Must[T any](v any) T {
+	t, err := As[T](v)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// asValue returns a new addressable VALUE of TYPE typ set to a
+func asValue(typ *TYPE, a any) VALUE {
+	d := typ.New().Elem()
+	if typ.Kind() == Pointer {
+		*(*unsafe.Pointer)(d.ptr) = asValue(typ.Elem(), a).ptr
+		return d
+	}
+	d.Set(a)
+	return d
+}
+
+// Get returns the value at path in v converted to type T, where path is a
+// dot separated list of struct field names, map keys and slice or array
+// indexes, eg. Get[string](v, "Users.0.Name"). Pointers and interfaces
+// in the path are dereferenced. Returns an error if the path is not
+// found in v or the value cannot be converted to T
+func Get[T any](v any, path string) (t T, err error) {
+	e, err := valueAt(ValueOfV(v), path)
+	if err != nil {
+		return
+	}
+	return As[T](valueInterface(e))
+}
+
+// valueAt returns the value at the dot separated path in VALUE v
+func valueAt(v VALUE, path string) (VALUE, error) {
+	if path == "" {
+		return v, nil
+	}
+	for i, k := range strings.Split(path, ".") {
+		if v = valueDeref(v); v.typ == nil {
+			return v, errors.New("nil value at '" + strings.Join(strings.Split(path, ".")[:i], ".") + "'")
+		}
+		found := false
+		switch v.KIND() {
+		case Struct:
+			(STRUCT)(v).ForFields(true, func(_ int, f FIELD) (brake bool) {
+				if f.name == k {
+					v, found = f.VALUE(), true
+				}
+				return found
+			})
+		case Map:
+			if m := (MAP)(v); m.Len() > 0 && m.KeyPtr(k) != nil {
+				v, found = m.Index(k), true
+			}
+		case Slice, Array, Bytes, Uuid:
+			if n, err := strconv.Atoi(k); err == nil && n >= 0 && n < v.Len() {
+				v, found = v.Index(n), true
+			}
+		}
+		if !found {
+			return v, errors.New("path '" + path + "' not found")
+		}
+	}
+	return valueDeref(v), nil
+}
+
+// valueDeref returns the value held by pointers and interfaces in v,
+// returns a VALUE with a nil TYPE if a nil pointer or interface is held
+func valueDeref(v VALUE) VALUE {
+	for v.typ != nil {
+		v = v.SetType()
+		if k := v.Kind(); k != Pointer && k != Interface {
+			return v
+		}
+		if v.IsNil() {
+			return VALUE{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// valueInterface returns VALUE v as an interface,
+// nil if v holds a nil interface
+func valueInterface(v VALUE) any {
+	if v.typ == nil {
+		return nil
+	}
+	if v = v.SetType(); v.typ == nil || (v.Kind() == Interface && v.IsNil()) {
+		return nil
+	}
+	return v.Interface()
+}
+
+// SliceAs returns the slice, array or SLICE v as a SLICEOF[T],
+// converting each item to type T
+func SliceAs[T any](v any) (SLICEOF[T], error) {
+	switch s := v.(type) {
+	case []T:
+		return s, nil
+	case SLICEOF[T]:
+		return s, nil
+	}
+	s := valueDeref(ValueOfV(v))
+	if s.typ == nil {
+		return nil, nil
+	}
+	switch s.KIND() {
+	case Slice, Array:
+	default:
+		return nil, errors.New("cannot convert " + s.typ.String() + " to slice")
+	}
+	r := make(SLICEOF[T], s.Len())
+	for i := range r {
+		var err error
+		if r[i], err = As[T](valueInterface(s.Index(i))); err != nil {
+			return nil, fmt.Errorf("index %d: %w", i, err)
+		}
+	}
+	return r, nil
+}
+
+// MapAs returns the map, MAP or struct v as a MAPOF[K, V],
+// converting each key to type K and each value to type V
+func MapAs[K comparable, V any](v any) (MAPOF[K, V], error) {
+	switch m := v.(type) {
+	case map[K]V:
+		return m, nil
+	case MAPOF[K, V]:
+		return m, nil
+	}
+	m := valueDeref(ValueOfV(v))
+	if m.typ == nil {
+		return nil, nil
+	}
+	var keys []string
+	var vals []VALUE
+	switch m.KIND() {
+	case Map:
+		(MAP)(m).ForEach(func(i int, k string, e VALUE) (brake bool) {
+			keys, vals = append(keys, k), append(vals, e)
+			return
+		})
+	case Struct:
+		(STRUCT)(m).ForFields(true, func(i int, f FIELD) (brake bool) {
+			if f.Visible() {
+				keys, vals = append(keys, f.name), append(vals, f.VALUE())
+			}
+			return
+		})
+	default:
+		return nil, errors.New("cannot convert " + m.typ.String() + " to map")
+	}
+	r := make(MAPOF[K, V], len(keys))
+	for i, k := range keys {
+		rk, err := As[K](k)
+		if err != nil {
+			return nil, fmt.Errorf("key '%s': %w", k, err)
+		}
+		if r[rk], err = As[V](valueInterface(vals[i])); err != nil {
+			return nil, fmt.Errorf("key '%s': %w", k, err)
+		}
+	}
+	return r, nil
+}
+
+// ------------------------------------------------------------ /
+// TYPED SLICE AND MAP
+// the typed counterparts of SLICE and MAP, which convert items
+// set to the type of the slice or map
+// ------------------------------------------------------------ /
+
+// SLICEOF is a slice of items of type T, see SliceAs
+type SLICEOF[T any] []T
+
+// Len returns the number of items in SLICEOF
+func (s SLICEOF[T]) Len() int {
+	return len(s)
+}
+
+// Index returns item i of SLICEOF
+func (s SLICEOF[T]) Index(i int) T {
+	return s[i]
+}
+
+// Set sets item i of SLICEOF to a converted to type T,
+// returns a ConversionError if a cannot be converted
+func (s SLICEOF[T]) Set(i int, a any) error {
+	t, err := As[T](a)
+	if err != nil {
+		return err
+	}
+	s[i] = t
+	return nil
+}
+
+// Append returns SLICEOF with the items a converted to type T appended,
+// returns a ConversionError if an item cannot be converted
+func (s SLICEOF[T]) Append(a ...any) (SLICEOF[T], error) {
+	for _, e := range a {
+		t, err := As[T](e)
+		if err != nil {
+			return s, err
+		}
+		s = append(s, t)
+	}
+	return s, nil
+}
+
+// Slice returns SLICEOF as []T
+func (s SLICEOF[T]) Slice() []T {
+	return s
+}
+
+// SLICE returns SLICEOF as a gotype SLICE
+func (s SLICEOF[T]) SLICE() SLICE {
+	return SliceOf([]T(s))
+}
+
+// MAPOF is a map of keys of type K to values of type V, see MapAs
+type MAPOF[K comparable, V any] map[K]V
+
+// Len returns the number of keys in MAPOF
+func (m MAPOF[K, V]) Len() int {
+	return len(m)
+}
+
+// Index returns the value of key k converted to type K,
+// the zero value of V if MAPOF has no such key
+func (m MAPOF[K, V]) Index(k any) V {
+	var v V
+	if key, err := As[K](k); err == nil {
+		v = m[key]
+	}
+	return v
+}
+
+// Set sets key k converted to type K to the value a converted to type V,
+// returns a ConversionError if k or a cannot be converted
+func (m MAPOF[K, V]) Set(k, a any) error {
+	key, err := As[K](k)
+	if err != nil {
+		return err
+	}
+	v, err := As[V](a)
+	if err != nil {
+		return err
+	}
+	m[key] = v
+	return nil
+}
+
+// Map returns MAPOF as map[K]V
+func (m MAPOF[K, V]) Map() map[K]V {
+	return m
+}
+
+// MAP returns MAPOF as a gotype MAP
+func (m MAPOF[K, V]) MAP() MAP {
+	return MapOf(map[K]V(m))
+}
@@ -54,6 +54,9 @@ func AbsInt(x int) int {
 	return x
 }
 
+// ptrSize is the size of a pointer
+const ptrSize = unsafe.Sizeof(uintptr(0))
+
 func offset(p unsafe.Pointer, offset uintptr) unsafe.Pointer {
 	return unsafe.Pointer(uintptr(p) + offset)
 }
@@ -104,8 +107,20 @@ func mapaccess_faststr(t *TYPE, m unsafe.Pointer, key string) (val unsafe.Pointe
 func mapdelete_faststr(t *TYPE, m unsafe.Pointer, key string)
 
 //go:noescape
-//go:linkname mapassign_faststr reflect.mapassign_faststr
-func mapassign_faststr(t *TYPE, m unsafe.Pointer, key string, val unsafe.Pointer)
+//go:linkname mapaccess reflect.mapaccess
+func mapaccess(t *TYPE, m unsafe.Pointer, key unsafe.Pointer) (val unsafe.Pointer)
+
+//go:noescape
+//go:linkname mapdelete reflect.mapdelete
+func mapdelete(t *TYPE, m unsafe.Pointer, key unsafe.Pointer)
+
+//go:noescape
+//go:linkname mapassign reflect.mapassign
+func mapassign(t *TYPE, m unsafe.Pointer, key, val unsafe.Pointer)
+
+//go:noescape
+//go:linkname mapclear reflect.mapclear
+func mapclear(t *TYPE, m unsafe.Pointer)
 
 //go:noescape
 //go:linkname toType reflect.toType
@@ -127,10 +142,18 @@ func growslice(t *TYPE, old sliceHeader, num int) sliceHeader
 //go:linkname typedmemmove reflect.typedmemmove
 func typedmemmove(t *TYPE, dst, src unsafe.Pointer)
 
+//go:noescape
+//go:linkname typedmemclr reflect.typedmemclr
+func typedmemclr(t *TYPE, ptr unsafe.Pointer)
+
 //go:noescape
 //go:linkname resolveNameOff reflect.resolveNameOff
 func resolveNameOff(ptrInModule unsafe.Pointer, off int32) unsafe.Pointer
 
+//go:noescape
+//go:linkname resolveTypeOff reflect.resolveTypeOff
+func resolveTypeOff(rtype unsafe.Pointer, off int32) unsafe.Pointer
+
 //go:noescape
 //go:linkname mallocgc runtime.mallocgc
 func mallocgc(size uintptr, typ *TYPE, needzero bool) unsafe.Pointer
@@ -55,6 +55,8 @@ func (v VALUE) Int() int {
 			return UINT(*(*uint32)(v.ptr)).Int()
 		case Uint64:
 			return UINT(*(*uint64)(v.ptr)).Int()
+		case Uintptr:
+			return UINT(*(*uintptr)(v.ptr)).Int()
 		case Float32:
 			return FLOAT(*(*float32)(v.ptr)).Int()
 		case Float64:
@@ -139,24 +141,24 @@ func (i INT) Int() int {
 
 // Int8 returns golang int8 of gotype INT
 func (i INT) Int8() int8 {
-	if i > math.MaxInt8 || i < -math.MaxInt8 {
-		panic("overflow error: Int greater than max int8")
+	if i > math.MaxInt8 || i < math.MinInt8 {
+		panic("overflow error: Int greater than max int8 or Int less than min int8")
 	}
 	return int8(i)
 }
 
 // Int16 returns golang int16 of gotype INT
 func (i INT) Int16() int16 {
-	if i > math.MaxInt16 || i < -math.MaxInt16 {
-		panic("overflow error: Int greater than max int16")
+	if i > math.MaxInt16 || i < math.MinInt16 {
+		panic("overflow error: Int greater than max int16 or Int less than min int16")
 	}
 	return int16(i)
 }
 
 // Int32 returns golang int32 of gotype INT
 func (i INT) Int32() int32 {
-	if i > math.MaxInt32 || i < -math.MaxInt32 {
-		panic("overflow error: Int greater than max int32")
+	if i > math.MaxInt32 || i < math.MinInt32 {
+		panic("overflow error: Int greater than max int32 or Int less than min int32")
 	}
 	return int32(i)
 }
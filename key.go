@@ -0,0 +1,291 @@
+// Copyright 2023 james dotter. All rights reserved.
+// Use of this source code is governed by a
+// license that can be found in the gotype LICENSE file.
+
+package gotype
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+)
+
+// ------------------------------------------------------------ /
+// GOTYPE KEY ENCODING
+// order preserving encoding of tuples of values for use as
+// keys of key value stores, where the bytes.Compare of two
+// encoded keys matches the natural ordering of the values
+// ------------------------------------------------------------ /
+
+// KEY ENCODING FORMAT
+// []byte{Tag, bytes..., Tag, bytes..., ...}
+//
+// Tag			Kinds									Bytes
+// keyNil		nil										none
+// keyBool		Bool									0x0 or 0x1
+// keyInt		Int, Int8, Int16, Int32, Int64			8 bytes big endian with sign bit flipped
+// keyUint		Uint, Uint8, Uint16, Uint32, Uint64		8 bytes big endian
+// keyFloat		Float32, Float64						8 bytes big endian of float64 bits, sign bit
+//														flipped if positive or all bits flipped if negative
+// keyString	String									bytes..., 0x0, 0x1 with 0x0 escaped as 0x0, 0xff
+// keyBytes		Bytes									same as String
+// keyTime		Time									unix seconds encoded as keyInt, then 4 bytes
+//														big endian of nanoseconds within the second
+// keyUuid		Uuid									16 bytes
+//
+// Components wrapped with Desc are encoded with every byte, including the
+// tag, inverted to sort in descending order. Components at the same
+// position of keys compared to one another should be of the same kind
+
+// KEY contains an order preserving encoding of a tuple of values
+type KEY []byte
+
+const (
+	keyNil byte = iota + 1
+	keyBool
+	keyInt
+	keyUint
+	keyFloat
+	keyString
+	keyBytes
+	keyTime
+	keyUuid
+)
+
+// descKey is a key component encoded in descending order
+type descKey struct {
+	a any
+}
+
+// Desc returns a key component of a that is encoded to sort in descending order
+func Desc(a any) any {
+	return descKey{a}
+}
+
+// EncodeKey returns the order preserving encoding of the
+// tuple of values a, see KEY ENCODING FORMAT. Panics if a
+// value is not of a kind supported by key encodings
+func EncodeKey(a ...any) KEY {
+	return KEY(nil).Append(a...)
+}
+
+// DecodeKey returns the values of the components of key k, with ints,
+// uints and floats decoded to int64, uint64 and float64, returns a
+// DecodeError if the key is truncated or corrupt
+func DecodeKey(k KEY) ([]any, error) {
+	return k.Values()
+}
+
+// Append returns the key with the values a appended as components
+func (k KEY) Append(a ...any) KEY {
+	for _, c := range a {
+		k = k.append(c)
+	}
+	return k
+}
+
+// append returns the key with the value a appended as a component
+func (k KEY) append(a any) KEY {
+	d, desc := a.(descKey)
+	if desc {
+		a = d.a
+	}
+	i, v := len(k), ValueOfV(a)
+	if v.typ != nil {
+		v = v.SetType().ElemDeep()
+	}
+	if v.typ == nil {
+		k = append(k, keyNil)
+	} else {
+		switch v.KIND() {
+		case Bool:
+			k = append(k, keyBool, 0)
+			if v.Bool() {
+				k[i+1] = 1
+			}
+		case Int, Int8, Int16, Int32, Int64:
+			k = binary.BigEndian.AppendUint64(append(k, keyInt), uint64(v.Int())^(1<<63))
+		case Uint, Uint8, Uint16, Uint32, Uint64, Uintptr:
+			k = binary.BigEndian.AppendUint64(append(k, keyUint), uint64(v.Uint()))
+		case Float32, Float64:
+			b := math.Float64bits(v.Float64())
+			if b&(1<<63) != 0 {
+				b = ^b
+			} else {
+				b |= 1 << 63
+			}
+			k = binary.BigEndian.AppendUint64(append(k, keyFloat), b)
+		case String:
+			k = appendKeyBytes(append(k, keyString), []byte(*(*string)(v.ptr)))
+		case Bytes:
+			k = appendKeyBytes(append(k, keyBytes), v.Bytes())
+		case Time:
+			t := time.Time(v.TIME())
+			k = binary.BigEndian.AppendUint64(append(k, keyTime), uint64(t.Unix())^(1<<63))
+			k = binary.BigEndian.AppendUint32(k, uint32(t.Nanosecond()))
+		case Uuid:
+			u := v.UUID()
+			k = append(append(k, keyUuid), u[:]...)
+		default:
+			panic("cannot encode key component of kind " + v.KIND().String())
+		}
+	}
+	if desc {
+		for j := i; j < len(k); j++ {
+			k[j] = ^k[j]
+		}
+	}
+	return k
+}
+
+// appendKeyBytes appends the bytes b to key k with
+// 0x0 escaped and terminated with 0x0, 0x1
+func appendKeyBytes(k KEY, b []byte) KEY {
+	for _, c := range b {
+		if c == 0 {
+			k = append(k, 0, 0xff)
+			continue
+		}
+		k = append(k, c)
+	}
+	return append(k, 0, 1)
+}
+
+// Values returns the values of the components of the key, see DecodeKey
+func (k KEY) Values() (a []any, err error) {
+	var c any
+	for i := 0; i < len(k); {
+		if c, i, err = k.component(i); err != nil {
+			return nil, err
+		}
+		a = append(a, c)
+	}
+	return
+}
+
+// Decode decodes the components of the key into pointers dest
+// in order, converting values to the type of each dest. Returns
+// a DecodeError if the key is truncated or corrupt or has fewer
+// components than dest, or a ConversionError if a component
+// cannot be set to its dest
+func (k KEY) Decode(dest ...any) (err error) {
+	var c any
+	i := 0
+	for _, d := range dest {
+		if i >= len(k) {
+			return &DecodeError{i, ErrTruncated}
+		}
+		if c, i, err = k.component(i); err != nil {
+			return
+		}
+		if c == nil {
+			v := destValue(d)
+			typedmemclr(v.typ, v.ptr)
+			continue
+		}
+		if _, err = destValue(d).SetWith(c, ConvertDefault); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// component decodes the component at offset i of the key and
+// returns its value and the offset of the next component
+func (k KEY) component(i int) (c any, n int, err error) {
+	tag, desc := k[i], k[i] > 0x7f
+	if desc {
+		tag = ^tag
+	}
+	// at returns byte j of the component in ascending order
+	at := func(j int) byte {
+		if desc {
+			return ^k[j]
+		}
+		return k[j]
+	}
+	// u64 returns the big endian uint64 of the 8 bytes of the component
+	u64 := func() (u uint64) {
+		for j := i + 1; j < i+9; j++ {
+			u = u<<8 | uint64(at(j))
+		}
+		return
+	}
+	switch tag {
+	case keyNil:
+		return nil, i + 1, nil
+	case keyBool:
+		n = i + 2
+	case keyInt, keyUint, keyFloat:
+		n = i + 9
+	case keyTime:
+		n = i + 13
+	case keyUuid:
+		n = i + 17
+	case keyString, keyBytes:
+		var b []byte
+		for j := i + 1; ; j++ {
+			if j+1 >= len(k) {
+				return nil, 0, &DecodeError{len(k), ErrTruncated}
+			}
+			if c := at(j); c != 0 {
+				b = append(b, c)
+				continue
+			}
+			switch at(j + 1) {
+			case 0xff:
+				b, j = append(b, 0), j+1
+				continue
+			case 1:
+				n = j + 2
+			default:
+				return nil, 0, &DecodeError{j, ErrCorrupt}
+			}
+			break
+		}
+		if tag == keyString {
+			return string(b), n, nil
+		}
+		if b == nil {
+			b = []byte{}
+		}
+		return b, n, nil
+	default:
+		return nil, 0, &DecodeError{i, ErrCorrupt}
+	}
+	if n > len(k) {
+		return nil, 0, &DecodeError{len(k), ErrTruncated}
+	}
+	switch tag {
+	case keyBool:
+		c = at(i+1) != 0
+	case keyInt:
+		c = int64(u64() ^ (1 << 63))
+	case keyUint:
+		c = u64()
+	case keyFloat:
+		b := u64()
+		if b&(1<<63) != 0 {
+			b &^= 1 << 63
+		} else {
+			b = ^b
+		}
+		c = math.Float64frombits(b)
+	case keyTime:
+		var ns uint32
+		for j := i + 9; j < n; j++ {
+			ns = ns<<8 | uint32(at(j))
+		}
+		if ns > 999999999 {
+			return nil, 0, &DecodeError{i + 9, ErrCorrupt}
+		}
+		c = TIME(time.Unix(int64(u64()^(1<<63)), int64(ns)).UTC())
+	case keyUuid:
+		var u UUID
+		for j := range u {
+			u[j] = at(i + 1 + j)
+		}
+		c = u
+	}
+	return
+}
@@ -6,6 +6,7 @@ package gotype
 
 import (
 	"reflect"
+	"sort"
 	"unsafe"
 )
 
@@ -28,6 +29,12 @@ func MapOf(a any) MAP {
 	return ValueOf(a).MAP()
 }
 
+// newMap returns a new empty MAP with keys
+// of TYPE key and elements of TYPE elem
+func newMap(key, elem *TYPE) MAP {
+	return (MAP)(FromReflect(reflect.MakeMap(reflect.MapOf(toType(key), toType(elem)))))
+}
+
 // MAP returns VALUE as gotype MAP
 func (v VALUE) MAP() MAP {
 	switch v.Kind() {
@@ -66,151 +73,174 @@ func (v VALUE) MAP() MAP {
 // Len returns the number of items in MAP
 func (m MAP) Len() int {
 	if p := (VALUE)(m).Pointer(); p != nil {
-		return *(*int)(p)
+		return maplen(p)
 	}
 	return 0
 }
 
-// Keys returns gotype MAP keys as []string
+// Keys returns gotype MAP keys as []string,
+// keys of kinds other than string are converted to string
 func (m MAP) Keys() []string {
-	p := (VALUE)(m).Pointer()
-	l := *(*int)(p)
-	d := mallocgc(uintptr(l*16), TypeOf(byte(0)), false)
-	keys := *(*[]string)(unsafe.Pointer(&sliceHeader{Data: d, Len: l, Cap: l}))
-	ob := uintptr(0)
-	b := *(*uintptr)(unsafe.Pointer(uintptr(p) + 16))
-	keysize := uintptr((*mapType)(unsafe.Pointer(m.typ)).keysize)
-	bucketsize := uintptr((*mapType)(unsafe.Pointer(m.typ)).bucketsize)
-	i := 0
-	for i < l {
-		k := 0
-		for k < bucketCnt { // capture each key in bucket
-			up := b + dataOffset + uintptr(k)*keysize
-			if *(*unsafe.Pointer)(unsafe.Pointer(up)) == nil { // key deleted - skip
-				k++
-				continue
-			}
-			if *(*byte)(unsafe.Pointer(up + 8)) == 0 { // key empty - next bucket
-				break
-			}
-			keys[i] = *(*string)(unsafe.Pointer(up))
-			k++
-			i++
-		}
-		// determine next bucket
-		if sub := *(*uintptr)(unsafe.Pointer(b + bucketsize - 8)); sub != 0 { // has overflow
-			if ob == 0 {
-				ob = b
-			}
-			b = sub
-		} else if ob != 0 { // in overflow bucket
-			b = ob + bucketsize
-			ob = 0
-		} else {
-			b += bucketsize
-		}
-	}
+	keys := make([]string, 0, m.Len())
+	m.ForEach(func(i int, k string, v VALUE) (brake bool) {
+		keys = append(keys, k)
+		return
+	})
+	return keys
+}
+
+// KeyValues returns gotype MAP keys as []VALUE
+func (m MAP) KeyValues() []VALUE {
+	keys := make([]VALUE, 0, m.Len())
+	m.ForEachKey(func(i int, k, v VALUE) (brake bool) {
+		keys = append(keys, k)
+		return
+	})
 	return keys
 }
 
-// Index returns the value found at key k of Map
+// Index returns the value found at key k of Map, where k
+// is converted to the key type of the map if necessary,
 // returns nil pointer if key does not exist
-func (m MAP) Index(k string) VALUE {
+func (m MAP) Index(k any) VALUE {
 	t := (*mapType)(unsafe.Pointer(m.typ)).elem
-	v := VALUE{
-		t,
-		mapaccess_faststr(m.typ, (VALUE)(m).Pointer(), k),
-		t.flag(),
-	}
-	if !t.IfaceIndir() {
-		v.ptr = *(*unsafe.Pointer)(v.ptr)
+	p := m.KeyPtr(k)
+	if p == nil {
+		return VALUE{t, nil, flag(t.Kind())}
 	}
-	return v.SetType()
+	return VALUE{t, p, flagIndir | flag(t.Kind())}.SetType()
 }
 
-// ForEach executes function f on each item in ARRAY,
+// ForEach executes function f on each item in MAP, keys
+// of kinds other than string are converted to string,
 // note: i is not a fixed value and may change across items
 func (m MAP) ForEach(f func(i int, k string, v VALUE) (brake bool)) {
+	m.ForEachKey(func(i int, k, v VALUE) (brake bool) {
+		return f(i, mapKeyString(k), v)
+	})
+}
+
+// ForEachKey executes function f on each key and item in MAP,
+// note: i is not a fixed value and may change across items
+func (m MAP) ForEachKey(f func(i int, k, v VALUE) (brake bool)) {
 	p := (VALUE)(m).Pointer()
 	if p == nil {
 		return
 	}
 	t := (*mapType)(unsafe.Pointer(m.typ))
-	l := *(*int)(p)
-	b := uintptr(*(*unsafe.Pointer)(unsafe.Pointer(uintptr(p) + 16)))
-	ob := uintptr(0)
-	bucketsize := uintptr(t.bucketsize)
-	keysize := uintptr(t.keysize)
-	valuesize := uintptr(t.valuesize)
-	voff := dataOffset + bucketCnt*keysize
-	i := 0
-	brake := false
-	for i < l {
-		k := 0
-		for k < bucketCnt { // capture each key in bucket
-			up := b + dataOffset + uintptr(k)*keysize
-			if *(*unsafe.Pointer)(unsafe.Pointer(up)) == nil { // key deleted - skip
-				k++
-				continue
-			}
-			if *(*byte)(unsafe.Pointer(up + 8)) == 0 { // key empty - next bucket
-				break
-			}
-			v := VALUE{
-				t.elem,
-				unsafe.Pointer(b + voff + uintptr(k)*valuesize),
-				t.elem.flag(),
-			}.SetType()
-			if !t.elem.IfaceIndir() {
-				v.ptr = *(*unsafe.Pointer)(v.ptr)
-			}
-			if f(i, *(*string)(unsafe.Pointer(up)), v) {
-				brake = true
-				break
-			}
-			k++
-			i++
+	it := &hiter{}
+	mapiterinit(m.typ, p, it)
+	for i := 0; mapiterkey(it) != nil; i++ {
+		k := VALUE{t.key, mapiterkey(it), flagIndir | flag(t.key.Kind())}.SetType()
+		v := VALUE{t.elem, mapiterelem(it), flagIndir | flag(t.elem.Kind())}.SetType()
+		if f(i, k, v) {
+			return
 		}
-		if brake {
-			break
-		}
-		// determine next bucket
-		if sub := *(*uintptr)(unsafe.Pointer(b + bucketsize - 8)); sub != 0 { // has overflow
-			if ob == 0 {
-				ob = b
-			}
-			b = sub
-		} else if ob != 0 { // in overflow bucket
-			b = ob + bucketsize
-			ob = 0
-		} else {
-			b += bucketsize
+		mapiternext(it)
+	}
+}
+
+// ForEachSorted executes function f on each item in MAP in the
+// order of its keys, numeric keys are ordered by value and other
+// keys by the key converted to string
+func (m MAP) ForEachSorted(f func(i int, k string, v VALUE) (brake bool)) {
+	for i, e := range m.sorted() {
+		if f(i, e.s, e.v) {
+			return
 		}
 	}
 }
 
-// Set updates the value of key k to value v,
+// mapItem is a key and value of a MAP
+type mapItem struct {
+	k, v VALUE
+	s    string // key as string
+}
+
+// sorted returns the items of MAP in the order of its keys
+func (m MAP) sorted() []mapItem {
+	items := make([]mapItem, 0, m.Len())
+	m.ForEachKey(func(i int, k, v VALUE) (brake bool) {
+		items = append(items, mapItem{k, v, mapKeyString(k)})
+		return
+	})
+	var less func(i, j int) bool
+	switch (*mapType)(unsafe.Pointer(m.typ)).key.KIND() {
+	case Int, Int8, Int16, Int32, Int64:
+		less = func(i, j int) bool { return items[i].k.Int() < items[j].k.Int() }
+	case Uint, Uint8, Uint16, Uint32, Uint64, Uintptr:
+		less = func(i, j int) bool { return items[i].k.Uint() < items[j].k.Uint() }
+	case Float32, Float64:
+		less = func(i, j int) bool { return items[i].k.Float64() < items[j].k.Float64() }
+	default:
+		less = func(i, j int) bool { return items[i].s < items[j].s }
+	}
+	sort.Slice(items, less)
+	return items
+}
+
+// Set updates the value of key k to value v, where k is
+// converted to the key type of the map if necessary,
 // returns the MAP with the updated value
-func (m MAP) Set(k string, v any) MAP {
+func (m MAP) Set(k any, v any) MAP {
 	if v == nil {
-		mapdelete_faststr(m.typ, m.ptr, k)
-		return m
+		return m.Delete(k)
 	}
 	etyp := (*mapType)(unsafe.Pointer(m.typ)).elem
 	val := ValueOfV(v).SetType()
 	if val.typ != etyp {
 		val = val.convert(etyp)
 	}
-	mapassign_faststr(m.typ, (VALUE)(m).Pointer(), k, val.ptr)
+	mapassign(m.typ, (VALUE)(m).Pointer(), m.key(k), val.indirect())
 	return m
 }
 
-// Delete removes key from MAP
-func (m MAP) Delete(key string) MAP {
-	mapdelete_faststr(m.typ, (VALUE)(m).Pointer(), key)
+// Delete removes key from MAP, where key is converted
+// to the key type of the map if necessary
+func (m MAP) Delete(key any) MAP {
+	if s, ok := key.(string); ok && m.stringKeys() {
+		mapdelete_faststr(m.typ, (VALUE)(m).Pointer(), s)
+		return m
+	}
+	mapdelete(m.typ, (VALUE)(m).Pointer(), m.key(key))
 	return m
 }
 
+// stringKeys evaluates whether the MAP keys are of kind string
+func (m MAP) stringKeys() bool {
+	return (*mapType)(unsafe.Pointer(m.typ)).key.Kind() == String
+}
+
+// key returns a pointer to key k converted
+// to the key type of the MAP if necessary
+func (m MAP) key(k any) unsafe.Pointer {
+	t := (*mapType)(unsafe.Pointer(m.typ)).key
+	v := ValueOfV(k)
+	if v.typ != nil {
+		if v = v.SetType(); v.typ == t {
+			return v.indirect()
+		}
+	}
+	d := t.New().Elem()
+	if t.Kind() == Interface {
+		*(*any)(d.ptr) = valueInterface(v)
+		return d.ptr
+	}
+	d.Set(v)
+	return d.ptr
+}
+
+// mapKeyString returns map key k as a string
+func mapKeyString(k VALUE) string {
+	if k.typ == nil {
+		return ""
+	}
+	if k.Kind() == String {
+		return *(*string)(k.ptr)
+	}
+	return k.String()
+}
+
 // ------------------------------------------------------------ /
 // GOTYPE EXPANDED FUNCTIONS
 // implementations of new functions for
@@ -218,9 +248,13 @@ func (m MAP) Delete(key string) MAP {
 // referenced packages: reflect
 // ------------------------------------------------------------ /
 
-// KeyPtr returns an unsafe pointer to the value at index 'key'
-func (m MAP) KeyPtr(key string) unsafe.Pointer {
-	return mapaccess_faststr(m.typ, (VALUE)(m).Pointer(), key)
+// KeyPtr returns an unsafe pointer to the value at index 'key',
+// returns nil if the key does not exist
+func (m MAP) KeyPtr(key any) unsafe.Pointer {
+	if s, ok := key.(string); ok && m.stringKeys() {
+		return mapaccess_faststr(m.typ, (VALUE)(m).Pointer(), s)
+	}
+	return mapaccess(m.typ, (VALUE)(m).Pointer(), m.key(key))
 }
 
 // Kind returns the kind of the map elements
@@ -263,17 +297,21 @@ func (m MAP) Pointer() unsafe.Pointer {
 
 // Bytes encodes gotype MAP as []byte
 func (m MAP) Encode() ENCODING {
+	return m.encode(&encoder{})
+}
+
+// encode returns the encoding of MAP with the pointers already encoded in enc
+func (m MAP) encode(enc *encoder) ENCODING {
 	t := (*mapType)(unsafe.Pointer(m.typ))
 	e := append([]byte{
 		byte(Map),
-		t.key.Kind().Byte(),
+		t.key.KIND().Byte(),
 		t.elem.Kind().Byte()},
 		lenBytes(m.Len())...)
-	m.ForEach(func(i int, k string, v VALUE) (brake bool) {
-		e = append(e, STRING(k).Encode()...)
-		e = append(e, m.Index(k).Encode()...)
-		return
-	})
+	for _, i := range m.sorted() {
+		e = append(e, i.k.encode(enc)...)
+		e = append(e, i.v.encodeElem(t.elem, enc)...)
+	}
 	return e
 }
 
@@ -326,7 +364,7 @@ func (m MAP) json(ancestry ...ancestor) (s string) {
 	if m.Len() == 0 {
 		return "{}"
 	}
-	m.ForEach(func(i int, k string, v VALUE) (brake bool) {
+	m.ForEachSorted(func(i int, k string, v VALUE) (brake bool) {
 		sval, recursive := v.jsonSafe(ancestry...)
 		if !recursive {
 			s += `,"` + k + `":` + sval
@@ -344,10 +382,10 @@ func (m MAP) STRING() STRING {
 	return STRING(m.String())
 }
 
-// Slice returns gotype MAP values as []any
+// Slice returns gotype MAP values as []any in the order of its keys
 func (m MAP) Slice() []any {
 	vals := make([]any, m.Len())
-	m.ForEach(func(i int, k string, v VALUE) (brake bool) {
+	m.ForEachSorted(func(i int, k string, v VALUE) (brake bool) {
 		vals[i] = v.Interface()
 		return
 	})
@@ -357,7 +395,7 @@ func (m MAP) Slice() []any {
 // Values returns gotype MAP values as []VALUE
 func (m MAP) Values() []VALUE {
 	vals := make([]VALUE, m.Len())
-	m.ForEach(func(i int, k string, v VALUE) (brake bool) {
+	m.ForEachKey(func(i int, k, v VALUE) (brake bool) {
 		vals[i] = v
 		return
 	})
@@ -367,14 +405,14 @@ func (m MAP) Values() []VALUE {
 // Strings returns gotype MAP values as []string
 func (m MAP) Strings() []string {
 	vals := make([]string, m.Len())
-	m.ForEach(func(i int, k string, v VALUE) (brake bool) {
+	m.ForEachKey(func(i int, k, v VALUE) (brake bool) {
 		vals[i] = v.String()
 		return
 	})
 	return vals
 }
 
-// SLICE returns gotype MAP values as gotype SLICE
+// SLICE returns gotype MAP values as gotype SLICE in the order of its keys
 func (m MAP) SLICE() SLICE {
 	k := (*mapType)(unsafe.Pointer(m.typ)).elem.Kind()
 	if k == Interface {
@@ -382,7 +420,7 @@ func (m MAP) SLICE() SLICE {
 	}
 	s := k.NewSlice(m.Len())
 	n := 0
-	m.ForEach(func(i int, k string, v VALUE) (brake bool) {
+	m.ForEachSorted(func(i int, k string, v VALUE) (brake bool) {
 		s.index(n).Set(v)
 		n++
 		return
@@ -8,7 +8,6 @@ import (
 	"bytes"
 	"fmt"
 	"math"
-	"reflect"
 	"strconv"
 	"unsafe"
 )
@@ -37,6 +36,7 @@ type Marshaler struct {
 	availBuf    int    // the available buffer space
 	// marshaling syntax
 	Type              string // the type of marshaller. json, yaml, etc.
+	TypeKey           string // the key of the registered type name of values in interfaces, eg. "$type", none if empty
 	Space             []byte // the space characters
 	LineBreak         []byte // the line break characters
 	Indent            []byte // the indentation characters
@@ -56,19 +56,23 @@ type Marshaler struct {
 	MapEnd            []byte // the characters that end a hash map
 	InlineSyntax      *InlineSyntax
 	// marshaling flags
-	Format           bool // when true, marshal with formatting, indentation, and line breaks
-	FormatWithSpaces bool // when true, marshal with space between keys and values
-	CascadeOnlyDeep  bool // when true, marshal single-depth slices and maps with inline syntax
-	QuotedKey        bool // when true, marshal map keys with quotes
-	QuotedString     bool // when true, marshal strings with quotes
-	QuotedSpecial    bool // when true, marshal strings with quotes if they contain special characters
-	QuotedNum        bool // when true, marshal numbers with quotes
-	QuotedBool       bool // when true, marshal bools with quotes
-	QuotedNull       bool // when true, marshal null with quotes
-	RecursiveName    bool // when true, include name, string or type of recursive value in marshalling, otherwise, exclude all recursion
-	UnmarshalTyped   bool // when true, unmarshal to typed values (int, float64, bool, string) instead of just strings
-	MarshalMethods   bool // when true, marshal structs with a Marshal method by calling the method
-	ExcludeZeros     bool // when true, exclude zero and nil values from marshalling
+	Format            bool // when true, marshal with formatting, indentation, and line breaks
+	FormatWithSpaces  bool // when true, marshal with space between keys and values
+	CascadeOnlyDeep   bool // when true, marshal single-depth slices and maps with inline syntax
+	QuotedKey         bool // when true, marshal map keys with quotes
+	QuotedString      bool // when true, marshal strings with quotes
+	QuotedSpecial     bool // when true, marshal strings with quotes if they contain special characters
+	QuotedNum         bool // when true, marshal numbers with quotes
+	QuotedBool        bool // when true, marshal bools with quotes
+	QuotedNull        bool // when true, marshal null with quotes
+	RecursiveName     bool // when true, include name, string or type of recursive value in marshalling, otherwise, exclude all recursion
+	UnmarshalTyped    bool // when true, unmarshal to typed values (int, float64, bool, string) instead of just strings
+	UnmarshalDefaults bool // when true, UnmarshalInto sets struct fields missing from the data to their default tag
+	MarshalMethods    bool // when true, marshal structs with a Marshal method by calling the method
+	ExcludeZeros      bool // when true, exclude zero and nil values from marshalling
+	ComplexAsArray    bool // when true, marshal complex numbers as [real, imag], otherwise as strings "1+2i"
+	// unmarshaling policies
+	Conversion ConversionPolicy // the policy of numeric conversions in UnmarshalInto
 	// marshaler cache
 	space      byte
 	quote      byte
@@ -80,7 +84,6 @@ type Marshaler struct {
 	mapParts   map[string][3][]byte
 	hasTag     map[*TYPE]bool
 	tagKeys    map[*TYPE][]string
-	methods    map[*TYPE]int
 }
 
 type InlineSyntax struct {
@@ -99,6 +102,7 @@ type InlineSyntax struct {
 var (
 	JsonMarshaler = &Marshaler{
 		Type:              "json",
+		TypeKey:           "$type",
 		FormatWithSpaces:  true,
 		CascadeOnlyDeep:   true,
 		QuotedKey:         true,
@@ -121,6 +125,7 @@ var (
 	}
 	YamlMarshaler = &Marshaler{
 		Type:             "yaml",
+		TypeKey:          "$type",
 		Format:           true,
 		FormatWithSpaces: true,
 		QuotedSpecial:    true,
@@ -232,7 +237,6 @@ func (m *Marshaler) Reset() {
 	m.mapParts = map[string][3][]byte{}
 	m.hasTag = nil
 	m.tagKeys = nil
-	m.methods = nil
 }
 
 func (m *Marshaler) ResetCursor() {
@@ -345,8 +349,10 @@ func (m *Marshaler) marshal(v VALUE, ancestry ...ancestor) {
 	switch v.KIND() {
 	case Bool:
 		m.marshalBool(v.Bool())
-	case Int, Int8, Int16, Int32, Int64, Uint, Uint8, Uint16, Uint32, Uint64, Uintptr, Float32, Float64, Complex64, Complex128:
+	case Int, Int8, Int16, Int32, Int64, Uint, Uint8, Uint16, Uint32, Uint64, Uintptr, Float32, Float64:
 		m.marshalNum(v)
+	case Complex64, Complex128:
+		m.marshalComplex(v)
 	case Array:
 		m.marshalArray((ARRAY)(v), ancestry...)
 	case Func:
@@ -416,15 +422,11 @@ func (m *Marshaler) marshalNum(v VALUE) {
 	case Uint64:
 		bytes = []byte(strconv.FormatUint(*(*uint64)(v.ptr), 10))
 	case Uintptr:
-		bytes = []byte(strconv.FormatUint(*(*uint64)(v.ptr), 10))
+		bytes = []byte(strconv.FormatUint(uint64(*(*uintptr)(v.ptr)), 10))
 	case Float32:
 		bytes = []byte(strconv.FormatFloat(float64(*(*float32)(v.ptr)), 'f', -1, 64))
 	case Float64:
 		bytes = []byte(strconv.FormatFloat(*(*float64)(v.ptr), 'f', -1, 64))
-	case Complex64:
-		bytes = []byte(strconv.FormatComplex(complex128(*(*complex64)(v.ptr)), 'f', -1, 128))
-	case Complex128:
-		bytes = []byte(strconv.FormatComplex(*(*complex128)(v.ptr), 'f', -1, 128))
 	default:
 		panic("cannot marshal type '" + v.typ.String() + "'")
 	}
@@ -435,6 +437,23 @@ func (m *Marshaler) marshalNum(v VALUE) {
 	m.bufferBytes(bytes)
 }
 
+// marshalComplex marshals a complex number as [real, imag]
+// if ComplexAsArray, otherwise as a string of the form "1+2i"
+func (m *Marshaler) marshalComplex(v VALUE) {
+	if m.ComplexAsArray {
+		if v.Kind() == Complex64 {
+			c := *(*complex64)(v.ptr)
+			a := [2]float32{real(c), imag(c)}
+			m.marshalArray((ARRAY)(ValueOf(&a).Elem()))
+			return
+		}
+		a := COMPLEX(*(*complex128)(v.ptr)).Array()
+		m.marshalArray((ARRAY)(ValueOf(&a).Elem()))
+		return
+	}
+	m.marshalString(v.String())
+}
+
 func (m *Marshaler) marshalArray(a ARRAY, ancestry ...ancestor) {
 	if a.Len() == 0 {
 		m.marshalEmptySlice()
@@ -442,8 +461,9 @@ func (m *Marshaler) marshalArray(a ARRAY, ancestry ...ancestor) {
 	}
 	delim, end, ancestry := m.marshalSliceStart((VALUE)(a), ancestry)
 	var j int
+	iface := (*arrayType)(unsafe.Pointer(a.typ)).elem.Kind() == Interface
 	a.ForEach(func(i int, k string, v VALUE) (brake bool) {
-		j = m.marshalElem(j, delim, nil, v, ancestry)
+		j = m.marshalElem(j, delim, nil, v, iface, ancestry)
 		return
 	})
 	m.marshalEnd(end)
@@ -456,12 +476,35 @@ func (m *Marshaler) marshalFunc(v VALUE) {
 func (m *Marshaler) marshalInterface(v VALUE, ancestry ...ancestor) {
 	v = v.SetType()
 	if v.Kind() != Interface {
+		if n, ok := v.typ.RegisteredName(); ok && m.TypeKey != "" && !v.IsNil() {
+			m.marshalTyped(n, v, ancestry...)
+			return
+		}
 		m.marshal(v, ancestry...)
 		return
 	}
 	m.marshalString(fmt.Sprint(v.Interface()))
 }
 
+// marshalTyped marshals VALUE v of a type registered by name as a map with
+// name at the TypeKey, the fields of structs and pointers to structs are
+// marshaled in the same map, other values are marshaled at key "$value"
+func (m *Marshaler) marshalTyped(name string, v VALUE, ancestry ...ancestor) {
+	if v.Kind() == Pointer {
+		ancestry = append([]ancestor{{v.typ, v.Uintptr()}}, ancestry...)
+		v = v.Elem()
+	}
+	delim, end, ancestry := m.marshalMapStart(v, ancestry)
+	j := m.marshalElem(0, delim, []byte(m.TypeKey), ValueOf(name), false, ancestry)
+	if v.KIND() == Struct {
+		m.marshalStructTag((STRUCT)(v))
+		m.marshalFields(j, delim, (STRUCT)(v), ancestry)
+	} else {
+		m.marshalElem(j, delim, []byte(typedValueKey), v, false, ancestry)
+	}
+	m.marshalEnd(end)
+}
+
 func (m *Marshaler) marshalPointer(v VALUE, ancestry ...ancestor) {
 	ancestry = append([]ancestor{{v.typ, v.Uintptr()}}, ancestry...)
 	m.marshal(v.Elem(), ancestry...)
@@ -474,8 +517,9 @@ func (m *Marshaler) marshalMap(hm MAP, ancestry ...ancestor) {
 	}
 	delim, end, ancestry := m.marshalMapStart((VALUE)(hm), ancestry)
 	var j int
-	hm.ForEach(func(i int, k string, v VALUE) (brake bool) {
-		j = m.marshalElem(j, delim, []byte(k), v, ancestry)
+	iface := (*mapType)(unsafe.Pointer(hm.typ)).elem.Kind() == Interface
+	hm.ForEachSorted(func(i int, k string, v VALUE) (brake bool) {
+		j = m.marshalElem(j, delim, []byte(k), v, iface, ancestry)
 		return
 	})
 	m.marshalEnd(end)
@@ -488,8 +532,9 @@ func (m *Marshaler) marshalSlice(s SLICE, ancestry ...ancestor) {
 	}
 	delim, end, ancestry := m.marshalSliceStart((VALUE)(s), ancestry)
 	var j int
+	iface := (*sliceType)(unsafe.Pointer(s.typ)).elem.Kind() == Interface
 	s.ForEach(func(i int, k string, v VALUE) (brake bool) {
-		j = m.marshalElem(j, delim, nil, v, ancestry)
+		j = m.marshalElem(j, delim, nil, v, iface, ancestry)
 		return
 	})
 	m.marshalEnd(end)
@@ -520,15 +565,23 @@ func (m *Marshaler) marshalStruct(s STRUCT, ancestry ...ancestor) {
 	}
 	delim, end, ancestry := m.marshalMapStart((VALUE)(s), ancestry)
 	m.marshalStructTag(s)
-	j, has, keys := 0, m.hasTag[s.typ], m.tagKeys[s.typ]
+	m.marshalFields(0, delim, s, ancestry)
+	m.marshalEnd(end)
+}
+
+// marshalFields marshals the fields of struct s as the elems of a map
+// from elem j, keyed by the tag of the marshaler Type or field name
+func (m *Marshaler) marshalFields(j int, delim []byte, s STRUCT, ancestry []ancestor) int {
+	has, keys := m.hasTag[s.typ], m.tagKeys[s.typ]
+	fs := (*structType)(unsafe.Pointer(s.typ)).fields
 	s.ForEach(func(i int, k string, v VALUE) (brake bool) {
 		if has {
 			k = keys[i]
 		}
-		j = m.marshalElem(j, delim, []byte(k), v, ancestry)
+		j = m.marshalElem(j, delim, []byte(k), v, fs[i].typ.Kind() == Interface, ancestry)
 		return
 	})
-	m.marshalEnd(end)
+	return j
 }
 
 func (m *Marshaler) marshaltStructByMethod(s STRUCT) bool {
@@ -539,29 +592,12 @@ func (m *Marshaler) marshaltStructByMethod(s STRUCT) bool {
 	if !m.MarshalMethods {
 		return false
 	}
-	if m.methods != nil {
-		if index, ok := m.methods[s.typ]; ok {
-			if index != -1 {
-				m.bufferBytes([]byte((VALUE)(s).Reflect().Method(index).Call(nil)[0].String()))
-				return true
-			}
-			return false
-		}
-	} else {
-		m.methods = map[*TYPE]int{s.typ: -1}
-	}
 	n := STRING(m.Type[:1]).ToUpper() + m.Type[1:]
-	methods := []string{n, "Marshal" + n}
-	for _, name := range methods {
-		meth, exists := s.typ.Reflect().MethodByName(name)
-		if exists {
-			in, out := meth.Type.NumIn(), meth.Type.NumOut()
-			if in == 1 && out > 0 {
-				if k := FromReflectType(meth.Type.Out(0)).KIND(); k == String || k == Bytes {
-					m.methods[s.typ] = meth.Index
-					m.bufferBytes([]byte((VALUE)(s).Reflect().Method(meth.Index).Call(nil)[0].String()))
-					return true
-				}
+	for _, name := range []string{string(n), "Marshal" + string(n)} {
+		if meth := s.typ.method(name); meth.index != -1 && meth.numIn == 1 && len(meth.out) > 0 {
+			if k := meth.out[0].KIND(); k == String || k == Bytes {
+				m.bufferBytes([]byte((VALUE)(s).callString(meth.index)))
+				return true
 			}
 		}
 	}
@@ -735,7 +771,9 @@ func (m *Marshaler) marshalMapStart(v VALUE, a []ancestor) (delim []byte, end []
 	return delim, end, append([]ancestor{{v.typ, v.Uintptr()}}, a...)
 }
 
-func (m *Marshaler) marshalElem(i int, delim, k []byte, v VALUE, ancestry []ancestor) int {
+// marshalElem marshals elem i of a slice or map, with key k for maps,
+// iface is true if the elem is held in an interface
+func (m *Marshaler) marshalElem(i int, delim, k []byte, v VALUE, iface bool, ancestry []ancestor) int {
 	v = v.SetType()
 	if i == 0 {
 		delim = nil
@@ -747,7 +785,11 @@ func (m *Marshaler) marshalElem(i int, delim, k []byte, v VALUE, ancestry []ance
 				m.bufferElem(delim, k, m.Null)
 			} else {
 				m.bufferElem(delim, k, nil)
-				m.marshal(v)
+				if iface {
+					m.marshalInterface(v)
+				} else {
+					m.marshal(v)
+				}
 			}
 			return i
 		}
@@ -766,6 +808,10 @@ func (m *Marshaler) marshalElem(i int, delim, k []byte, v VALUE, ancestry []ance
 	}
 	i++
 	m.bufferElem(delim, k, nil)
+	if iface {
+		m.marshalInterface(v, ancestry...)
+		return i
+	}
 	m.marshal(v, ancestry...)
 	return i
 }
@@ -837,10 +883,10 @@ func (m *Marshaler) recursiveValue(v VALUE, ancestry []ancestor) (bytes []byte,
 		if a.pointer == v.Uintptr() && a.typ == v.typ {
 			is = true
 			if v.Kind() == Struct && m.RecursiveName {
-				if m, ok := v.typ.Reflect().MethodByName("Name"); ok {
-					bytes = []byte(v.Reflect().Method(m.Index).Call([]reflect.Value{})[0].String())
-				} else if m, ok := v.typ.Reflect().MethodByName("String"); ok {
-					bytes = []byte(v.Reflect().Method(m.Index).Call([]reflect.Value{})[0].String())
+				if m := v.typ.method("Name"); m.index != -1 {
+					bytes = []byte(v.callString(m.index))
+				} else if m := v.typ.method("String"); m.index != -1 {
+					bytes = []byte(v.callString(m.index))
 				} else {
 					bytes = []byte(v.typ.NameShort())
 				}
@@ -1003,6 +1049,23 @@ func (m *Marshaler) Unmarshal(bytes ...[]byte) *Marshaler {
 	return m
 }
 
+// UnmarshalInto unmarshals the bytes provided, or the marshaler buffer
+// if none are provided, into the value pointed to by dest.
+// Struct fields are matched to keys using the tag of the marshaler Type
+// (eg. `json:"name"`) or the field name, and values are converted to
+// the kind of the destination. Maps with a registered type name at the
+// TypeKey are unmarshaled into interfaces as values of the type,
+// maps with an unregistered name are unmarshaled as plain maps
+func (m *Marshaler) UnmarshalInto(dest any, bytes ...[]byte) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	m.Unmarshal(bytes...)
+	return Merge(dest, m.value, MergeKeyTag(m.Type), MergeZeros(true), MergeDefaults(m.UnmarshalDefaults), MergeConversion(m.Conversion), MergeTypeKey(m.TypeKey))
+}
+
 func (m *Marshaler) unmarshalObject(ancestry ...ancestor) (slice []any, hmap map[string]any) {
 	if delim, end, isSlice := m.unmarshalSliceStart(ancestry); isSlice {
 		return m.unmarshalSlice(delim, end, ancestry...), nil
@@ -1015,6 +1078,9 @@ func (m *Marshaler) unmarshalObject(ancestry ...ancestor) (slice []any, hmap map
 
 func (m *Marshaler) unmarshalSlice(delim, end []byte, ancestry ...ancestor) (slice []any) {
 	ancestry = append([]ancestor{{&TYPE{kind: 23}, 0}}, ancestry...)
+	if m.unmarshalEmpty(end) {
+		return []any{}
+	}
 	for m.cursor < m.len {
 		slice = append(slice, m.unmarshalItem([][]byte{delim, end}, ancestry...))
 		m.unmarshalNonData()
@@ -1035,6 +1101,9 @@ func (m *Marshaler) unmarshalSlice(delim, end []byte, ancestry ...ancestor) (sli
 func (m *Marshaler) unmarshalMap(delim, end []byte, ancestry ...ancestor) map[string]any {
 	ancestry = append([]ancestor{{&TYPE{kind: 53}, 0}}, ancestry...)
 	hmap := map[string]any{}
+	if m.unmarshalEmpty(end) {
+		return hmap
+	}
 	for m.cursor < m.len {
 		hmap[m.unmarshalKey()] = m.unmarshalItem([][]byte{delim, end}, ancestry...)
 		m.unmarshalNonData()
@@ -1051,6 +1120,18 @@ func (m *Marshaler) unmarshalMap(delim, end []byte, ancestry ...ancestor) map[st
 	return hmap
 }
 
+// unmarshalEmpty evaluates whether the slice or map at the
+// cursor is empty and if so, moves the cursor past its end
+func (m *Marshaler) unmarshalEmpty(end []byte) bool {
+	m.unmarshalNonData()
+	if end == nil || m.cursor >= m.len || !m.isMatch(end) {
+		return false
+	}
+	m.Inc(len(end))
+	m.decDepth()
+	return true
+}
+
 func (m *Marshaler) unmarshalItem(endings [][]byte, ancestry ...ancestor) any {
 	m.unmarshalNonData()
 	switch {
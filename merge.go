@@ -0,0 +1,520 @@
+// Copyright 2023 james dotter. All rights reserved.
+// Use of this source code is governed by a
+// license that can be found in the gotype LICENSE file.
+
+package gotype
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unsafe"
+)
+
+// ------------------------------------------------------------ /
+// MERGE IMPLEMENTATION
+// recursive merging of maps, structs, slices and Gmaps
+// for layering values such as defaults, files and overrides
+// ------------------------------------------------------------ /
+
+// MergeStrategy determines how slices are merged
+type MergeStrategy uint8
+
+const (
+	MergeReplace MergeStrategy = iota // src slice replaces dst slice
+	MergeAppend                       // src elements are appended to dst slice
+	MergeByKey                        // src elements are merged into dst elements with matching key
+)
+
+var mergeStrategies = map[string]MergeStrategy{
+	"replace": MergeReplace,
+	"append":  MergeAppend,
+	"key":     MergeByKey,
+}
+
+// MergeOption configures the behavior of Merge
+type MergeOption func(*merger)
+
+type merger struct {
+	slices   MergeStrategy    // strategy used to merge slices
+	key      string           // field or map key identifying slice elements for MergeByKey
+	zeros    bool             // when true, zero values in src override values in dst
+	tag      string           // struct tag holding per field merge strategies
+	keyTag   string           // struct tag used to match struct fields to map keys
+	defaults bool             // when true, struct fields missing from src are set to their defaults
+	policy   ConversionPolicy // policy of numeric conversions from src to dst
+	typeKey  string           // map key holding the registered type name of values merged into interfaces
+}
+
+// MergeSlices sets the strategy used to merge slices,
+// key is the field or map key identifying elements when merging by key
+func MergeSlices(s MergeStrategy, key ...string) MergeOption {
+	return func(m *merger) {
+		m.slices = s
+		if len(key) > 0 {
+			m.key = key[0]
+		}
+	}
+}
+
+// MergeZeros sets whether zero values in src override values in dst,
+// by default zero values in src are ignored and dst values are kept
+func MergeZeros(override bool) MergeOption {
+	return func(m *merger) {
+		m.zeros = override
+	}
+}
+
+// MergeTag sets the struct tag holding per field merge strategies,
+// defaults to "merge", eg. `merge:"slice:'key' key:'ID' zero:'override'"`
+func MergeTag(tag string) MergeOption {
+	return func(m *merger) {
+		m.tag = tag
+	}
+}
+
+// MergeKeyTag sets the struct tag used to match struct fields
+// to map keys, defaults to "json"
+func MergeKeyTag(tag string) MergeOption {
+	return func(m *merger) {
+		m.keyTag = tag
+	}
+}
+
+// MergeDefaults sets whether struct fields in dst with no
+// matching key in src are set to the value of their default tag
+func MergeDefaults(apply bool) MergeOption {
+	return func(m *merger) {
+		m.defaults = apply
+	}
+}
+
+// MergeConversion sets the ConversionPolicy of numeric conversions
+// from src to dst, defaults to ConvertDefault
+func MergeConversion(p ConversionPolicy) MergeOption {
+	return func(m *merger) {
+		m.policy = p
+	}
+}
+
+// MergeTypeKey sets the map key holding the registered type name of
+// maps merged into interfaces, eg. "$type", so that the interface is set
+// to a value of the type, see RegisterTypeName. No key by default
+func MergeTypeKey(key string) MergeOption {
+	return func(m *merger) {
+		m.typeKey = key
+	}
+}
+
+// Merge recursively merges src into the pointer dst,
+// where dst and src are maps, structs, slices or Gmaps (or pointers to these).
+// Maps and structs are merged key by key, slices are merged using the
+// MergeStrategy provided and all other values in src replace those in dst
+// unless the src value is zero. Struct fields may set their own strategy
+// with the merge tag, eg. `merge:"slice:'append'"` or `merge:"-"` to skip the field.
+// Returns an error if a value of src cannot be converted or merged into dst
+func Merge(dst, src any, opts ...MergeOption) (err error) {
+	m := &merger{tag: "merge", keyTag: "json"}
+	for _, o := range opts {
+		o(m)
+	}
+	d := ValueOfV(dst)
+	if d.Kind() != Pointer || d.Pointer() == nil {
+		return errors.New("merge: dst must be a non nil pointer")
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			if !recoverable(r) {
+				panic(r)
+			}
+			err = fmt.Errorf("merge: %v", r)
+		}
+	}()
+	return m.merge(d.Elem(), ValueOfV(src), "")
+}
+
+// merge merges the src value s into the addressable dst value d
+func (m *merger) merge(d, s VALUE, path string) error {
+	if s = mergeSrc(s); s.typ == nil || s.IsNil() {
+		if m.zeros && d.typ != nil {
+			typedmemmove(d.typ, d.ptr, d.typ.New().ptr)
+		}
+		return nil
+	}
+	if d.typ == gmapType {
+		return m.mergeGmap((*Gmap)(d.ptr), s, path)
+	}
+	if d.KIND().IsBasic() {
+		return m.mergeBasic(d, s, path)
+	}
+	switch d.Kind() {
+	case Pointer:
+		if *(*unsafe.Pointer)(d.ptr) == nil {
+			*(*unsafe.Pointer)(d.ptr) = d.typ.Elem().New().ptr
+		}
+		return m.merge(d.Elem(), s, path)
+	case Interface:
+		return m.mergeInterface(d, s, path)
+	case Struct:
+		return m.mergeStruct((STRUCT)(d), s, path)
+	case Map:
+		return m.mergeMap(d, s, path)
+	case Slice:
+		return m.mergeSlice((SLICE)(d), s, path)
+	case Array:
+		if k := s.Kind(); k != Array && k != Slice {
+			return mergeError(path, d, s)
+		}
+		a := (ARRAY)(d)
+		for i := 0; i < a.Len() && i < s.Len(); i++ {
+			if err := m.merge(a.index(i), s.Index(i), mergePath(path, i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return m.mergeBasic(d, s, path)
+}
+
+// mergeBasic replaces the dst value with src unless src is zero
+func (m *merger) mergeBasic(d, s VALUE, path string) error {
+	if !m.zeros && s.IsZero() {
+		return nil
+	}
+	if _, err := d.SetWith(s, m.policy); err != nil {
+		return fmt.Errorf("cannot merge at '%s': %w", path, err)
+	}
+	return nil
+}
+
+// mergeInterface merges src into the value held by an interface,
+// the held value is copied, merged and stored back in the interface
+func (m *merger) mergeInterface(d, s VALUE, path string) error {
+	e := d.SetType()
+	if t, v, err := m.typed(s, path); err != nil || t != nil {
+		if err != nil {
+			return err
+		}
+		n := t.New().Elem()
+		if e.typ == t {
+			n = mergeCopy(e)
+		}
+		if err = m.merge(n, v, path); err != nil {
+			return err
+		}
+		*(*any)(d.ptr) = n.Interface()
+		return nil
+	}
+	if e.Kind() == Interface || !mergeable(e) || !mergeable(s) {
+		return m.mergeBasic(d, s, path)
+	}
+	n := mergeCopy(e)
+	if err := m.merge(n, s, path); err != nil {
+		return err
+	}
+	*(*any)(d.ptr) = n.Interface()
+	return nil
+}
+
+// typed returns the TYPE registered by the name at the type key of
+// the src map and the src value of the TYPE, which is the map for
+// structs and the value at key "$value" for other types. Returns a nil
+// TYPE if src has no type key or the name is not registered, so that
+// maps of data with an unregistered type key are merged as plain maps
+func (m *merger) typed(s VALUE, path string) (*TYPE, VALUE, error) {
+	if m.typeKey == "" {
+		return nil, s, nil
+	}
+	if s = mergeSrc(s); s.typ == nil || s.Kind() != Map {
+		return nil, s, nil
+	}
+	name, ok := mergeKey(s, m.typeKey)
+	if !ok {
+		return nil, s, nil
+	}
+	t := TypeByName(name)
+	if t == nil {
+		return nil, s, nil
+	}
+	if t.DeepPtrElem().KIND() != Struct {
+		if (MAP)(s).KeyPtr(typedValueKey) == nil {
+			return t, VALUE{}, nil
+		}
+		return t, (MAP)(s).Index(typedValueKey), nil
+	}
+	return t, s, nil
+}
+
+// mergeStruct merges the fields or keys of src into the fields of struct d
+func (m *merger) mergeStruct(d STRUCT, s VALUE, path string) (err error) {
+	if !mergeable(s) || s.Kind() == Slice || s.Kind() == Array {
+		return mergeError(path, (VALUE)(d), s)
+	}
+	pairs := m.pairs(s)
+	d.ForFields(true, func(i int, f FIELD) (brake bool) {
+		if !f.Visible() {
+			return
+		}
+		fm := m.field(f)
+		if fm == nil {
+			return
+		}
+		v, ok := pairs[m.fieldKey(f)]
+		if !ok {
+			if v, ok = pairs[f.name]; !ok {
+				if m.defaults {
+					applyFieldDefault(f)
+				}
+				return
+			}
+		}
+		err = fm.merge(f.VALUE(), v, mergePath(path, f.name))
+		return err != nil
+	})
+	return
+}
+
+// mergeMap merges the fields or keys of src into the map d
+func (m *merger) mergeMap(d, s VALUE, path string) (err error) {
+	if !mergeable(s) || s.Kind() == Slice || s.Kind() == Array {
+		return mergeError(path, d, s)
+	}
+	if *(*unsafe.Pointer)(d.ptr) == nil {
+		*(*unsafe.Pointer)(d.ptr) = makemap(d.typ, 0, nil)
+	}
+	dm, t := (MAP)(d), (*mapType)(unsafe.Pointer(d.typ)).elem
+	for _, p := range m.pairList(s) {
+		e := dm.KeyPtr(p.Key)
+		if e == nil && (t.Kind() != Interface || m.typeKey == "") {
+			dm.Set(p.Key, p.Value)
+			continue
+		}
+		n := t.New().Elem()
+		if e != nil {
+			typedmemmove(t, n.ptr, e)
+		}
+		if err = m.merge(n, p.Value, mergePath(path, p.Key)); err != nil {
+			return
+		}
+		dm.Set(p.Key, n)
+	}
+	return
+}
+
+// mergeGmap merges the fields or keys of src into Gmap g
+func (m *merger) mergeGmap(g *Gmap, s VALUE, path string) error {
+	if !mergeable(s) || s.Kind() == Slice || s.Kind() == Array {
+		return errors.New("cannot merge " + s.typ.String() + " into Gmap at '" + path + "'")
+	}
+	for _, p := range m.pairList(s) {
+		e, ok := g.Get(p.Key)
+		if !ok || e.typ == nil {
+			g.Set(p.Key, p.Value)
+			continue
+		}
+		e = e.SetType()
+		if !mergeable(e) || !mergeable(p.Value) {
+			if m.zeros || !p.Value.IsZero() {
+				g.Set(p.Key, p.Value)
+			}
+			continue
+		}
+		n := mergeCopy(e)
+		if err := m.merge(n, p.Value, mergePath(path, p.Key)); err != nil {
+			return err
+		}
+		g.Set(p.Key, n)
+	}
+	return nil
+}
+
+// mergeSlice merges src into slice d using the merger's slice strategy
+func (m *merger) mergeSlice(d SLICE, s VALUE, path string) error {
+	if k := s.Kind(); k != Slice && k != Array {
+		return mergeError(path, (VALUE)(d), s)
+	}
+	l := s.Len()
+	switch m.slices {
+	case MergeReplace:
+		if l == 0 && !m.zeros {
+			return nil
+		}
+		*(*sliceHeader)(d.ptr) = sliceHeader{}
+		fallthrough
+	case MergeAppend:
+		n := d.Len()
+		d.Extend(l)
+		for i := 0; i < l; i++ {
+			if err := m.merge(d.index(n+i), s.Index(i), mergePath(path, n+i)); err != nil {
+				return err
+			}
+		}
+	case MergeByKey:
+		if m.key == "" {
+			return errors.New("merge by key requires a key at '" + path + "'")
+		}
+		keys := map[string]int{}
+		d.ForEach(func(i int, _ string, e VALUE) (brake bool) {
+			if k, ok := mergeKey(e, m.key); ok {
+				keys[k] = i
+			}
+			return
+		})
+		for i := 0; i < l; i++ {
+			e := s.Index(i)
+			k, ok := mergeKey(e, m.key)
+			j, found := keys[k]
+			if !ok || !found {
+				j = d.Len()
+				d.Extend(1)
+				if ok {
+					keys[k] = j
+				}
+			}
+			if err := m.merge(d.index(j), e, mergePath(path, j)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// field returns the merger for the struct field f
+// using the strategies in the field's merge tag,
+// returns nil if the field is excluded from merging
+func (m *merger) field(f FIELD) *merger {
+	if f.rawtag == "" {
+		return m
+	}
+	t := f.Tag(m.tag)
+	if t == "" {
+		return m
+	}
+	if t == "-" {
+		return nil
+	}
+	fm := *m
+	for k, v := range parseTags(t, `'`[0]) {
+		switch k {
+		case "slice":
+			if s, ok := mergeStrategies[v]; ok {
+				fm.slices = s
+			}
+		case "key":
+			fm.key = v
+		case "zero":
+			fm.zeros = v == "override"
+		}
+	}
+	return &fm
+}
+
+// fieldKey returns the key of field f when matched to map keys
+func (m *merger) fieldKey(f FIELD) string {
+	if m.keyTag != "" && f.rawtag != "" {
+		if k, _, _ := strings.Cut(f.Tag(m.keyTag), ","); k != "" && k != "-" {
+			return k
+		}
+	}
+	return f.name
+}
+
+// pairList returns the key value pairs of a map, struct or Gmap VALUE
+func (m *merger) pairList(s VALUE) (g Gmap) {
+	switch {
+	case s.typ == gmapType:
+		return *(*Gmap)(s.ptr)
+	case s.Kind() == Map:
+		(MAP)(s).ForEach(func(i int, k string, v VALUE) (brake bool) {
+			g = append(g, GmapEl{k, v})
+			return
+		})
+	case s.Kind() == Struct:
+		(STRUCT)(s).ForFields(true, func(i int, f FIELD) (brake bool) {
+			if f.Visible() {
+				g = append(g, GmapEl{m.fieldKey(f), f.VALUE().SetType()})
+			}
+			return
+		})
+	}
+	return
+}
+
+// pairs returns the key value pairs of a map, struct or Gmap VALUE as a map
+func (m *merger) pairs(s VALUE) map[string]VALUE {
+	l := m.pairList(s)
+	p := make(map[string]VALUE, len(l))
+	for _, e := range l {
+		p[e.Key] = e.Value
+	}
+	return p
+}
+
+var gmapType = TypeOf(Gmap{})
+
+// mergeSrc dereferences pointers and interfaces of the src VALUE
+func mergeSrc(s VALUE) VALUE {
+	if s.typ == nil {
+		return s
+	}
+	s = s.SetType()
+	for s.Kind() == Pointer {
+		if s.IsNil() {
+			return VALUE{}
+		}
+		s = s.Elem().SetType()
+	}
+	return s
+}
+
+// mergeCopy returns an addressable copy of VALUE v
+func mergeCopy(v VALUE) VALUE {
+	n, p := v.typ.New().Elem(), v.ptr
+	if v.flag&flagIndir == 0 {
+		p = unsafe.Pointer(&v.ptr)
+	}
+	typedmemmove(v.typ, n.ptr, p)
+	return n
+}
+
+// mergeable evaluates whether VALUE v is a container that can be merged into
+func mergeable(v VALUE) bool {
+	if v.typ == gmapType {
+		return true
+	}
+	switch v.KIND() {
+	case Map, Struct, Slice, Array, Pointer:
+		return true
+	}
+	return false
+}
+
+// mergeKey returns the string value of key in the struct or map VALUE v
+func mergeKey(v VALUE, key string) (string, bool) {
+	if v = mergeSrc(v); v.typ == nil {
+		return "", false
+	}
+	switch v.Kind() {
+	case Struct:
+		if f := v.typ.FieldByName(key); f != nil {
+			return VALUE{f.typ, offset(v.ptr, f.offset), flagIndir | flag(f.typ.Kind())}.SetType().String(), true
+		}
+	case Map:
+		if (MAP)(v).KeyPtr(key) != nil {
+			return (MAP)(v).Index(key).String(), true
+		}
+	}
+	return "", false
+}
+
+func mergePath(path string, key any) string {
+	k := fmt.Sprint(key)
+	if path == "" {
+		return k
+	}
+	return path + "." + k
+}
+
+func mergeError(path string, d, s VALUE) error {
+	return errors.New("cannot merge " + s.typ.String() + " into " + d.typ.String() + " at '" + path + "'")
+}
@@ -0,0 +1,86 @@
+// Copyright 2023 james dotter. All rights reserved.
+// Use of this source code is governed by a
+// license that can be found in the gotype LICENSE file.
+
+package gotype
+
+import (
+	"errors"
+	"sync"
+)
+
+// ------------------------------------------------------------ /
+// TYPE REGISTRY IMPLEMENTATION
+// registry of types by name for recording and restoring the
+// concrete types of values held in interfaces when encoding
+// and marshaling
+// ------------------------------------------------------------ /
+
+var (
+	namedTypes sync.Map   // map of type name to *TYPE
+	typeNames  sync.Map   // map of *TYPE to type name
+	registerMu sync.Mutex // guards registration across both maps
+
+	ErrUnregisteredType = errors.New("type is not registered")
+)
+
+// typedValueKey is the key of values of registered types other than
+// structs in maps keyed by the Marshaler TypeKey
+const typedValueKey = "$value"
+
+// RegisterType registers the type of a by its name, eg. "pkg.Type",
+// see RegisterTypeName
+func RegisterType(a any) {
+	RegisterTypeName(TypeOf(a).String(), a)
+}
+
+// RegisterTypeName registers the type of a by name, so that values of the
+// type held in interfaces are encoded and marshaled with name, and are
+// decoded and unmarshaled to the type.
+// Panics if name or the type is already registered to another type or name
+func RegisterTypeName(name string, a any) {
+	t := TypeOf(a)
+	if t == nil {
+		panic("cannot register type of nil value")
+	}
+	registerMu.Lock()
+	defer registerMu.Unlock()
+	if n, ok := typeNames.Load(t); ok && n.(string) != name {
+		panic("type " + t.String() + " is already registered as " + n.(string))
+	}
+	if r, ok := namedTypes.Load(name); ok && r.(*TYPE) != t {
+		panic("name " + name + " is already registered to type " + r.(*TYPE).String())
+	}
+	typeNames.Store(t, name)
+	namedTypes.Store(name, t)
+}
+
+// TypeByName returns the TYPE registered by name, names prefixed with
+// "*" return pointers to the TYPE registered by the rest of the name.
+// Returns nil if no type is registered by name
+func TypeByName(name string) *TYPE {
+	if t, ok := namedTypes.Load(name); ok {
+		return t.(*TYPE)
+	}
+	if len(name) > 0 && name[0] == '*' {
+		if t := TypeByName(name[1:]); t != nil {
+			return t.PtrType()
+		}
+	}
+	return nil
+}
+
+// RegisteredName returns the name the TYPE is registered by, or
+// the name of its elem prefixed with "*" for pointers to registered
+// types, and false if neither the TYPE or its elem is registered
+func (t *TYPE) RegisteredName() (string, bool) {
+	if n, ok := typeNames.Load(t); ok {
+		return n.(string), true
+	}
+	if t.Kind() == Pointer {
+		if n, ok := t.Elem().RegisteredName(); ok {
+			return "*" + n, true
+		}
+	}
+	return "", false
+}
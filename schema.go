@@ -0,0 +1,396 @@
+// Copyright 2023 james dotter. All rights reserved.
+// Use of this source code is governed by a
+// license that can be found in the gotype LICENSE file.
+
+package gotype
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/fnv"
+	"strconv"
+	"unsafe"
+)
+
+// ------------------------------------------------------------ /
+// GOTYPE ENCODING SCHEMA
+// self describing encodings prefixed with a versioned schema
+// header of the encoded type, enabling structs to be decoded
+// by field name after their fields are added or reordered
+// ------------------------------------------------------------ /
+
+// SCHEMA HEADER FORMAT
+// []byte{SchemaHeader, Version, Fingerprint..., Schema..., Encoding...}
+//
+// Schema:			Kind[, KeyKind][, ElemSchema]
+//					Struct, Len, [NameLen, Name..., FieldSchema]...
+//
+// KeyKind:			kind of the keys of a map
+// ElemSchema:		schema of the elems of a slice, array or map
+// Fingerprint:		8 byte FNV-1a hash of the Schema bytes, identifying the type
+//
+// Pointers are described by the schema of their elem and interfaces by
+// Interface, as values held in interfaces are encoded with their own kind.
+// Pointers shared by values are not shared by the values decoded by schema
+//
+// SCHEMA EVOLUTION:
+// struct fields are matched by name, fields of the encoding not in dest
+// are skipped and fields of dest not in the encoding are zeroed. Numeric
+// kinds decode to any numeric kind and strings to bytes; other kind
+// changes are rejected with a SchemaError
+
+const (
+	SchemaHeader    byte = 0x80 // first byte of an encoding with a schema header
+	EncodingVersion byte = 1    // latest version of the encoding format
+)
+
+var ErrEncodingVersion = errors.New("unsupported encoding version")
+
+// Schema describes the type of an encoded value
+type Schema struct {
+	Kind   KIND          // kind of the value
+	Key    KIND          // kind of the keys of a map
+	Elem   *Schema       // schema of the elems of a slice, array or map
+	Fields []SchemaField // fields of a struct
+}
+
+// SchemaField is the name and schema of a struct field
+type SchemaField struct {
+	Name string
+	*Schema
+}
+
+// typeSchema is the cached schema of a TYPE
+type typeSchema struct {
+	s  *Schema
+	b  []byte // encoded schema
+	fp uint64 // fingerprint of the encoded schema
+}
+
+// Schema returns the schema describing the encoding of the TYPE,
+// panics if the TYPE is recursive. Must not be modified
+func (t *TYPE) Schema() *Schema {
+	return t.schema().s
+}
+
+// Fingerprint returns the hash of the schema of the TYPE
+func (t *TYPE) Fingerprint() uint64 {
+	return t.schema().fp
+}
+
+// schema returns the cached schema of the TYPE
+func (t *TYPE) schema() *typeSchema {
+	m := t.meta()
+	if s := m.schema.Load(); s != nil {
+		return s
+	}
+	s := &typeSchema{s: schemaOf(t, nil)}
+	s.b = s.s.encode(nil)
+	s.fp = fingerprint(s.b)
+	m.schema.Store(s)
+	return s
+}
+
+// schemaOf returns the schema of TYPE t,
+// seen are the struct TYPEs containing t
+func schemaOf(t *TYPE, seen []*TYPE) *Schema {
+	t = t.DeepPtrElem()
+	s := &Schema{Kind: t.KIND()}
+	switch s.Kind {
+	case Array, Slice:
+		s.Elem = schemaOf(t.Elem(), seen)
+	case Map:
+		m := (*mapType)(unsafe.Pointer(t))
+		s.Key, s.Elem = m.key.KIND(), schemaOf(m.elem, seen)
+	case Struct:
+		for _, p := range seen {
+			if p == t {
+				panic("cannot describe schema of recursive type " + t.String())
+			}
+		}
+		seen = append(seen, t)
+		fs := t.fieldMetas()
+		s.Fields = make([]SchemaField, len(fs))
+		for i, f := range fs {
+			s.Fields[i] = SchemaField{f.name, schemaOf(f.typ, seen)}
+		}
+	}
+	return s
+}
+
+// fingerprint returns the FNV-1a hash of an encoded schema
+func fingerprint(b []byte) uint64 {
+	h := fnv.New64a()
+	h.Write(b)
+	return h.Sum64()
+}
+
+// encode appends the encoding of the schema to b
+func (s *Schema) encode(b []byte) []byte {
+	b = append(b, s.Kind.Byte())
+	switch s.Kind {
+	case Array, Slice:
+		b = s.Elem.encode(b)
+	case Map:
+		b = s.Elem.encode(append(b, s.Key.Byte()))
+	case Struct:
+		b = append(b, lenBytes(len(s.Fields))...)
+		for _, f := range s.Fields {
+			b = append(append(b, lenBytes(len(f.Name))...), f.Name...)
+			b = f.encode(b)
+		}
+	}
+	return b
+}
+
+// EncodeSchema returns the encoding of VALUE prefixed
+// with a schema header describing the type of VALUE
+func (v VALUE) EncodeSchema() ENCODING {
+	t := TypeOf((*any)(nil)).Elem() // schema of a nil value
+	if v = v.SetType(); v.typ != nil {
+		t = v.typ
+	}
+	s := t.schema()
+	e := make([]byte, 10, 10+len(s.b))
+	e[0], e[1] = SchemaHeader, EncodingVersion
+	binary.LittleEndian.PutUint64(e[2:], s.fp)
+	e = append(e, s.b...)
+	return append(e, v.Encode()...)
+}
+
+// EncodeSchema encodes any value to bytes prefixed
+// with a schema header describing the type of the value
+func EncodeSchema(a any) ENCODING {
+	return ValueOfV(a).EncodeSchema()
+}
+
+// Schema returns the schema of the header of the encoding,
+// returns nil if the encoding has no schema header
+func (e ENCODING) Schema() *Schema {
+	if e.isCompressed() {
+		e = e[2:]
+	}
+	s, _, _ := e.header()
+	return s
+}
+
+// Fingerprint returns the fingerprint of the schema header of
+// the encoding, returns 0 if the encoding has no schema header
+func (e ENCODING) Fingerprint() uint64 {
+	if e.isCompressed() {
+		e = e[2:]
+	}
+	_, fp, _ := e.header()
+	return fp
+}
+
+// hasHeader returns true if the encoding has a schema header
+func (e ENCODING) hasHeader() bool {
+	return len(e) > 0 && e[0] == SchemaHeader
+}
+
+// header decodes the schema header of the encoding and returns the schema,
+// its fingerprint and the number of bytes of the header, returns nil if the
+// encoding has no header and panics if the header is corrupt or of a later version
+func (e ENCODING) header() (s *Schema, fp uint64, n int) {
+	if !e.hasHeader() {
+		return
+	}
+	e.LenAtLeast(11)
+	if e[1] == 0 || e[1] > EncodingVersion {
+		panic(ErrEncodingVersion)
+	}
+	fp = binary.LittleEndian.Uint64(e[2:10])
+	s, n = e.decodeSchema(10)
+	if fingerprint(e[10:n]) != fp {
+		panic("corrupt encoding")
+	}
+	return
+}
+
+// decodeSchema decodes the schema at offset i of the encoding and
+// returns the schema and the offset of the end of the schema
+func (e ENCODING) decodeSchema(i int) (s *Schema, n int) {
+	e.LenAtLeast(i + 1)
+	s, n = &Schema{Kind: KIND(e[i])}, i+1
+	switch s.Kind {
+	case Array, Slice:
+		s.Elem, n = e.decodeSchema(n)
+	case Map:
+		e.LenAtLeast(n + 1)
+		s.Key = KIND(e[n])
+		s.Elem, n = e.decodeSchema(n + 1)
+	case Struct:
+		var l, nl int
+		l, n = e.decodeLen(uintptr(n))
+		s.Fields = make([]SchemaField, l)
+		for j := range s.Fields {
+			nl, n = e.decodeLen(uintptr(n))
+			e.LenAtLeast(n + nl)
+			s.Fields[j].Name = string(e[n : n+nl])
+			s.Fields[j].Schema, n = e.decodeSchema(n + nl)
+		}
+	}
+	return
+}
+
+// decodeWithSchema decodes an encoding with a schema header into
+// pointer dest and returns the number of bytes decoded, panics
+// with a SchemaError if dest is incompatible with the schema
+func (e ENCODING) decodeWithSchema(dest any) int {
+	s, _, n := e.header()
+	enc := e[n:].Decodex()
+	if err := decodeSchemaSet(s, enc.v, destValue(dest)); err != nil {
+		panic(err)
+	}
+	return n + enc.b
+}
+
+// decodeSchemaSet cascades through decoded values eVal described
+// by schema s and inserts values into destination dVal
+func decodeSchemaSet(s *Schema, eVal VALUE, dVal VALUE) *SchemaError {
+	if decodedNil(eVal) {
+		typedmemclr(dVal.typ, dVal.ptr)
+		return nil
+	}
+	eVal = eVal.SetType()
+	if s.Kind == Interface || dVal.Kind() == Interface {
+		decodeValueSet(eVal, dVal, decodeRefs{})
+		return nil
+	}
+	for eVal.Kind() == Pointer && !decodedNil(eVal) {
+		eVal = eVal.Elem().SetType()
+	}
+	if decodedNil(eVal) {
+		typedmemclr(dVal.typ, dVal.ptr)
+		return nil
+	}
+	for dVal.Kind() == Pointer {
+		dVal = dVal.Init().Elem()
+	}
+	if !s.matches(eVal) {
+		panic("corrupt encoding")
+	}
+	dKind := dVal.KIND()
+	if s.Kind.IsBasic() {
+		if !schemaCompatible(s.Kind, dKind) {
+			return &SchemaError{From: s.Kind, To: dKind}
+		}
+		dVal.Set(eVal)
+		return nil
+	}
+	switch s.Kind {
+	case Array, Slice:
+		ea := eVal.ARRAY()
+		el := ea.Len()
+		var da ARRAY
+		switch dKind {
+		case Array:
+			if da = dVal.ARRAY(); el > da.Len() {
+				panic("cannot decode to array of differing length")
+			}
+		case Slice:
+			ds := dVal.SLICE()
+			if n := el - ds.Len(); n > 0 {
+				ds.Extend(n)
+			}
+			da = dVal.ARRAY()
+		default:
+			return &SchemaError{From: s.Kind, To: dKind}
+		}
+		for i := 0; i < el; i++ {
+			if err := decodeSchemaSet(s.Elem, ea.index(i), da.index(i)); err != nil {
+				return err.in("[" + strconv.Itoa(i) + "]")
+			}
+		}
+		return nil
+	case Map:
+		if dKind != Map {
+			return &SchemaError{From: s.Kind, To: dKind}
+		}
+		dm := dVal.Init().MAP()
+		kt, t := (*mapType)(unsafe.Pointer(dm.typ)).key, (*mapType)(unsafe.Pointer(dm.typ)).elem
+		if !schemaCompatible(s.Key, kt.KIND()) {
+			return (&SchemaError{From: s.Key, To: kt.KIND()}).in("[key]")
+		}
+		var err *SchemaError
+		eVal.MAP().ForEachKey(func(i int, k, v VALUE) (brake bool) {
+			dv := t.New().Elem()
+			if p := dm.KeyPtr(k); p != nil {
+				typedmemmove(t, dv.ptr, p)
+			}
+			if err = decodeSchemaSet(s.Elem, v, dv); err != nil {
+				err = err.in("[" + mapKeyString(k) + "]")
+				return true
+			}
+			dm.Set(k, dv)
+			return
+		})
+		return err
+	case Struct:
+		if dKind != Struct {
+			return &SchemaError{From: s.Kind, To: dKind}
+		}
+		ea, fs := eVal.ARRAY(), dVal.typ.fieldMetas()
+		matched := make([]bool, len(fs))
+		for i, f := range s.Fields {
+			j := schemaField(fs, i, f.Name)
+			if j < 0 {
+				continue // field removed from dest
+			}
+			matched[j] = true
+			if err := decodeSchemaSet(f.Schema, ea.index(i), (STRUCT)(dVal).field(j)); err != nil {
+				return err.in(f.Name)
+			}
+		}
+		for j, ok := range matched {
+			if !ok { // field added to dest
+				typedmemclr(fs[j].typ, offset(dVal.ptr, fs[j].offset))
+			}
+		}
+		return nil
+	}
+	panic("cannot decode encoding of " + s.Kind.String())
+}
+
+// matches returns true if decoded value eVal is of the kind described by the schema
+func (s *Schema) matches(eVal VALUE) bool {
+	switch k := eVal.KIND(); s.Kind {
+	case Array, Slice:
+		return k == Array || k == Slice
+	case Struct:
+		return k == Slice && eVal.Len() == len(s.Fields)
+	default:
+		return k == s.Kind
+	}
+}
+
+// schemaField returns the index of the field named n in fields fs,
+// checking index i first, returns -1 if fs has no field named n
+func schemaField(fs []fieldMeta, i int, n string) int {
+	if i < len(fs) && fs[i].name == n {
+		return i
+	}
+	for j, f := range fs {
+		if f.name == n {
+			return j
+		}
+	}
+	return -1
+}
+
+// schemaCompatible returns true if values encoded as
+// KIND from can be decoded to a destination of KIND to
+func schemaCompatible(from, to KIND) bool {
+	switch {
+	case from == to:
+		return true
+	case from.IsNumeric() || from == Uintptr:
+		return to.IsNumeric() || to == Uintptr || isComplexKind(to)
+	case isComplexKind(from):
+		return isComplexKind(to)
+	case from == String || from == Bytes:
+		return to == String || to == Bytes
+	}
+	return false
+}
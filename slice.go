@@ -56,6 +56,7 @@ func (v VALUE) SLICE() SLICE {
 	}
 }
 
+// NewSlice returns a new SLICE of size zero values of TYPE r
 func NewSlice(r *TYPE, size int) SLICE {
 	return NewArray(r, size).SLICE()
 }
@@ -89,6 +90,17 @@ func (s SLICE) index(i int) VALUE {
 	}.SetType()
 }
 
+// elem returns the addressable value at index i of SLICE,
+// elems of interface kind are not converted to their type
+func (s SLICE) elem(i int) VALUE {
+	t := (*sliceType)(unsafe.Pointer(s.typ))
+	return VALUE{
+		t.elem,
+		unsafe.Pointer(uintptr((*sliceHeader)(s.ptr).Data) + uintptr(i)*t.elem.size),
+		flagAddr | flagIndir | flag(t.elem.Kind()),
+	}
+}
+
 // ForEach executes function f on each item in SLICE,
 // note: k equals "" at each item
 func (s SLICE) ForEach(f func(i int, k string, v VALUE) (brake bool)) {
@@ -169,10 +181,15 @@ func (s SLICE) Pointer() unsafe.Pointer {
 
 // Encode returns a gotype encoding of SLICE
 func (s SLICE) Encode() ENCODING {
-	l := s.Len()
-	e := append([]byte{s.typ.Kind().Byte(), (*sliceType)(unsafe.Pointer(s.typ)).elem.Kind().Byte()}, lenBytes(l)...)
+	return s.encode(&encoder{})
+}
+
+// encode returns the encoding of SLICE with the pointers already encoded in enc
+func (s SLICE) encode(enc *encoder) ENCODING {
+	l, t := s.Len(), (*sliceType)(unsafe.Pointer(s.typ)).elem
+	e := append([]byte{s.typ.Kind().Byte(), t.Kind().Byte()}, lenBytes(l)...)
 	for i := 0; i < l; i++ {
-		e = append(e, s.Index(i).Encode()...)
+		e = append(e, s.index(i).encodeElem(t, enc)...)
 	}
 	return e
 }
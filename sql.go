@@ -0,0 +1,558 @@
+// Copyright 2023 james dotter. All rights reserved.
+// Use of this source code is governed by a
+// license that can be found in the gotype LICENSE file.
+
+package gotype
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unsafe"
+
+	"github.com/google/uuid"
+)
+
+// ------------------------------------------------------------ /
+// DATABASE/SQL IMPLEMENTATION
+// implementation of sql.Scanner and driver.Valuer for gotype
+// custom types and scanning of sql rows into slices of structs
+// ------------------------------------------------------------ /
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// Scan implements sql.Scanner, setting TIME from a time.Time,
+// a formatted date or time string or bytes, or an int64 of unix nanoseconds.
+// A NULL value sets the zero TIME
+func (t *TIME) Scan(src any) error {
+	switch s := src.(type) {
+	case nil:
+		*t = TIME{}
+	case time.Time:
+		*t = TIME(s)
+	case int64:
+		*t = INT(s).TIME()
+	case string:
+		return t.scanString(s)
+	case []byte:
+		return t.scanString(string(s))
+	default:
+		return fmt.Errorf("cannot scan %T into TIME", src)
+	}
+	return nil
+}
+
+func (t *TIME) scanString(s string) error {
+	if tt, can, _ := STRING(s).CanTime(); can {
+		*t = tt
+		return nil
+	}
+	tt, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return fmt.Errorf("cannot scan '%s' into TIME", s)
+	}
+	*t = TIME(tt)
+	return nil
+}
+
+// Value implements driver.Valuer, returning TIME as a time.Time
+func (t TIME) Value() (driver.Value, error) {
+	return time.Time(t), nil
+}
+
+// Scan implements sql.Scanner, setting UUID from a formatted
+// string or bytes, or from 16 raw bytes. A NULL value sets the zero UUID
+func (u *UUID) Scan(src any) error {
+	var s string
+	switch v := src.(type) {
+	case nil:
+		*u = UUID{}
+		return nil
+	case string:
+		s = v
+	case []byte:
+		if len(v) == 16 {
+			copy(u[:], v)
+			return nil
+		}
+		s = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into UUID", src)
+	}
+	if s == "" {
+		*u = UUID{}
+		return nil
+	}
+	p, err := uuid.Parse(s)
+	if err != nil {
+		return fmt.Errorf("cannot scan '%s' into UUID: %w", s, err)
+	}
+	*u = UUID(p)
+	return nil
+}
+
+// Value implements driver.Valuer, returning UUID as a formatted string
+func (u UUID) Value() (driver.Value, error) {
+	return uuid.UUID(u).String(), nil
+}
+
+// Scan implements sql.Scanner, setting BYTES to a copy
+// of the bytes or string provided. A NULL value sets nil BYTES
+func (b *BYTES) Scan(src any) error {
+	switch s := src.(type) {
+	case nil:
+		*b = nil
+	case []byte:
+		*b = append(BYTES(nil), s...)
+	case string:
+		*b = BYTES(s)
+	default:
+		return fmt.Errorf("cannot scan %T into BYTES", src)
+	}
+	return nil
+}
+
+// Value implements driver.Valuer, returning BYTES as []byte
+func (b BYTES) Value() (driver.Value, error) {
+	if b == nil {
+		return nil, nil
+	}
+	return []byte(b), nil
+}
+
+// Value implements driver.Valuer, returning JSON as a string,
+// or NULL if the JSON is empty. JSON does not implement sql.Scanner
+// as its Scan method scans the JSON into a struct, JSON columns are
+// scanned into JSON fields by ScanRows or can be scanned into NullJSON
+func (j JSON) Value() (driver.Value, error) {
+	if len(j) == 0 {
+		return nil, nil
+	}
+	return string(j), nil
+}
+
+// NullJSON is a JSON column that may be NULL,
+// implementing sql.Scanner and driver.Valuer.
+// It wraps JSON rather than adding a Scan method to JSON because
+// JSON.Scan(dest any, tags ...string) already scans JSON into a struct,
+// and its signature cannot also satisfy sql.Scanner's Scan(src any) error
+type NullJSON struct {
+	JSON  JSON
+	Valid bool // Valid is true if JSON is not NULL
+}
+
+// Scan implements sql.Scanner, setting NullJSON to a copy of the
+// bytes or string provided. A NULL value sets an invalid NullJSON
+func (n *NullJSON) Scan(src any) error {
+	switch s := src.(type) {
+	case nil:
+		*n = NullJSON{}
+	case []byte:
+		*n = NullJSON{append(JSON(nil), s...), true}
+	case string:
+		*n = NullJSON{JSON(s), true}
+	default:
+		return fmt.Errorf("cannot scan %T into NullJSON", src)
+	}
+	return nil
+}
+
+// Value implements driver.Valuer, returning NullJSON as a string,
+// or NULL if the NullJSON is not valid
+func (n NullJSON) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return string(n.JSON), nil
+}
+
+// ScanRows scans each row of rows into a new struct appended to the slice
+// of structs or of pointers to structs pointed to by dest. Columns are
+// matched to fields by the db tag of the field, or else by the field name
+// ignoring case and underscores. Columns without a matching field are
+// discarded. Fields implementing sql.Scanner scan the column directly,
+// other fields are set from the column converted to the field's kind,
+// and a NULL column sets the field to its zero value. Rows is not closed
+func ScanRows(rows *sql.Rows, dest any) (err error) {
+	d := ValueOfV(dest)
+	if d.Kind() != Pointer || d.Pointer() == nil || d.typ.Elem().Kind() != Slice {
+		return errors.New("sql: dest must be a non nil pointer to a slice of structs")
+	}
+	s := (SLICE)(d.Elem())
+	et := s.typ.Elem()
+	st := et
+	if st.Kind() == Pointer {
+		st = st.Elem()
+	}
+	if st.KIND() != Struct {
+		return errors.New("sql: dest must be a non nil pointer to a slice of structs")
+	}
+	cols, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	fields := scanFields(st, cols)
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("sql: %v", r)
+		}
+	}()
+	targets, srcs := make([]any, len(cols)), make([]any, len(cols))
+	for rows.Next() {
+		row := st.New()
+		for i, f := range fields {
+			switch {
+			case f == nil:
+				targets[i] = &srcs[i]
+			case f.scanner:
+				targets[i] = reflect.NewAt(f.typ.Reflect(), offset(row.ptr, f.offset)).Interface()
+			default:
+				srcs[i], targets[i] = nil, &srcs[i]
+			}
+		}
+		if err = rows.Scan(targets...); err != nil {
+			return err
+		}
+		for i, f := range fields {
+			if f != nil && !f.scanner {
+				if err = scanSet(VALUE{f.typ, offset(row.ptr, f.offset), flagAddr | flagIndir | flag(f.typ.Kind())}, srcs[i]); err != nil {
+					return fmt.Errorf("sql: column '%s': %w", cols[i], err)
+				}
+			}
+		}
+		l := s.Len()
+		s = s.Extend(1)
+		slot := offset((*sliceHeader)(s.ptr).Data, uintptr(l)*et.size)
+		if et.Kind() == Pointer {
+			*(*unsafe.Pointer)(slot) = row.ptr
+		} else {
+			typedmemmove(st, slot, row.ptr)
+		}
+	}
+	return rows.Err()
+}
+
+// scanField is the struct field a column is scanned into
+type scanField struct {
+	typ     *TYPE
+	offset  uintptr
+	scanner bool // pointer to the field implements sql.Scanner
+}
+
+// scanFields returns the field of struct TYPE t
+// matching each column, nil if no field matches
+func scanFields(t *TYPE, cols []string) []*scanField {
+	v := t.New().Elem()
+	s := (STRUCT)(v)
+	// TagIndex indexes by field name unless every field is tagged
+	index, names := map[string]FIELD{}, map[string]FIELD{}
+	for k, f := range s.TagIndex("db") {
+		k, _, _ = strings.Cut(k, ",")
+		index[k] = f
+	}
+	s.ForFields(true, func(i int, f FIELD) (brake bool) {
+		if !f.Visible() {
+			return
+		}
+		if tag, _, _ := strings.Cut(f.Tag("db"), ","); tag != "" && tag != "-" {
+			if _, ok := index[tag]; !ok {
+				index[tag] = f
+			}
+		}
+		names[scanName(f.name)] = f
+		return
+	})
+	fields := make([]*scanField, len(cols))
+	for i, c := range cols {
+		f, ok := index[c]
+		if !ok {
+			f, ok = names[scanName(c)]
+		}
+		if !ok || f.Tag("db") == "-" {
+			continue
+		}
+		fields[i] = &scanField{
+			typ:     f.typ,
+			offset:  uintptr(f.ptr) - uintptr(v.ptr),
+			scanner: reflect.PointerTo(f.typ.Reflect()).Implements(scannerType),
+		}
+	}
+	return fields
+}
+
+// scanName normalizes a column or field name for matching
+func scanName(n string) string {
+	return strings.ToLower(strings.ReplaceAll(n, "_", ""))
+}
+
+// scanSet sets the addressable VALUE v to the column value src,
+// converted to the kind of v. A nil src sets v to its zero value
+func scanSet(v VALUE, src any) error {
+	if src == nil {
+		typedmemmove(v.typ, v.ptr, v.typ.New().Elem().ptr)
+		return nil
+	}
+	if v.Kind() == Pointer {
+		e := v.typ.Elem().New()
+		if err := scanSet(e.Elem(), src); err != nil {
+			return err
+		}
+		*(*unsafe.Pointer)(v.ptr) = e.ptr
+		return nil
+	}
+	if v.KIND() == Time {
+		return (*TIME)(v.ptr).Scan(src)
+	}
+	v.Set(src)
+	return nil
+}
+
+// ------------------------------------------------------------ /
+// SQL STATEMENT BUILDER
+// generation of parameterized statements and column lists
+// from structs with db tags, eg. `db:"id,pk"`
+// ------------------------------------------------------------ /
+
+// SQLPlaceholder is the style of the parameter placeholders of a SQLDialect
+type SQLPlaceholder uint8
+
+const (
+	PlaceholderQuestion SQLPlaceholder = iota // ?
+	PlaceholderDollar                         // $1, $2, ...
+	PlaceholderNamed                          // :name, args are sql.NamedArg
+)
+
+// SQLUpsert is the upsert syntax of a SQLDialect
+type SQLUpsert uint8
+
+const (
+	UpsertOnConflict     SQLUpsert = iota // ON CONFLICT (keys) DO UPDATE SET col = EXCLUDED.col
+	UpsertOnDuplicateKey                  // ON DUPLICATE KEY UPDATE col = VALUES(col)
+)
+
+// SQLDialect generates parameterized statements from structs
+// using the placeholders, identifier quotes and upsert syntax
+// of a database. Columns are the fields of the struct named by
+// their db tag or else the snake case field name, with options:
+//
+//	db:"name,pk"    the column is a primary key, used in the WHERE
+//	                clause of updates and the conflict target of upserts
+//	db:"name,auto"  the column is generated by the database
+//	                and omitted from inserts when zero
+//	db:"-"          the field is not a column
+type SQLDialect struct {
+	Placeholder  SQLPlaceholder
+	QuoteOpen    string // opening quote of identifiers, eg. `"`
+	QuoteClose   string // closing quote of identifiers, eg. `"`
+	UpsertSyntax SQLUpsert
+}
+
+var (
+	SQLPostgres = SQLDialect{PlaceholderDollar, `"`, `"`, UpsertOnConflict}
+	SQLMySQL    = SQLDialect{PlaceholderQuestion, "`", "`", UpsertOnDuplicateKey}
+	SQLSQLite   = SQLDialect{PlaceholderQuestion, `"`, `"`, UpsertOnConflict}
+)
+
+// sqlColumn is a column of a struct
+type sqlColumn struct {
+	name  string
+	key   bool
+	auto  bool
+	value VALUE
+}
+
+// sqlColumns returns the columns of the struct or pointer to struct v
+func sqlColumns(v any) (t *TYPE, cols []sqlColumn) {
+	s := ValueOfV(v).STRUCT()
+	for i, n := range s.FieldNames() {
+		tag := s.typ.Field(i).TagValue("db")
+		if c := n[0]; c < 'A' || c > 'Z' || tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = STRING(n).ToSnake()
+		}
+		c := sqlColumn{name: name, value: s.field(i)}
+		for _, o := range strings.Split(opts, ",") {
+			switch o {
+			case "pk":
+				c.key = true
+			case "auto":
+				c.auto = true
+			}
+		}
+		cols = append(cols, c)
+	}
+	return s.typ, cols
+}
+
+// Quote returns the identifier quoted, quoting each
+// part of a dotted identifier, eg. "schema"."table"
+func (d SQLDialect) Quote(ident string) string {
+	if d.QuoteOpen == "" {
+		return ident
+	}
+	parts := strings.Split(ident, ".")
+	for i, p := range parts {
+		if d.QuoteClose != "" {
+			p = strings.ReplaceAll(p, d.QuoteClose, d.QuoteClose+d.QuoteClose)
+		}
+		parts[i] = d.QuoteOpen + p + d.QuoteClose
+	}
+	return strings.Join(parts, ".")
+}
+
+// Columns returns the quoted, comma separated columns of
+// the struct or pointer to struct v, eg. for a SELECT statement
+func (d SQLDialect) Columns(v any) string {
+	_, cols := sqlColumns(v)
+	names := make([]string, len(cols))
+	for i, c := range cols {
+		names[i] = d.Quote(c.name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// Select returns a SELECT statement of the columns
+// of the struct or pointer to struct v from table
+func (d SQLDialect) Select(table string, v any) string {
+	return "SELECT " + d.Columns(v) + " FROM " + d.Quote(table)
+}
+
+// Insert returns a parameterized INSERT statement into table of the
+// columns of the struct or pointer to struct v and the args of the statement
+func (d SQLDialect) Insert(table string, v any) (query string, args []any, err error) {
+	_, cols := sqlColumns(v)
+	sb := d.builder()
+	names, params := sb.insert(cols)
+	if len(names) == 0 {
+		return "", nil, errors.New("sql: no columns to insert")
+	}
+	return "INSERT INTO " + d.Quote(table) + " (" + strings.Join(names, ", ") + ") VALUES (" + strings.Join(params, ", ") + ")", sb.args, nil
+}
+
+// Update returns a parameterized UPDATE statement of table setting the
+// columns of the struct or pointer to struct v where its pk columns are
+// equal, and the args of the statement. If prev is provided only the columns
+// changed from prev are set, otherwise only the non zero columns are set
+func (d SQLDialect) Update(table string, v any, prev ...any) (query string, args []any, err error) {
+	t, cols := sqlColumns(v)
+	var old []sqlColumn
+	if len(prev) > 0 {
+		var pt *TYPE
+		if pt, old = sqlColumns(prev[0]); pt != t {
+			return "", nil, errors.New("sql: cannot compare " + t.String() + " with " + pt.String())
+		}
+	}
+	sb := d.builder()
+	var set, where []string
+	for i, c := range cols {
+		switch {
+		case c.key:
+			continue
+		case old != nil:
+			if reflect.DeepEqual(c.value.Interface(), old[i].value.Interface()) {
+				continue
+			}
+		case c.value.IsZero():
+			continue
+		}
+		set = append(set, d.Quote(c.name)+" = "+sb.param(c))
+	}
+	if len(set) == 0 {
+		return "", nil, errors.New("sql: no columns to update")
+	}
+	for _, c := range cols {
+		if c.key {
+			where = append(where, d.Quote(c.name)+" = "+sb.param(c))
+		}
+	}
+	if len(where) == 0 {
+		return "", nil, errors.New("sql: no pk columns to update by")
+	}
+	return "UPDATE " + d.Quote(table) + " SET " + strings.Join(set, ", ") + " WHERE " + strings.Join(where, " AND "), sb.args, nil
+}
+
+// Upsert returns a parameterized statement inserting the columns of the
+// struct or pointer to struct v into table, or updating the non pk and non
+// auto columns if a row with the same pk columns exists, and the args of
+// the statement
+func (d SQLDialect) Upsert(table string, v any) (query string, args []any, err error) {
+	_, cols := sqlColumns(v)
+	sb := d.builder()
+	names, params := sb.insert(cols)
+	var keys, set []string
+	for _, c := range cols {
+		q := d.Quote(c.name)
+		switch {
+		case c.key:
+			keys = append(keys, q)
+		case c.auto:
+			continue
+		case d.UpsertSyntax == UpsertOnDuplicateKey:
+			set = append(set, q+" = VALUES("+q+")")
+		default:
+			set = append(set, q+" = EXCLUDED."+q)
+		}
+	}
+	if len(keys) == 0 {
+		return "", nil, errors.New("sql: no pk columns to upsert by")
+	}
+	query = "INSERT INTO " + d.Quote(table) + " (" + strings.Join(names, ", ") + ") VALUES (" + strings.Join(params, ", ") + ")"
+	switch {
+	case d.UpsertSyntax == UpsertOnDuplicateKey:
+		if len(set) == 0 { // no op update of a key
+			set = append(set, keys[0]+" = "+keys[0])
+		}
+		query += " ON DUPLICATE KEY UPDATE " + strings.Join(set, ", ")
+	case len(set) == 0:
+		query += " ON CONFLICT (" + strings.Join(keys, ", ") + ") DO NOTHING"
+	default:
+		query += " ON CONFLICT (" + strings.Join(keys, ", ") + ") DO UPDATE SET " + strings.Join(set, ", ")
+	}
+	return query, sb.args, nil
+}
+
+func (d SQLDialect) builder() *sqlBuilder {
+	return &sqlBuilder{SQLDialect: d}
+}
+
+// sqlBuilder collects the args of a statement
+type sqlBuilder struct {
+	SQLDialect
+	args []any
+}
+
+// param adds the value of column c to the args
+// and returns its placeholder
+func (b *sqlBuilder) param(c sqlColumn) string {
+	switch b.Placeholder {
+	case PlaceholderDollar:
+		b.args = append(b.args, c.value.Interface())
+		return "$" + strconv.Itoa(len(b.args))
+	case PlaceholderNamed:
+		b.args = append(b.args, sql.Named(c.name, c.value.Interface()))
+		return ":" + c.name
+	}
+	b.args = append(b.args, c.value.Interface())
+	return "?"
+}
+
+// insert returns the quoted names and placeholders of the columns
+// inserted, omitting zero auto columns
+func (b *sqlBuilder) insert(cols []sqlColumn) (names, params []string) {
+	for _, c := range cols {
+		if c.auto && c.value.IsZero() {
+			continue
+		}
+		names = append(names, b.Quote(c.name))
+		params = append(params, b.param(c))
+	}
+	return
+}
@@ -0,0 +1,160 @@
+// Copyright 2023 james dotter. All rights reserved.
+// Use of this source code is governed by a
+// license that can be found in the gotype LICENSE file.
+
+package gotype
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+)
+
+// ------------------------------------------------------------ /
+// GOTYPE ENCODING STREAMS
+// length prefixed records of encodings written to and read
+// from io streams one at a time, for append only logs and
+// record files that are not loaded into memory
+// ------------------------------------------------------------ /
+
+// RECORD FORMAT
+// []byte{Flags, Len..., Encoding...[, CRC...]}
+//
+// Flags:		0x0 or recordCRC if the record ends with a CRC
+// Len:			uvarint length of the Encoding
+// CRC:			4 byte big endian CRC-32C (Castagnoli) of the Encoding
+
+const recordCRC byte = 0x1
+
+var (
+	ErrChecksum = errors.New("record checksum mismatch")
+	crcTable    = crc32.MakeTable(crc32.Castagnoli)
+)
+
+// EncodingWriter writes encodings as records to an io.Writer
+type EncodingWriter struct {
+	w   io.Writer
+	crc bool
+	buf []byte
+}
+
+// NewEncodingWriter returns an EncodingWriter of w, which
+// writes a CRC with each record if crc is true
+func NewEncodingWriter(w io.Writer, crc bool) *EncodingWriter {
+	return &EncodingWriter{w: w, crc: crc}
+}
+
+// Write writes encoding e as a record with a single call to the
+// underlying io.Writer and returns any error of the io.Writer
+func (w *EncodingWriter) Write(e ENCODING) error {
+	b := append(w.buf[:0], 0)
+	if w.crc {
+		b[0] = recordCRC
+	}
+	b = binary.AppendUvarint(b, uint64(len(e)))
+	b = append(b, e...)
+	if w.crc {
+		b = binary.BigEndian.AppendUint32(b, crc32.Checksum(e, crcTable))
+	}
+	w.buf = b
+	_, err := w.w.Write(b)
+	return err
+}
+
+// Encode encodes a and writes it as a record
+func (w *EncodingWriter) Encode(a any) error {
+	return w.Write(Encode(a))
+}
+
+// EncodingReader reads records of encodings from an io.Reader
+type EncodingReader struct {
+	r   *bufio.Reader
+	off int // offset of the next record in the stream
+	buf []byte
+}
+
+// NewEncodingReader returns an EncodingReader of r
+func NewEncodingReader(r io.Reader) *EncodingReader {
+	return &EncodingReader{r: bufio.NewReader(r)}
+}
+
+// Next reads the next record and returns its encoding, which is only valid
+// until the following call to Next. Returns io.EOF at the end of the stream,
+// a DecodeError if the record is truncated, corrupt, longer than
+// MaxDecodeLen or its CRC does not match, or any error of the io.Reader
+func (r *EncodingReader) Next() (ENCODING, error) {
+	flags, err := r.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if flags&^recordCRC != 0 {
+		return nil, &DecodeError{r.off, ErrCorrupt}
+	}
+	l, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return nil, r.readError(err)
+	}
+	if l > uint64(MaxDecodeLen) {
+		return nil, &DecodeError{r.off, ErrMaxLen}
+	}
+	n := int(l)
+	if flags == recordCRC {
+		n += 4
+	}
+	if cap(r.buf) < n {
+		r.buf = make([]byte, n)
+	}
+	b := r.buf[:n]
+	if _, err = io.ReadFull(r.r, b); err != nil {
+		return nil, r.readError(err)
+	}
+	e := ENCODING(b[:l])
+	if flags == recordCRC && crc32.Checksum(e, crcTable) != binary.BigEndian.Uint32(b[l:]) {
+		return nil, &DecodeError{r.off, ErrChecksum}
+	}
+	r.off += 2 + n
+	for ; l >= 0x80; l >>= 7 {
+		r.off++ // uvarint bytes of Len
+	}
+	return e, nil
+}
+
+// readError returns err of reading a record that has begun as a DecodeError
+// if the stream ended before the end of the record
+func (r *EncodingReader) readError(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return &DecodeError{r.off, ErrTruncated}
+	}
+	return err
+}
+
+// Decode reads the next record and decodes it into pointer dest,
+// returns io.EOF at the end of the stream, see Next and DecodeE
+func (r *EncodingReader) Decode(dest any) error {
+	e, err := r.Next()
+	if err != nil {
+		return err
+	}
+	_, err = e.DecodeE(dest)
+	return err
+}
+
+// ForEach reads each record of the stream and calls f with its encoding,
+// which is only valid until f returns, until f returns true or the end
+// of the stream. Returns nil at the end of the stream or the error of Next
+func (r *EncodingReader) ForEach(f func(i int, e ENCODING) (brake bool)) error {
+	for i := 0; ; i++ {
+		e, err := r.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if f(i, e) {
+			return nil
+		}
+	}
+}
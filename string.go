@@ -68,15 +68,15 @@ func (v VALUE) string() string {
 	case Uint64:
 		return strconv.FormatUint(*(*uint64)(v.ptr), 10)
 	case Uintptr:
-		return strconv.FormatUint(*(*uint64)(v.ptr), 10)
+		return strconv.FormatUint(uint64(*(*uintptr)(v.ptr)), 10)
 	case Float32:
 		return strconv.FormatFloat(float64(*(*float32)(v.ptr)), 'f', -1, 64)
 	case Float64:
 		return strconv.FormatFloat(*(*float64)(v.ptr), 'f', -1, 64)
 	case Complex64:
-		return strconv.FormatComplex(complex128(*(*complex64)(v.ptr)), 'f', -1, 128)
+		return formatComplex(complex128(*(*complex64)(v.ptr)), 64)
 	case Complex128:
-		return strconv.FormatComplex(*(*complex128)(v.ptr), 'f', -1, 128)
+		return COMPLEX(*(*complex128)(v.ptr)).String()
 	case Array:
 		return (ARRAY)(v).String()
 	case Chan:
@@ -234,6 +234,21 @@ func (s STRING) FLOAT() FLOAT {
 	return FLOAT(s.Float64())
 }
 
+// Complex128 returns gotype STRING as complex128,
+// parsing strings of the form "1+2i" or "(1+2i)"
+func (s STRING) Complex128() complex128 {
+	c, e := strconv.ParseComplex(strings.TrimSpace(string(s)), 128)
+	if e != nil {
+		panic("cannot convert string to complex128")
+	}
+	return c
+}
+
+// COMPLEX returns gotype STRING as a gotype COMPLEX
+func (s STRING) COMPLEX() COMPLEX {
+	return COMPLEX(s.Complex128())
+}
+
 // String returns gotype STRING as golang string
 func (s STRING) String() string {
 	return string(s)
@@ -78,6 +78,17 @@ func (s STRUCT) index(i int) VALUE {
 	}.SetType()
 }
 
+// field returns the addressable value of field i of STRUCT,
+// fields of interface kind are not converted to their type
+func (s STRUCT) field(i int) VALUE {
+	f := (*structType)(unsafe.Pointer(s.typ)).fields[i]
+	return VALUE{
+		f.typ,
+		unsafe.Pointer(uintptr(s.ptr) + f.offset),
+		s.flag&(flagStickyRO|flagIndir|flagAddr) | flag(f.typ.Kind()),
+	}
+}
+
 // Index returns FIELD with name n of STRUCT
 func (s STRUCT) Field(n string) (r FIELD) {
 	s.ForFields(false, func(i int, f FIELD) (brake bool) {
@@ -130,16 +141,16 @@ func (s STRUCT) ForEach(f func(i int, f string, v VALUE) (brake bool)) {
 // ForFields executes function f on each field in STRUCT;
 // note: f is the struct field; tag and name are populated when inclDetail is true
 func (s STRUCT) ForFields(inclDetail bool, f func(i int, f FIELD) (brake bool)) {
-	for i, fld := range (*structType)(unsafe.Pointer(s.typ)).fields {
+	for i, fld := range s.typ.fieldMetas() {
 		fo := FIELD{
 			typ:   fld.typ,
 			ptr:   unsafe.Pointer(uintptr(s.ptr) + fld.offset),
 			f:     s.flag&(flagStickyRO|flagIndir|flagAddr) | flag(fld.typ.Kind()),
-			name_: fld.name,
+			name_: fld.name_,
 			index: i,
 		}
 		if inclDetail {
-			fo.name, fo.rawtag = fo.name_.name(), fo.name_.tag()
+			fo.name, fo.rawtag = fld.name, fld.rawtag
 		}
 		if f(i, fo) {
 			break
@@ -192,10 +203,15 @@ func (s STRUCT) Pointer() unsafe.Pointer {
 
 // Bytes returns gotype STRUCT as []byte
 func (s STRUCT) Encode() ENCODING {
-	l := s.Len()
-	e := append([]byte{byte(Struct)}, lenBytes(l)...)
-	for i := 0; i < l; i++ {
-		e = append(e, s.Index(i).Encode()...)
+	return s.encode(&encoder{})
+}
+
+// encode returns the encoding of STRUCT with the pointers already encoded in enc
+func (s STRUCT) encode(enc *encoder) ENCODING {
+	fs := (*structType)(unsafe.Pointer(s.typ)).fields
+	e := append([]byte{byte(Struct)}, lenBytes(len(fs))...)
+	for i, f := range fs {
+		e = append(e, s.index(i).encodeElem(f.typ, enc)...)
 	}
 	return e
 }
@@ -235,11 +251,10 @@ func (s STRUCT) MAP() MAP {
 
 // String returns gotype STRUCT as a serialized json string
 func (s STRUCT) String() string {
-	if m, ok := s.ReflectType().MethodByName("String"); ok {
-		return s.ReflectValue().Method(m.Index).Call([]reflect.Value{})[0].String()
-	} else {
-		return (VALUE)(s).Marshal(JsonMarshaler).String()
+	if m := s.typ.method("String"); m.index != -1 {
+		return (VALUE)(s).callString(m.index)
 	}
+	return (VALUE)(s).Marshal(JsonMarshaler).String()
 }
 
 // Name returns the name of the struct type as a string
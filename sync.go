@@ -0,0 +1,212 @@
+// Copyright 2023 james dotter. All rights reserved.
+// Use of this source code is governed by a
+// license that can be found in the gotype LICENSE file.
+
+package gotype
+
+import (
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// ------------------------------------------------------------ /
+// SYNC VALUE IMPLEMENTATION
+// concurrency safe reads and mutations of values shared
+// across goroutines, and atomic compare and swap of scalars
+// ------------------------------------------------------------ /
+
+// SyncValue is an addressable VALUE guarded by a read write mutex,
+// enabling a value shared across goroutines to be read and mutated
+// atomically. Operations are only safe if all access to the value
+// is made through the SyncValue
+type SyncValue struct {
+	mu sync.RWMutex
+	v  VALUE
+}
+
+// SyncValueOf returns a SyncValue of the value pointed to by a,
+// panics if a is not a non nil pointer or an addressable VALUE
+func SyncValueOf(a any) *SyncValue {
+	v := ValueOfV(a)
+	if v.typ == nil {
+		panic("cannot sync nil value")
+	}
+	if v.flag&flagAddr == 0 {
+		if v.Kind() != Pointer || v.Pointer() == nil {
+			panic("cannot sync value that is not a non nil pointer or addressable value")
+		}
+		v = v.Elem()
+	}
+	return &SyncValue{v: v}
+}
+
+// Read calls f with the VALUE of the SyncValue under a read lock,
+// the VALUE must not be mutated or retained after f returns
+func (s *SyncValue) Read(f func(v VALUE)) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	f(s.v)
+}
+
+// Update calls f with the VALUE of the SyncValue under a write lock,
+// the VALUE must not be retained after f returns
+func (s *SyncValue) Update(f func(v VALUE)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f(s.v)
+}
+
+// Interface returns a copy of the value of the SyncValue as an interface
+func (s *SyncValue) Interface() any {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.v.Interface()
+}
+
+// Index returns a copy of the value at key or index of
+// the slice, array, map or struct held by the SyncValue
+func (s *SyncValue) Index(key any) any {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v := s.v.SetType()
+	for v.Kind() == Pointer {
+		v = v.Elem().SetType()
+	}
+	var e VALUE
+	switch v.Kind() {
+	case Map:
+		if e = (MAP)(v).Index(key); e.ptr == nil {
+			return nil
+		}
+	case Struct:
+		if k, ok := key.(string); ok {
+			e = (STRUCT)(v).Field(k).VALUE()
+			break
+		}
+		e = (STRUCT)(v).Index(ValueOfV(key).Int())
+	default:
+		e = v.Index(ValueOfV(key).Int())
+	}
+	return valueInterface(e)
+}
+
+// Len returns the length of the value of the SyncValue
+func (s *SyncValue) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.v.Len()
+}
+
+// Set sets the value of the SyncValue to a, see VALUE.Set
+func (s *SyncValue) Set(a any) *SyncValue {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.v.Set(a)
+	return s
+}
+
+// SetWith sets the value of the SyncValue to a, converting numbers
+// using ConversionPolicy p, see VALUE.SetWith
+func (s *SyncValue) SetWith(a any, p ConversionPolicy) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.v.SetWith(a, p)
+	return err
+}
+
+// SetIndex sets the value at key or index of the slice, array, map,
+// struct or string held by the SyncValue, see VALUE.SetIndex.
+// Nil maps are initialized and slices are extended as needed
+func (s *SyncValue) SetIndex(key any, val any) *SyncValue {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.init().SetIndex(key, val)
+	return s
+}
+
+// Append appends the values a to the slice held by the SyncValue
+func (s *SyncValue) Append(a ...any) *SyncValue {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v := s.init()
+	if v.Kind() != Slice {
+		panic("can only append to slice value")
+	}
+	(SLICE)(v).Append(a...)
+	return s
+}
+
+// Delete removes key from the map held by the SyncValue
+func (s *SyncValue) Delete(key any) *SyncValue {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v := s.init()
+	if v.Kind() != Map {
+		panic("can only delete from map value")
+	}
+	(MAP)(v).Delete(key)
+	return s
+}
+
+// Swap sets the value of the SyncValue to new and returns a copy of the old value
+func (s *SyncValue) Swap(new any) (old any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old = s.v.Interface()
+	s.v.Set(new)
+	return
+}
+
+// CompareAndSwap sets the value of the SyncValue to new if it is equal
+// to old, converted to the type of the value, and returns whether the
+// value was swapped. Panics if the value is not of a comparable scalar kind
+func (s *SyncValue) CompareAndSwap(old, new any) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.v.typ.KIND().IsBasic() && s.v.Kind() != Pointer {
+		panic("cannot compare and swap value of kind " + s.v.KIND().String())
+	}
+	o := s.v.typ.New().Elem()
+	o.Set(old)
+	if s.v.Interface() != o.Interface() {
+		return false
+	}
+	s.v.Set(new)
+	return true
+}
+
+// init returns the VALUE of the SyncValue with the
+// underlying value of pointers and nil maps initialized
+func (s *SyncValue) init() VALUE {
+	v := s.v
+	for v.Kind() == Pointer {
+		v = v.Init().Elem()
+	}
+	if v.Kind() == Map {
+		v = v.Init()
+	}
+	return v
+}
+
+// CompareAndSwap atomically sets the addressable scalar VALUE to new
+// if it is equal to old, converted to the type of VALUE, and returns
+// whether the value was swapped. The VALUE must be of 32 or 64 bit
+// numeric kind, uintptr or pointer. Floats are compared by their bits
+func (v VALUE) CompareAndSwap(old, new any) bool {
+	if v.flag&flagAddr == 0 {
+		panic("cannot compare and swap value that is not addressable")
+	}
+	o, n := v.typ.New().Elem(), v.typ.New().Elem()
+	o.Set(old)
+	n.Set(new)
+	switch k := v.Kind(); {
+	case k == Pointer || k == UnsafePointer:
+		return atomic.CompareAndSwapPointer((*unsafe.Pointer)(v.ptr), *(*unsafe.Pointer)(o.ptr), *(*unsafe.Pointer)(n.ptr))
+	case (k.IsNumeric() || k == Uintptr) && v.typ.size == 4:
+		return atomic.CompareAndSwapUint32((*uint32)(v.ptr), *(*uint32)(o.ptr), *(*uint32)(n.ptr))
+	case (k.IsNumeric() || k == Uintptr) && v.typ.size == 8:
+		return atomic.CompareAndSwapUint64((*uint64)(v.ptr), *(*uint64)(o.ptr), *(*uint64)(n.ptr))
+	}
+	panic("cannot compare and swap value of kind " + v.KIND().String())
+}
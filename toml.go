@@ -0,0 +1,419 @@
+// Copyright 2023 james dotter. All rights reserved.
+// Use of this source code is governed by a
+// license that can be found in the gotype LICENSE file.
+
+package gotype
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ------------------------------------------------------------ /
+// TOML IMPLEMENTATION
+// parsing of toml documents into map[string]any, toml tables
+// cannot be expressed by the Marshaler syntax so are parsed here
+// ------------------------------------------------------------ /
+
+// parseToml parses the toml document b into a map[string]any,
+// supporting tables, arrays of tables, dotted keys, basic and literal
+// strings, integers, floats, booleans, arrays and inline tables.
+// Dates and times are returned as strings
+func parseToml(b []byte) (root map[string]any, err error) {
+	p := &tomlParser{b: b, line: 1}
+	root = map[string]any{}
+	cur := root
+	for {
+		p.skipSpace(true)
+		if p.i >= len(p.b) {
+			return
+		}
+		switch p.b[p.i] {
+		case '[':
+			p.i++
+			array := p.is('[')
+			if array {
+				p.i++
+			}
+			var keys []string
+			if keys, err = p.key(); err != nil {
+				return nil, err
+			}
+			if !p.is(']') || (array && (p.i+1 >= len(p.b) || p.b[p.i+1] != ']')) {
+				return nil, p.error("expected end of table header")
+			}
+			p.i++
+			if array {
+				p.i++
+			}
+			if cur, err = p.table(root, keys, array); err != nil {
+				return nil, err
+			}
+		default:
+			if err = p.keyValue(cur); err != nil {
+				return nil, err
+			}
+		}
+		if err = p.lineEnd(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+type tomlParser struct {
+	b    []byte
+	i    int
+	line int
+}
+
+func (p *tomlParser) error(msg string) error {
+	return errors.New("toml: line " + strconv.Itoa(p.line) + ": " + msg)
+}
+
+func (p *tomlParser) is(c byte) bool {
+	return p.i < len(p.b) && p.b[p.i] == c
+}
+
+func (p *tomlParser) has(s string) bool {
+	return strings.HasPrefix(string(p.b[p.i:]), s)
+}
+
+// skipSpace skips spaces and tabs, and when lines is true
+// also line breaks and comments
+func (p *tomlParser) skipSpace(lines bool) {
+	for p.i < len(p.b) {
+		switch p.b[p.i] {
+		case ' ', '\t', '\r':
+		case '\n':
+			if !lines {
+				return
+			}
+			p.line++
+		case '#':
+			if !lines {
+				return
+			}
+			for p.i < len(p.b) && p.b[p.i] != '\n' {
+				p.i++
+			}
+			continue
+		default:
+			return
+		}
+		p.i++
+	}
+}
+
+// lineEnd consumes the remainder of a line following a value or table header
+func (p *tomlParser) lineEnd() error {
+	p.skipSpace(false)
+	if p.is('#') {
+		for p.i < len(p.b) && p.b[p.i] != '\n' {
+			p.i++
+		}
+	}
+	if p.i < len(p.b) {
+		if !p.is('\n') {
+			return p.error("expected end of line")
+		}
+		p.i++
+		p.line++
+	}
+	return nil
+}
+
+// key parses a dotted key of bare and quoted parts
+func (p *tomlParser) key() (keys []string, err error) {
+	for {
+		p.skipSpace(false)
+		var k string
+		switch {
+		case p.is('"'), p.is('\''):
+			if k, err = p.string(); err != nil {
+				return
+			}
+		default:
+			s := p.i
+			for p.i < len(p.b) && tomlBare(p.b[p.i]) {
+				p.i++
+			}
+			if s == p.i {
+				return nil, p.error("expected key")
+			}
+			k = string(p.b[s:p.i])
+		}
+		keys = append(keys, k)
+		p.skipSpace(false)
+		if !p.is('.') {
+			return
+		}
+		p.i++
+	}
+}
+
+func tomlBare(c byte) bool {
+	return c == '_' || c == '-' || (c >= '0' && c <= '9') || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z')
+}
+
+// keyValue parses a key = value pair into table t
+func (p *tomlParser) keyValue(t map[string]any) error {
+	keys, err := p.key()
+	if err != nil {
+		return err
+	}
+	if !p.is('=') {
+		return p.error("expected '=' after key")
+	}
+	p.i++
+	p.skipSpace(false)
+	v, err := p.value()
+	if err != nil {
+		return err
+	}
+	if t, err = p.subTable(t, keys[:len(keys)-1]); err != nil {
+		return err
+	}
+	k := keys[len(keys)-1]
+	if _, ok := t[k]; ok {
+		return p.error("duplicate key '" + k + "'")
+	}
+	t[k] = v
+	return nil
+}
+
+// subTable returns the table at keys in t, creating tables as needed
+func (p *tomlParser) subTable(t map[string]any, keys []string) (map[string]any, error) {
+	for _, k := range keys {
+		switch v := t[k].(type) {
+		case nil:
+			n := map[string]any{}
+			t[k] = n
+			t = n
+		case map[string]any:
+			t = v
+		case []any:
+			if len(v) == 0 {
+				return nil, p.error("key '" + k + "' is not a table")
+			}
+			n, ok := v[len(v)-1].(map[string]any)
+			if !ok {
+				return nil, p.error("key '" + k + "' is not a table")
+			}
+			t = n
+		default:
+			return nil, p.error("key '" + k + "' is not a table")
+		}
+	}
+	return t, nil
+}
+
+// table returns the table of a table header, appending
+// a new table to the array at keys if array is true
+func (p *tomlParser) table(root map[string]any, keys []string, array bool) (map[string]any, error) {
+	if !array {
+		return p.subTable(root, keys)
+	}
+	t, err := p.subTable(root, keys[:len(keys)-1])
+	if err != nil {
+		return nil, err
+	}
+	k := keys[len(keys)-1]
+	n := map[string]any{}
+	switch v := t[k].(type) {
+	case nil:
+		t[k] = []any{n}
+	case []any:
+		t[k] = append(v, n)
+	default:
+		return nil, p.error("key '" + k + "' is not an array of tables")
+	}
+	return n, nil
+}
+
+// value parses a toml value
+func (p *tomlParser) value() (any, error) {
+	if p.i >= len(p.b) {
+		return nil, p.error("expected value")
+	}
+	switch p.b[p.i] {
+	case '"', '\'':
+		return p.string()
+	case '[':
+		return p.array()
+	case '{':
+		return p.inlineTable()
+	}
+	s := p.i
+	for p.i < len(p.b) && !strings.ContainsRune(" \t\r\n,]}#", rune(p.b[p.i])) {
+		p.i++
+	}
+	v := string(p.b[s:p.i])
+	// local date time separated by a space
+	if len(v) == 10 && v[4] == '-' && v[7] == '-' && p.has(" ") && p.i+1 < len(p.b) && p.b[p.i+1] >= '0' && p.b[p.i+1] <= '9' {
+		p.i++
+		for p.i < len(p.b) && !strings.ContainsRune(" \t\r\n,]}#", rune(p.b[p.i])) {
+			p.i++
+		}
+		v = string(p.b[s:p.i])
+	}
+	switch v {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "inf", "+inf", "-inf", "nan", "+nan", "-nan":
+		f, _ := strconv.ParseFloat(v, 64)
+		return f, nil
+	}
+	if i, err := strconv.ParseInt(v, 0, 64); err == nil {
+		return int(i), nil
+	}
+	if f, err := strconv.ParseFloat(strings.ReplaceAll(v, "_", ""), 64); err == nil {
+		return f, nil
+	}
+	if len(v) >= 8 && (v[2] == ':' || (len(v) >= 10 && v[4] == '-' && v[7] == '-')) {
+		return v, nil // date or time
+	}
+	return nil, p.error("invalid value '" + v + "'")
+}
+
+// array parses a toml array
+func (p *tomlParser) array() (a []any, err error) {
+	p.i++
+	a = []any{}
+	for {
+		p.skipSpace(true)
+		if p.is(']') {
+			p.i++
+			return
+		}
+		var v any
+		if v, err = p.value(); err != nil {
+			return
+		}
+		a = append(a, v)
+		p.skipSpace(true)
+		if p.is(',') {
+			p.i++
+		} else if !p.is(']') {
+			return nil, p.error("expected ',' or ']' in array")
+		}
+	}
+}
+
+// inlineTable parses a toml inline table
+func (p *tomlParser) inlineTable() (t map[string]any, err error) {
+	p.i++
+	t = map[string]any{}
+	for {
+		p.skipSpace(false)
+		if p.is('}') {
+			p.i++
+			return
+		}
+		if err = p.keyValue(t); err != nil {
+			return
+		}
+		p.skipSpace(false)
+		if p.is(',') {
+			p.i++
+		} else if !p.is('}') {
+			return nil, p.error("expected ',' or '}' in inline table")
+		}
+	}
+}
+
+// string parses basic, literal and multi-line toml strings
+func (p *tomlParser) string() (string, error) {
+	q := p.b[p.i]
+	end := string(q)
+	if p.has(strings.Repeat(end, 3)) {
+		end = strings.Repeat(end, 3)
+		p.i += 3
+		if p.is('\r') {
+			p.i++
+		}
+		if p.is('\n') { // newline following opening quotes is trimmed
+			p.i++
+			p.line++
+		}
+	} else {
+		p.i++
+	}
+	var s strings.Builder
+	for p.i < len(p.b) {
+		c := p.b[p.i]
+		switch {
+		case p.has(end):
+			p.i += len(end)
+			return s.String(), nil
+		case c == '\n' && len(end) == 1:
+			return "", p.error("unterminated string")
+		case c == '\\' && q == '"':
+			p.i++
+			if err := p.escape(&s, len(end) == 3); err != nil {
+				return "", err
+			}
+			continue
+		case c == '\n':
+			p.line++
+		}
+		s.WriteByte(c)
+		p.i++
+	}
+	return "", p.error("unterminated string")
+}
+
+// escape writes the escaped character following a backslash to s
+func (p *tomlParser) escape(s *strings.Builder, multiline bool) error {
+	if p.i >= len(p.b) {
+		return p.error("unterminated string")
+	}
+	c := p.b[p.i]
+	p.i++
+	switch c {
+	case 'b':
+		s.WriteByte('\b')
+	case 't':
+		s.WriteByte('\t')
+	case 'n':
+		s.WriteByte('\n')
+	case 'f':
+		s.WriteByte('\f')
+	case 'r':
+		s.WriteByte('\r')
+	case 'e':
+		s.WriteByte(0x1b)
+	case '"', '\\':
+		s.WriteByte(c)
+	case 'u', 'U':
+		n := 4
+		if c == 'U' {
+			n = 8
+		}
+		if p.i+n > len(p.b) {
+			return p.error("invalid unicode escape")
+		}
+		r, err := strconv.ParseUint(string(p.b[p.i:p.i+n]), 16, 32)
+		if err != nil || !utf8.ValidRune(rune(r)) {
+			return p.error("invalid unicode escape")
+		}
+		s.WriteRune(rune(r))
+		p.i += n
+	case ' ', '\t', '\r', '\n':
+		if !multiline {
+			return p.error("invalid escape")
+		}
+		// line ending backslash trims whitespace up to the next content
+		for p.i--; p.i < len(p.b) && strings.IndexByte(" \t\r\n", p.b[p.i]) >= 0; p.i++ {
+			if p.b[p.i] == '\n' {
+				p.line++
+			}
+		}
+	default:
+		return p.error("invalid escape '\\" + string(c) + "'")
+	}
+	return nil
+}
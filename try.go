@@ -0,0 +1,178 @@
+// Copyright 2023 james dotter. All rights reserved.
+// Use of this source code is governed by a
+// license that can be found in the gotype LICENSE file.
+
+package gotype
+
+import (
+	"errors"
+)
+
+//go:generate go run try_gen.go
+
+// ------------------------------------------------------------ /
+// TRY CONVERSION IMPLEMENTATION
+// non panicking conversion of VALUE and each gotype type,
+// returning a ConversionError if the value cannot convert.
+// The TryX and CanX methods of each type are generated in
+// try_methods.go from the helpers of each target kind below
+// ------------------------------------------------------------ /
+
+var (
+	errTryInvalid = errors.New("invalid VALUE")
+	errTryNil     = errors.New("nil value")
+	errTryEmpty   = errors.New("empty string")
+)
+
+// tryValue returns the VALUE of a converted by conversions a does not implement
+func tryValue(a any) VALUE {
+	switch v := a.(type) {
+	case VALUE:
+		return v
+	case ARRAY:
+		return (VALUE)(v)
+	case MAP:
+		return (VALUE)(v)
+	case SLICE:
+		return (VALUE)(v)
+	case STRUCT:
+		return (VALUE)(v)
+	}
+	return ValueOf(a)
+}
+
+// tryCheck returns the error of converting VALUE v to KIND to that
+// the conversions do not report: v is invalid, a nil pointer or
+// interface or has no data, or v is an empty string or bytes
+// converted to a time or uuid
+func tryCheck(v VALUE, to KIND) error {
+	for {
+		switch {
+		case v.typ == nil:
+			return errTryInvalid
+		case v.flag&flagIndir != 0 && v.ptr == nil:
+			return errTryNil
+		case v.Kind() == Pointer || v.Kind() == Interface:
+			if v = v.Elem(); v.typ == nil {
+				return errTryNil
+			}
+			continue
+		}
+		break
+	}
+	if to == Time || to == Uuid {
+		if k := v.KIND(); (k == String || k == Bytes) && v.Len() == 0 {
+			return errTryEmpty
+		}
+	}
+	return nil
+}
+
+// try returns the result of conversion f of a to KIND to, or a
+// ConversionError if a cannot convert. Panics that are not
+// recoverable are bugs and are re-panicked
+func try[T any](a any, to KIND, f func() T) (t T, err error) {
+	v := tryValue(a)
+	if err = tryCheck(v, to); err != nil {
+		var in any
+		if err != errTryInvalid && (v.flag&flagIndir == 0 || v.ptr != nil) {
+			in = valueInterface(v)
+		}
+		return t, &ConversionError{v.tryKind(), to, in, err}
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			if !recoverable(r) {
+				panic(r)
+			}
+			err = conversionError(v, to, r)
+		}
+	}()
+	return f(), nil
+}
+
+// can evaluates whether the Try conversion returning err succeeded
+func can[T any](_ T, err error) bool {
+	return err == nil
+}
+
+// tryKind returns the KIND of VALUE v, Invalid if v has no TYPE
+func (v VALUE) tryKind() KIND {
+	if v.typ == nil {
+		return Invalid
+	}
+	return v.KIND()
+}
+
+// ------------------------------------------------------------ /
+// TRY CONVERSIONS BY TARGET KIND
+// ------------------------------------------------------------ /
+
+// convertible is the set of gotype types with Try and Can conversions
+type convertible interface {
+	VALUE | ARRAY | BOOL | BYTES | FLOAT | INT | JSON | MAP | SLICE | STRING | STRUCT | TIME | UINT | UUID
+}
+
+// tryBool returns t as bool, or a ConversionError if t cannot convert
+func tryBool[T convertible](t T) (bool, error) {
+	if c, ok := any(t).(interface{ Bool() bool }); ok {
+		return try(t, Bool, c.Bool)
+	}
+	return try(t, Bool, tryValue(t).Bool)
+}
+
+// tryInt returns t as int, or a ConversionError if t cannot convert
+func tryInt[T convertible](t T) (int, error) {
+	if c, ok := any(t).(interface{ Int() int }); ok {
+		return try(t, Int, c.Int)
+	}
+	return try(t, Int, tryValue(t).Int)
+}
+
+// tryUint returns t as uint, or a ConversionError if t cannot convert
+func tryUint[T convertible](t T) (uint, error) {
+	if c, ok := any(t).(interface{ Uint() uint }); ok {
+		return try(t, Uint, c.Uint)
+	}
+	return try(t, Uint, tryValue(t).Uint)
+}
+
+// tryFloat64 returns t as float64, or a ConversionError if t cannot convert
+func tryFloat64[T convertible](t T) (float64, error) {
+	if c, ok := any(t).(interface{ Float64() float64 }); ok {
+		return try(t, Float64, c.Float64)
+	}
+	return try(t, Float64, tryValue(t).Float64)
+}
+
+// tryString returns t as string, or a ConversionError if t cannot convert
+func tryString[T convertible](t T) (string, error) {
+	if c, ok := any(t).(interface{ String() string }); ok {
+		return try(t, String, c.String)
+	}
+	return try(t, String, tryValue(t).String)
+}
+
+// tryBytes returns t as []byte, or a ConversionError if t cannot convert
+func tryBytes[T convertible](t T) ([]byte, error) {
+	if c, ok := any(t).(interface{ Bytes() []byte }); ok {
+		return try(t, Bytes, c.Bytes)
+	}
+	return try(t, Bytes, tryValue(t).Bytes)
+}
+
+// tryTime returns t as TIME, or a ConversionError if t cannot convert
+func tryTime[T convertible](t T) (TIME, error) {
+	if c, ok := any(t).(interface{ TIME() TIME }); ok {
+		return try(t, Time, c.TIME)
+	}
+	return try(t, Time, tryValue(t).TIME)
+}
+
+// tryUUID returns t as UUID, or a ConversionError if t cannot convert
+func tryUUID[T convertible](t T) (UUID, error) {
+	if c, ok := any(t).(interface{ UUID() UUID }); ok {
+		return try(t, Uuid, c.UUID)
+	}
+	return try(t, Uuid, tryValue(t).UUID)
+}
@@ -0,0 +1,102 @@
+// Copyright 2023 james dotter. All rights reserved.
+// Use of this source code is governed by a
+// license that can be found in the gotype LICENSE file.
+
+//go:build ignore
+
+// try_gen generates try_methods.go, the TryX and CanX methods of
+// VALUE and each gotype type, from the helpers of each target
+// kind in try.go. Run with go generate
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+)
+
+// types are the gotype types with Try and Can conversions and their receivers
+var types = []struct{ name, recv string }{
+	{"VALUE", "v"},
+	{"ARRAY", "a"},
+	{"BOOL", "b"},
+	{"BYTES", "b"},
+	{"FLOAT", "f"},
+	{"INT", "i"},
+	{"JSON", "j"},
+	{"MAP", "m"},
+	{"SLICE", "s"},
+	{"STRING", "s"},
+	{"STRUCT", "s"},
+	{"TIME", "t"},
+	{"UINT", "u"},
+	{"UUID", "u"},
+}
+
+// targets are the conversions of each type and the go types converted to
+var targets = []struct{ name, typ string }{
+	{"Bool", "bool"},
+	{"Int", "int"},
+	{"Uint", "uint"},
+	{"Float64", "float64"},
+	{"String", "string"},
+	{"Bytes", "[]byte"},
+	{"Time", "TIME"},
+	{"UUID", "UUID"},
+}
+
+// existing are the Can methods of types declared before the
+// generated methods, which keep their own signature and checks
+var existing = map[string]bool{
+	"BYTES.CanBool":   true,
+	"BYTES.CanInt":    true,
+	"BYTES.CanUint":   true,
+	"BYTES.CanString": true,
+	"STRING.CanTime":  true,
+}
+
+func main() {
+	b := &bytes.Buffer{}
+	b.WriteString(`// Copyright 2023 james dotter. All rights reserved.
+// Use of this source code is governed by a
+// license that can be found in the gotype LICENSE file.
+
+// Code generated by try_gen.go; DO NOT EDIT.
+
+package gotype
+`)
+	for _, t := range types {
+		fmt.Fprintf(b, `
+// ------------------------------------------------------------ /
+// %s TRY CONVERSIONS
+// ------------------------------------------------------------ /
+`, t.name)
+		for _, c := range targets {
+			fmt.Fprintf(b, `
+// Try%[3]s returns gotype %[1]s as %[4]s, or a ConversionError if %[1]s cannot convert
+func (%[2]s %[1]s) Try%[3]s() (%[4]s, error) {
+	return try%[3]s(%[2]s)
+}
+`, t.name, t.recv, c.name, c.typ)
+		}
+		for _, c := range targets {
+			if existing[t.name+".Can"+c.name] {
+				continue
+			}
+			fmt.Fprintf(b, `
+// Can%[3]s evaluates whether gotype %[1]s can convert to %[4]s, see Try%[3]s
+func (%[2]s %[1]s) Can%[3]s() bool {
+	return can(%[2]s.Try%[3]s())
+}
+`, t.name, t.recv, c.name, c.typ)
+		}
+	}
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		panic(err)
+	}
+	if err = os.WriteFile("try_methods.go", src, 0644); err != nil {
+		panic(err)
+	}
+}
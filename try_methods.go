@@ -0,0 +1,1158 @@
+// Copyright 2023 james dotter. All rights reserved.
+// Use of this source code is governed by a
+// license that can be found in the gotype LICENSE file.
+
+// Code generated by try_gen.go; DO NOT EDIT.
+
+package gotype
+
+// ------------------------------------------------------------ /
+// VALUE TRY CONVERSIONS
+// ------------------------------------------------------------ /
+
+// TryBool returns gotype VALUE as bool, or a ConversionError if VALUE cannot convert
+func (v VALUE) TryBool() (bool, error) {
+	return tryBool(v)
+}
+
+// TryInt returns gotype VALUE as int, or a ConversionError if VALUE cannot convert
+func (v VALUE) TryInt() (int, error) {
+	return tryInt(v)
+}
+
+// TryUint returns gotype VALUE as uint, or a ConversionError if VALUE cannot convert
+func (v VALUE) TryUint() (uint, error) {
+	return tryUint(v)
+}
+
+// TryFloat64 returns gotype VALUE as float64, or a ConversionError if VALUE cannot convert
+func (v VALUE) TryFloat64() (float64, error) {
+	return tryFloat64(v)
+}
+
+// TryString returns gotype VALUE as string, or a ConversionError if VALUE cannot convert
+func (v VALUE) TryString() (string, error) {
+	return tryString(v)
+}
+
+// TryBytes returns gotype VALUE as []byte, or a ConversionError if VALUE cannot convert
+func (v VALUE) TryBytes() ([]byte, error) {
+	return tryBytes(v)
+}
+
+// TryTime returns gotype VALUE as TIME, or a ConversionError if VALUE cannot convert
+func (v VALUE) TryTime() (TIME, error) {
+	return tryTime(v)
+}
+
+// TryUUID returns gotype VALUE as UUID, or a ConversionError if VALUE cannot convert
+func (v VALUE) TryUUID() (UUID, error) {
+	return tryUUID(v)
+}
+
+// CanBool evaluates whether gotype VALUE can convert to bool, see TryBool
+func (v VALUE) CanBool() bool {
+	return can(v.TryBool())
+}
+
+// CanInt evaluates whether gotype VALUE can convert to int, see TryInt
+func (v VALUE) CanInt() bool {
+	return can(v.TryInt())
+}
+
+// CanUint evaluates whether gotype VALUE can convert to uint, see TryUint
+func (v VALUE) CanUint() bool {
+	return can(v.TryUint())
+}
+
+// CanFloat64 evaluates whether gotype VALUE can convert to float64, see TryFloat64
+func (v VALUE) CanFloat64() bool {
+	return can(v.TryFloat64())
+}
+
+// CanString evaluates whether gotype VALUE can convert to string, see TryString
+func (v VALUE) CanString() bool {
+	return can(v.TryString())
+}
+
+// CanBytes evaluates whether gotype VALUE can convert to []byte, see TryBytes
+func (v VALUE) CanBytes() bool {
+	return can(v.TryBytes())
+}
+
+// CanTime evaluates whether gotype VALUE can convert to TIME, see TryTime
+func (v VALUE) CanTime() bool {
+	return can(v.TryTime())
+}
+
+// CanUUID evaluates whether gotype VALUE can convert to UUID, see TryUUID
+func (v VALUE) CanUUID() bool {
+	return can(v.TryUUID())
+}
+
+// ------------------------------------------------------------ /
+// ARRAY TRY CONVERSIONS
+// ------------------------------------------------------------ /
+
+// TryBool returns gotype ARRAY as bool, or a ConversionError if ARRAY cannot convert
+func (a ARRAY) TryBool() (bool, error) {
+	return tryBool(a)
+}
+
+// TryInt returns gotype ARRAY as int, or a ConversionError if ARRAY cannot convert
+func (a ARRAY) TryInt() (int, error) {
+	return tryInt(a)
+}
+
+// TryUint returns gotype ARRAY as uint, or a ConversionError if ARRAY cannot convert
+func (a ARRAY) TryUint() (uint, error) {
+	return tryUint(a)
+}
+
+// TryFloat64 returns gotype ARRAY as float64, or a ConversionError if ARRAY cannot convert
+func (a ARRAY) TryFloat64() (float64, error) {
+	return tryFloat64(a)
+}
+
+// TryString returns gotype ARRAY as string, or a ConversionError if ARRAY cannot convert
+func (a ARRAY) TryString() (string, error) {
+	return tryString(a)
+}
+
+// TryBytes returns gotype ARRAY as []byte, or a ConversionError if ARRAY cannot convert
+func (a ARRAY) TryBytes() ([]byte, error) {
+	return tryBytes(a)
+}
+
+// TryTime returns gotype ARRAY as TIME, or a ConversionError if ARRAY cannot convert
+func (a ARRAY) TryTime() (TIME, error) {
+	return tryTime(a)
+}
+
+// TryUUID returns gotype ARRAY as UUID, or a ConversionError if ARRAY cannot convert
+func (a ARRAY) TryUUID() (UUID, error) {
+	return tryUUID(a)
+}
+
+// CanBool evaluates whether gotype ARRAY can convert to bool, see TryBool
+func (a ARRAY) CanBool() bool {
+	return can(a.TryBool())
+}
+
+// CanInt evaluates whether gotype ARRAY can convert to int, see TryInt
+func (a ARRAY) CanInt() bool {
+	return can(a.TryInt())
+}
+
+// CanUint evaluates whether gotype ARRAY can convert to uint, see TryUint
+func (a ARRAY) CanUint() bool {
+	return can(a.TryUint())
+}
+
+// CanFloat64 evaluates whether gotype ARRAY can convert to float64, see TryFloat64
+func (a ARRAY) CanFloat64() bool {
+	return can(a.TryFloat64())
+}
+
+// CanString evaluates whether gotype ARRAY can convert to string, see TryString
+func (a ARRAY) CanString() bool {
+	return can(a.TryString())
+}
+
+// CanBytes evaluates whether gotype ARRAY can convert to []byte, see TryBytes
+func (a ARRAY) CanBytes() bool {
+	return can(a.TryBytes())
+}
+
+// CanTime evaluates whether gotype ARRAY can convert to TIME, see TryTime
+func (a ARRAY) CanTime() bool {
+	return can(a.TryTime())
+}
+
+// CanUUID evaluates whether gotype ARRAY can convert to UUID, see TryUUID
+func (a ARRAY) CanUUID() bool {
+	return can(a.TryUUID())
+}
+
+// ------------------------------------------------------------ /
+// BOOL TRY CONVERSIONS
+// ------------------------------------------------------------ /
+
+// TryBool returns gotype BOOL as bool, or a ConversionError if BOOL cannot convert
+func (b BOOL) TryBool() (bool, error) {
+	return tryBool(b)
+}
+
+// TryInt returns gotype BOOL as int, or a ConversionError if BOOL cannot convert
+func (b BOOL) TryInt() (int, error) {
+	return tryInt(b)
+}
+
+// TryUint returns gotype BOOL as uint, or a ConversionError if BOOL cannot convert
+func (b BOOL) TryUint() (uint, error) {
+	return tryUint(b)
+}
+
+// TryFloat64 returns gotype BOOL as float64, or a ConversionError if BOOL cannot convert
+func (b BOOL) TryFloat64() (float64, error) {
+	return tryFloat64(b)
+}
+
+// TryString returns gotype BOOL as string, or a ConversionError if BOOL cannot convert
+func (b BOOL) TryString() (string, error) {
+	return tryString(b)
+}
+
+// TryBytes returns gotype BOOL as []byte, or a ConversionError if BOOL cannot convert
+func (b BOOL) TryBytes() ([]byte, error) {
+	return tryBytes(b)
+}
+
+// TryTime returns gotype BOOL as TIME, or a ConversionError if BOOL cannot convert
+func (b BOOL) TryTime() (TIME, error) {
+	return tryTime(b)
+}
+
+// TryUUID returns gotype BOOL as UUID, or a ConversionError if BOOL cannot convert
+func (b BOOL) TryUUID() (UUID, error) {
+	return tryUUID(b)
+}
+
+// CanBool evaluates whether gotype BOOL can convert to bool, see TryBool
+func (b BOOL) CanBool() bool {
+	return can(b.TryBool())
+}
+
+// CanInt evaluates whether gotype BOOL can convert to int, see TryInt
+func (b BOOL) CanInt() bool {
+	return can(b.TryInt())
+}
+
+// CanUint evaluates whether gotype BOOL can convert to uint, see TryUint
+func (b BOOL) CanUint() bool {
+	return can(b.TryUint())
+}
+
+// CanFloat64 evaluates whether gotype BOOL can convert to float64, see TryFloat64
+func (b BOOL) CanFloat64() bool {
+	return can(b.TryFloat64())
+}
+
+// CanString evaluates whether gotype BOOL can convert to string, see TryString
+func (b BOOL) CanString() bool {
+	return can(b.TryString())
+}
+
+// CanBytes evaluates whether gotype BOOL can convert to []byte, see TryBytes
+func (b BOOL) CanBytes() bool {
+	return can(b.TryBytes())
+}
+
+// CanTime evaluates whether gotype BOOL can convert to TIME, see TryTime
+func (b BOOL) CanTime() bool {
+	return can(b.TryTime())
+}
+
+// CanUUID evaluates whether gotype BOOL can convert to UUID, see TryUUID
+func (b BOOL) CanUUID() bool {
+	return can(b.TryUUID())
+}
+
+// ------------------------------------------------------------ /
+// BYTES TRY CONVERSIONS
+// ------------------------------------------------------------ /
+
+// TryBool returns gotype BYTES as bool, or a ConversionError if BYTES cannot convert
+func (b BYTES) TryBool() (bool, error) {
+	return tryBool(b)
+}
+
+// TryInt returns gotype BYTES as int, or a ConversionError if BYTES cannot convert
+func (b BYTES) TryInt() (int, error) {
+	return tryInt(b)
+}
+
+// TryUint returns gotype BYTES as uint, or a ConversionError if BYTES cannot convert
+func (b BYTES) TryUint() (uint, error) {
+	return tryUint(b)
+}
+
+// TryFloat64 returns gotype BYTES as float64, or a ConversionError if BYTES cannot convert
+func (b BYTES) TryFloat64() (float64, error) {
+	return tryFloat64(b)
+}
+
+// TryString returns gotype BYTES as string, or a ConversionError if BYTES cannot convert
+func (b BYTES) TryString() (string, error) {
+	return tryString(b)
+}
+
+// TryBytes returns gotype BYTES as []byte, or a ConversionError if BYTES cannot convert
+func (b BYTES) TryBytes() ([]byte, error) {
+	return tryBytes(b)
+}
+
+// TryTime returns gotype BYTES as TIME, or a ConversionError if BYTES cannot convert
+func (b BYTES) TryTime() (TIME, error) {
+	return tryTime(b)
+}
+
+// TryUUID returns gotype BYTES as UUID, or a ConversionError if BYTES cannot convert
+func (b BYTES) TryUUID() (UUID, error) {
+	return tryUUID(b)
+}
+
+// CanFloat64 evaluates whether gotype BYTES can convert to float64, see TryFloat64
+func (b BYTES) CanFloat64() bool {
+	return can(b.TryFloat64())
+}
+
+// CanBytes evaluates whether gotype BYTES can convert to []byte, see TryBytes
+func (b BYTES) CanBytes() bool {
+	return can(b.TryBytes())
+}
+
+// CanTime evaluates whether gotype BYTES can convert to TIME, see TryTime
+func (b BYTES) CanTime() bool {
+	return can(b.TryTime())
+}
+
+// CanUUID evaluates whether gotype BYTES can convert to UUID, see TryUUID
+func (b BYTES) CanUUID() bool {
+	return can(b.TryUUID())
+}
+
+// ------------------------------------------------------------ /
+// FLOAT TRY CONVERSIONS
+// ------------------------------------------------------------ /
+
+// TryBool returns gotype FLOAT as bool, or a ConversionError if FLOAT cannot convert
+func (f FLOAT) TryBool() (bool, error) {
+	return tryBool(f)
+}
+
+// TryInt returns gotype FLOAT as int, or a ConversionError if FLOAT cannot convert
+func (f FLOAT) TryInt() (int, error) {
+	return tryInt(f)
+}
+
+// TryUint returns gotype FLOAT as uint, or a ConversionError if FLOAT cannot convert
+func (f FLOAT) TryUint() (uint, error) {
+	return tryUint(f)
+}
+
+// TryFloat64 returns gotype FLOAT as float64, or a ConversionError if FLOAT cannot convert
+func (f FLOAT) TryFloat64() (float64, error) {
+	return tryFloat64(f)
+}
+
+// TryString returns gotype FLOAT as string, or a ConversionError if FLOAT cannot convert
+func (f FLOAT) TryString() (string, error) {
+	return tryString(f)
+}
+
+// TryBytes returns gotype FLOAT as []byte, or a ConversionError if FLOAT cannot convert
+func (f FLOAT) TryBytes() ([]byte, error) {
+	return tryBytes(f)
+}
+
+// TryTime returns gotype FLOAT as TIME, or a ConversionError if FLOAT cannot convert
+func (f FLOAT) TryTime() (TIME, error) {
+	return tryTime(f)
+}
+
+// TryUUID returns gotype FLOAT as UUID, or a ConversionError if FLOAT cannot convert
+func (f FLOAT) TryUUID() (UUID, error) {
+	return tryUUID(f)
+}
+
+// CanBool evaluates whether gotype FLOAT can convert to bool, see TryBool
+func (f FLOAT) CanBool() bool {
+	return can(f.TryBool())
+}
+
+// CanInt evaluates whether gotype FLOAT can convert to int, see TryInt
+func (f FLOAT) CanInt() bool {
+	return can(f.TryInt())
+}
+
+// CanUint evaluates whether gotype FLOAT can convert to uint, see TryUint
+func (f FLOAT) CanUint() bool {
+	return can(f.TryUint())
+}
+
+// CanFloat64 evaluates whether gotype FLOAT can convert to float64, see TryFloat64
+func (f FLOAT) CanFloat64() bool {
+	return can(f.TryFloat64())
+}
+
+// CanString evaluates whether gotype FLOAT can convert to string, see TryString
+func (f FLOAT) CanString() bool {
+	return can(f.TryString())
+}
+
+// CanBytes evaluates whether gotype FLOAT can convert to []byte, see TryBytes
+func (f FLOAT) CanBytes() bool {
+	return can(f.TryBytes())
+}
+
+// CanTime evaluates whether gotype FLOAT can convert to TIME, see TryTime
+func (f FLOAT) CanTime() bool {
+	return can(f.TryTime())
+}
+
+// CanUUID evaluates whether gotype FLOAT can convert to UUID, see TryUUID
+func (f FLOAT) CanUUID() bool {
+	return can(f.TryUUID())
+}
+
+// ------------------------------------------------------------ /
+// INT TRY CONVERSIONS
+// ------------------------------------------------------------ /
+
+// TryBool returns gotype INT as bool, or a ConversionError if INT cannot convert
+func (i INT) TryBool() (bool, error) {
+	return tryBool(i)
+}
+
+// TryInt returns gotype INT as int, or a ConversionError if INT cannot convert
+func (i INT) TryInt() (int, error) {
+	return tryInt(i)
+}
+
+// TryUint returns gotype INT as uint, or a ConversionError if INT cannot convert
+func (i INT) TryUint() (uint, error) {
+	return tryUint(i)
+}
+
+// TryFloat64 returns gotype INT as float64, or a ConversionError if INT cannot convert
+func (i INT) TryFloat64() (float64, error) {
+	return tryFloat64(i)
+}
+
+// TryString returns gotype INT as string, or a ConversionError if INT cannot convert
+func (i INT) TryString() (string, error) {
+	return tryString(i)
+}
+
+// TryBytes returns gotype INT as []byte, or a ConversionError if INT cannot convert
+func (i INT) TryBytes() ([]byte, error) {
+	return tryBytes(i)
+}
+
+// TryTime returns gotype INT as TIME, or a ConversionError if INT cannot convert
+func (i INT) TryTime() (TIME, error) {
+	return tryTime(i)
+}
+
+// TryUUID returns gotype INT as UUID, or a ConversionError if INT cannot convert
+func (i INT) TryUUID() (UUID, error) {
+	return tryUUID(i)
+}
+
+// CanBool evaluates whether gotype INT can convert to bool, see TryBool
+func (i INT) CanBool() bool {
+	return can(i.TryBool())
+}
+
+// CanInt evaluates whether gotype INT can convert to int, see TryInt
+func (i INT) CanInt() bool {
+	return can(i.TryInt())
+}
+
+// CanUint evaluates whether gotype INT can convert to uint, see TryUint
+func (i INT) CanUint() bool {
+	return can(i.TryUint())
+}
+
+// CanFloat64 evaluates whether gotype INT can convert to float64, see TryFloat64
+func (i INT) CanFloat64() bool {
+	return can(i.TryFloat64())
+}
+
+// CanString evaluates whether gotype INT can convert to string, see TryString
+func (i INT) CanString() bool {
+	return can(i.TryString())
+}
+
+// CanBytes evaluates whether gotype INT can convert to []byte, see TryBytes
+func (i INT) CanBytes() bool {
+	return can(i.TryBytes())
+}
+
+// CanTime evaluates whether gotype INT can convert to TIME, see TryTime
+func (i INT) CanTime() bool {
+	return can(i.TryTime())
+}
+
+// CanUUID evaluates whether gotype INT can convert to UUID, see TryUUID
+func (i INT) CanUUID() bool {
+	return can(i.TryUUID())
+}
+
+// ------------------------------------------------------------ /
+// JSON TRY CONVERSIONS
+// ------------------------------------------------------------ /
+
+// TryBool returns gotype JSON as bool, or a ConversionError if JSON cannot convert
+func (j JSON) TryBool() (bool, error) {
+	return tryBool(j)
+}
+
+// TryInt returns gotype JSON as int, or a ConversionError if JSON cannot convert
+func (j JSON) TryInt() (int, error) {
+	return tryInt(j)
+}
+
+// TryUint returns gotype JSON as uint, or a ConversionError if JSON cannot convert
+func (j JSON) TryUint() (uint, error) {
+	return tryUint(j)
+}
+
+// TryFloat64 returns gotype JSON as float64, or a ConversionError if JSON cannot convert
+func (j JSON) TryFloat64() (float64, error) {
+	return tryFloat64(j)
+}
+
+// TryString returns gotype JSON as string, or a ConversionError if JSON cannot convert
+func (j JSON) TryString() (string, error) {
+	return tryString(j)
+}
+
+// TryBytes returns gotype JSON as []byte, or a ConversionError if JSON cannot convert
+func (j JSON) TryBytes() ([]byte, error) {
+	return tryBytes(j)
+}
+
+// TryTime returns gotype JSON as TIME, or a ConversionError if JSON cannot convert
+func (j JSON) TryTime() (TIME, error) {
+	return tryTime(j)
+}
+
+// TryUUID returns gotype JSON as UUID, or a ConversionError if JSON cannot convert
+func (j JSON) TryUUID() (UUID, error) {
+	return tryUUID(j)
+}
+
+// CanBool evaluates whether gotype JSON can convert to bool, see TryBool
+func (j JSON) CanBool() bool {
+	return can(j.TryBool())
+}
+
+// CanInt evaluates whether gotype JSON can convert to int, see TryInt
+func (j JSON) CanInt() bool {
+	return can(j.TryInt())
+}
+
+// CanUint evaluates whether gotype JSON can convert to uint, see TryUint
+func (j JSON) CanUint() bool {
+	return can(j.TryUint())
+}
+
+// CanFloat64 evaluates whether gotype JSON can convert to float64, see TryFloat64
+func (j JSON) CanFloat64() bool {
+	return can(j.TryFloat64())
+}
+
+// CanString evaluates whether gotype JSON can convert to string, see TryString
+func (j JSON) CanString() bool {
+	return can(j.TryString())
+}
+
+// CanBytes evaluates whether gotype JSON can convert to []byte, see TryBytes
+func (j JSON) CanBytes() bool {
+	return can(j.TryBytes())
+}
+
+// CanTime evaluates whether gotype JSON can convert to TIME, see TryTime
+func (j JSON) CanTime() bool {
+	return can(j.TryTime())
+}
+
+// CanUUID evaluates whether gotype JSON can convert to UUID, see TryUUID
+func (j JSON) CanUUID() bool {
+	return can(j.TryUUID())
+}
+
+// ------------------------------------------------------------ /
+// MAP TRY CONVERSIONS
+// ------------------------------------------------------------ /
+
+// TryBool returns gotype MAP as bool, or a ConversionError if MAP cannot convert
+func (m MAP) TryBool() (bool, error) {
+	return tryBool(m)
+}
+
+// TryInt returns gotype MAP as int, or a ConversionError if MAP cannot convert
+func (m MAP) TryInt() (int, error) {
+	return tryInt(m)
+}
+
+// TryUint returns gotype MAP as uint, or a ConversionError if MAP cannot convert
+func (m MAP) TryUint() (uint, error) {
+	return tryUint(m)
+}
+
+// TryFloat64 returns gotype MAP as float64, or a ConversionError if MAP cannot convert
+func (m MAP) TryFloat64() (float64, error) {
+	return tryFloat64(m)
+}
+
+// TryString returns gotype MAP as string, or a ConversionError if MAP cannot convert
+func (m MAP) TryString() (string, error) {
+	return tryString(m)
+}
+
+// TryBytes returns gotype MAP as []byte, or a ConversionError if MAP cannot convert
+func (m MAP) TryBytes() ([]byte, error) {
+	return tryBytes(m)
+}
+
+// TryTime returns gotype MAP as TIME, or a ConversionError if MAP cannot convert
+func (m MAP) TryTime() (TIME, error) {
+	return tryTime(m)
+}
+
+// TryUUID returns gotype MAP as UUID, or a ConversionError if MAP cannot convert
+func (m MAP) TryUUID() (UUID, error) {
+	return tryUUID(m)
+}
+
+// CanBool evaluates whether gotype MAP can convert to bool, see TryBool
+func (m MAP) CanBool() bool {
+	return can(m.TryBool())
+}
+
+// CanInt evaluates whether gotype MAP can convert to int, see TryInt
+func (m MAP) CanInt() bool {
+	return can(m.TryInt())
+}
+
+// CanUint evaluates whether gotype MAP can convert to uint, see TryUint
+func (m MAP) CanUint() bool {
+	return can(m.TryUint())
+}
+
+// CanFloat64 evaluates whether gotype MAP can convert to float64, see TryFloat64
+func (m MAP) CanFloat64() bool {
+	return can(m.TryFloat64())
+}
+
+// CanString evaluates whether gotype MAP can convert to string, see TryString
+func (m MAP) CanString() bool {
+	return can(m.TryString())
+}
+
+// CanBytes evaluates whether gotype MAP can convert to []byte, see TryBytes
+func (m MAP) CanBytes() bool {
+	return can(m.TryBytes())
+}
+
+// CanTime evaluates whether gotype MAP can convert to TIME, see TryTime
+func (m MAP) CanTime() bool {
+	return can(m.TryTime())
+}
+
+// CanUUID evaluates whether gotype MAP can convert to UUID, see TryUUID
+func (m MAP) CanUUID() bool {
+	return can(m.TryUUID())
+}
+
+// ------------------------------------------------------------ /
+// SLICE TRY CONVERSIONS
+// ------------------------------------------------------------ /
+
+// TryBool returns gotype SLICE as bool, or a ConversionError if SLICE cannot convert
+func (s SLICE) TryBool() (bool, error) {
+	return tryBool(s)
+}
+
+// TryInt returns gotype SLICE as int, or a ConversionError if SLICE cannot convert
+func (s SLICE) TryInt() (int, error) {
+	return tryInt(s)
+}
+
+// TryUint returns gotype SLICE as uint, or a ConversionError if SLICE cannot convert
+func (s SLICE) TryUint() (uint, error) {
+	return tryUint(s)
+}
+
+// TryFloat64 returns gotype SLICE as float64, or a ConversionError if SLICE cannot convert
+func (s SLICE) TryFloat64() (float64, error) {
+	return tryFloat64(s)
+}
+
+// TryString returns gotype SLICE as string, or a ConversionError if SLICE cannot convert
+func (s SLICE) TryString() (string, error) {
+	return tryString(s)
+}
+
+// TryBytes returns gotype SLICE as []byte, or a ConversionError if SLICE cannot convert
+func (s SLICE) TryBytes() ([]byte, error) {
+	return tryBytes(s)
+}
+
+// TryTime returns gotype SLICE as TIME, or a ConversionError if SLICE cannot convert
+func (s SLICE) TryTime() (TIME, error) {
+	return tryTime(s)
+}
+
+// TryUUID returns gotype SLICE as UUID, or a ConversionError if SLICE cannot convert
+func (s SLICE) TryUUID() (UUID, error) {
+	return tryUUID(s)
+}
+
+// CanBool evaluates whether gotype SLICE can convert to bool, see TryBool
+func (s SLICE) CanBool() bool {
+	return can(s.TryBool())
+}
+
+// CanInt evaluates whether gotype SLICE can convert to int, see TryInt
+func (s SLICE) CanInt() bool {
+	return can(s.TryInt())
+}
+
+// CanUint evaluates whether gotype SLICE can convert to uint, see TryUint
+func (s SLICE) CanUint() bool {
+	return can(s.TryUint())
+}
+
+// CanFloat64 evaluates whether gotype SLICE can convert to float64, see TryFloat64
+func (s SLICE) CanFloat64() bool {
+	return can(s.TryFloat64())
+}
+
+// CanString evaluates whether gotype SLICE can convert to string, see TryString
+func (s SLICE) CanString() bool {
+	return can(s.TryString())
+}
+
+// CanBytes evaluates whether gotype SLICE can convert to []byte, see TryBytes
+func (s SLICE) CanBytes() bool {
+	return can(s.TryBytes())
+}
+
+// CanTime evaluates whether gotype SLICE can convert to TIME, see TryTime
+func (s SLICE) CanTime() bool {
+	return can(s.TryTime())
+}
+
+// CanUUID evaluates whether gotype SLICE can convert to UUID, see TryUUID
+func (s SLICE) CanUUID() bool {
+	return can(s.TryUUID())
+}
+
+// ------------------------------------------------------------ /
+// STRING TRY CONVERSIONS
+// ------------------------------------------------------------ /
+
+// TryBool returns gotype STRING as bool, or a ConversionError if STRING cannot convert
+func (s STRING) TryBool() (bool, error) {
+	return tryBool(s)
+}
+
+// TryInt returns gotype STRING as int, or a ConversionError if STRING cannot convert
+func (s STRING) TryInt() (int, error) {
+	return tryInt(s)
+}
+
+// TryUint returns gotype STRING as uint, or a ConversionError if STRING cannot convert
+func (s STRING) TryUint() (uint, error) {
+	return tryUint(s)
+}
+
+// TryFloat64 returns gotype STRING as float64, or a ConversionError if STRING cannot convert
+func (s STRING) TryFloat64() (float64, error) {
+	return tryFloat64(s)
+}
+
+// TryString returns gotype STRING as string, or a ConversionError if STRING cannot convert
+func (s STRING) TryString() (string, error) {
+	return tryString(s)
+}
+
+// TryBytes returns gotype STRING as []byte, or a ConversionError if STRING cannot convert
+func (s STRING) TryBytes() ([]byte, error) {
+	return tryBytes(s)
+}
+
+// TryTime returns gotype STRING as TIME, or a ConversionError if STRING cannot convert
+func (s STRING) TryTime() (TIME, error) {
+	return tryTime(s)
+}
+
+// TryUUID returns gotype STRING as UUID, or a ConversionError if STRING cannot convert
+func (s STRING) TryUUID() (UUID, error) {
+	return tryUUID(s)
+}
+
+// CanBool evaluates whether gotype STRING can convert to bool, see TryBool
+func (s STRING) CanBool() bool {
+	return can(s.TryBool())
+}
+
+// CanInt evaluates whether gotype STRING can convert to int, see TryInt
+func (s STRING) CanInt() bool {
+	return can(s.TryInt())
+}
+
+// CanUint evaluates whether gotype STRING can convert to uint, see TryUint
+func (s STRING) CanUint() bool {
+	return can(s.TryUint())
+}
+
+// CanFloat64 evaluates whether gotype STRING can convert to float64, see TryFloat64
+func (s STRING) CanFloat64() bool {
+	return can(s.TryFloat64())
+}
+
+// CanString evaluates whether gotype STRING can convert to string, see TryString
+func (s STRING) CanString() bool {
+	return can(s.TryString())
+}
+
+// CanBytes evaluates whether gotype STRING can convert to []byte, see TryBytes
+func (s STRING) CanBytes() bool {
+	return can(s.TryBytes())
+}
+
+// CanUUID evaluates whether gotype STRING can convert to UUID, see TryUUID
+func (s STRING) CanUUID() bool {
+	return can(s.TryUUID())
+}
+
+// ------------------------------------------------------------ /
+// STRUCT TRY CONVERSIONS
+// ------------------------------------------------------------ /
+
+// TryBool returns gotype STRUCT as bool, or a ConversionError if STRUCT cannot convert
+func (s STRUCT) TryBool() (bool, error) {
+	return tryBool(s)
+}
+
+// TryInt returns gotype STRUCT as int, or a ConversionError if STRUCT cannot convert
+func (s STRUCT) TryInt() (int, error) {
+	return tryInt(s)
+}
+
+// TryUint returns gotype STRUCT as uint, or a ConversionError if STRUCT cannot convert
+func (s STRUCT) TryUint() (uint, error) {
+	return tryUint(s)
+}
+
+// TryFloat64 returns gotype STRUCT as float64, or a ConversionError if STRUCT cannot convert
+func (s STRUCT) TryFloat64() (float64, error) {
+	return tryFloat64(s)
+}
+
+// TryString returns gotype STRUCT as string, or a ConversionError if STRUCT cannot convert
+func (s STRUCT) TryString() (string, error) {
+	return tryString(s)
+}
+
+// TryBytes returns gotype STRUCT as []byte, or a ConversionError if STRUCT cannot convert
+func (s STRUCT) TryBytes() ([]byte, error) {
+	return tryBytes(s)
+}
+
+// TryTime returns gotype STRUCT as TIME, or a ConversionError if STRUCT cannot convert
+func (s STRUCT) TryTime() (TIME, error) {
+	return tryTime(s)
+}
+
+// TryUUID returns gotype STRUCT as UUID, or a ConversionError if STRUCT cannot convert
+func (s STRUCT) TryUUID() (UUID, error) {
+	return tryUUID(s)
+}
+
+// CanBool evaluates whether gotype STRUCT can convert to bool, see TryBool
+func (s STRUCT) CanBool() bool {
+	return can(s.TryBool())
+}
+
+// CanInt evaluates whether gotype STRUCT can convert to int, see TryInt
+func (s STRUCT) CanInt() bool {
+	return can(s.TryInt())
+}
+
+// CanUint evaluates whether gotype STRUCT can convert to uint, see TryUint
+func (s STRUCT) CanUint() bool {
+	return can(s.TryUint())
+}
+
+// CanFloat64 evaluates whether gotype STRUCT can convert to float64, see TryFloat64
+func (s STRUCT) CanFloat64() bool {
+	return can(s.TryFloat64())
+}
+
+// CanString evaluates whether gotype STRUCT can convert to string, see TryString
+func (s STRUCT) CanString() bool {
+	return can(s.TryString())
+}
+
+// CanBytes evaluates whether gotype STRUCT can convert to []byte, see TryBytes
+func (s STRUCT) CanBytes() bool {
+	return can(s.TryBytes())
+}
+
+// CanTime evaluates whether gotype STRUCT can convert to TIME, see TryTime
+func (s STRUCT) CanTime() bool {
+	return can(s.TryTime())
+}
+
+// CanUUID evaluates whether gotype STRUCT can convert to UUID, see TryUUID
+func (s STRUCT) CanUUID() bool {
+	return can(s.TryUUID())
+}
+
+// ------------------------------------------------------------ /
+// TIME TRY CONVERSIONS
+// ------------------------------------------------------------ /
+
+// TryBool returns gotype TIME as bool, or a ConversionError if TIME cannot convert
+func (t TIME) TryBool() (bool, error) {
+	return tryBool(t)
+}
+
+// TryInt returns gotype TIME as int, or a ConversionError if TIME cannot convert
+func (t TIME) TryInt() (int, error) {
+	return tryInt(t)
+}
+
+// TryUint returns gotype TIME as uint, or a ConversionError if TIME cannot convert
+func (t TIME) TryUint() (uint, error) {
+	return tryUint(t)
+}
+
+// TryFloat64 returns gotype TIME as float64, or a ConversionError if TIME cannot convert
+func (t TIME) TryFloat64() (float64, error) {
+	return tryFloat64(t)
+}
+
+// TryString returns gotype TIME as string, or a ConversionError if TIME cannot convert
+func (t TIME) TryString() (string, error) {
+	return tryString(t)
+}
+
+// TryBytes returns gotype TIME as []byte, or a ConversionError if TIME cannot convert
+func (t TIME) TryBytes() ([]byte, error) {
+	return tryBytes(t)
+}
+
+// TryTime returns gotype TIME as TIME, or a ConversionError if TIME cannot convert
+func (t TIME) TryTime() (TIME, error) {
+	return tryTime(t)
+}
+
+// TryUUID returns gotype TIME as UUID, or a ConversionError if TIME cannot convert
+func (t TIME) TryUUID() (UUID, error) {
+	return tryUUID(t)
+}
+
+// CanBool evaluates whether gotype TIME can convert to bool, see TryBool
+func (t TIME) CanBool() bool {
+	return can(t.TryBool())
+}
+
+// CanInt evaluates whether gotype TIME can convert to int, see TryInt
+func (t TIME) CanInt() bool {
+	return can(t.TryInt())
+}
+
+// CanUint evaluates whether gotype TIME can convert to uint, see TryUint
+func (t TIME) CanUint() bool {
+	return can(t.TryUint())
+}
+
+// CanFloat64 evaluates whether gotype TIME can convert to float64, see TryFloat64
+func (t TIME) CanFloat64() bool {
+	return can(t.TryFloat64())
+}
+
+// CanString evaluates whether gotype TIME can convert to string, see TryString
+func (t TIME) CanString() bool {
+	return can(t.TryString())
+}
+
+// CanBytes evaluates whether gotype TIME can convert to []byte, see TryBytes
+func (t TIME) CanBytes() bool {
+	return can(t.TryBytes())
+}
+
+// CanTime evaluates whether gotype TIME can convert to TIME, see TryTime
+func (t TIME) CanTime() bool {
+	return can(t.TryTime())
+}
+
+// CanUUID evaluates whether gotype TIME can convert to UUID, see TryUUID
+func (t TIME) CanUUID() bool {
+	return can(t.TryUUID())
+}
+
+// ------------------------------------------------------------ /
+// UINT TRY CONVERSIONS
+// ------------------------------------------------------------ /
+
+// TryBool returns gotype UINT as bool, or a ConversionError if UINT cannot convert
+func (u UINT) TryBool() (bool, error) {
+	return tryBool(u)
+}
+
+// TryInt returns gotype UINT as int, or a ConversionError if UINT cannot convert
+func (u UINT) TryInt() (int, error) {
+	return tryInt(u)
+}
+
+// TryUint returns gotype UINT as uint, or a ConversionError if UINT cannot convert
+func (u UINT) TryUint() (uint, error) {
+	return tryUint(u)
+}
+
+// TryFloat64 returns gotype UINT as float64, or a ConversionError if UINT cannot convert
+func (u UINT) TryFloat64() (float64, error) {
+	return tryFloat64(u)
+}
+
+// TryString returns gotype UINT as string, or a ConversionError if UINT cannot convert
+func (u UINT) TryString() (string, error) {
+	return tryString(u)
+}
+
+// TryBytes returns gotype UINT as []byte, or a ConversionError if UINT cannot convert
+func (u UINT) TryBytes() ([]byte, error) {
+	return tryBytes(u)
+}
+
+// TryTime returns gotype UINT as TIME, or a ConversionError if UINT cannot convert
+func (u UINT) TryTime() (TIME, error) {
+	return tryTime(u)
+}
+
+// TryUUID returns gotype UINT as UUID, or a ConversionError if UINT cannot convert
+func (u UINT) TryUUID() (UUID, error) {
+	return tryUUID(u)
+}
+
+// CanBool evaluates whether gotype UINT can convert to bool, see TryBool
+func (u UINT) CanBool() bool {
+	return can(u.TryBool())
+}
+
+// CanInt evaluates whether gotype UINT can convert to int, see TryInt
+func (u UINT) CanInt() bool {
+	return can(u.TryInt())
+}
+
+// CanUint evaluates whether gotype UINT can convert to uint, see TryUint
+func (u UINT) CanUint() bool {
+	return can(u.TryUint())
+}
+
+// CanFloat64 evaluates whether gotype UINT can convert to float64, see TryFloat64
+func (u UINT) CanFloat64() bool {
+	return can(u.TryFloat64())
+}
+
+// CanString evaluates whether gotype UINT can convert to string, see TryString
+func (u UINT) CanString() bool {
+	return can(u.TryString())
+}
+
+// CanBytes evaluates whether gotype UINT can convert to []byte, see TryBytes
+func (u UINT) CanBytes() bool {
+	return can(u.TryBytes())
+}
+
+// CanTime evaluates whether gotype UINT can convert to TIME, see TryTime
+func (u UINT) CanTime() bool {
+	return can(u.TryTime())
+}
+
+// CanUUID evaluates whether gotype UINT can convert to UUID, see TryUUID
+func (u UINT) CanUUID() bool {
+	return can(u.TryUUID())
+}
+
+// ------------------------------------------------------------ /
+// UUID TRY CONVERSIONS
+// ------------------------------------------------------------ /
+
+// TryBool returns gotype UUID as bool, or a ConversionError if UUID cannot convert
+func (u UUID) TryBool() (bool, error) {
+	return tryBool(u)
+}
+
+// TryInt returns gotype UUID as int, or a ConversionError if UUID cannot convert
+func (u UUID) TryInt() (int, error) {
+	return tryInt(u)
+}
+
+// TryUint returns gotype UUID as uint, or a ConversionError if UUID cannot convert
+func (u UUID) TryUint() (uint, error) {
+	return tryUint(u)
+}
+
+// TryFloat64 returns gotype UUID as float64, or a ConversionError if UUID cannot convert
+func (u UUID) TryFloat64() (float64, error) {
+	return tryFloat64(u)
+}
+
+// TryString returns gotype UUID as string, or a ConversionError if UUID cannot convert
+func (u UUID) TryString() (string, error) {
+	return tryString(u)
+}
+
+// TryBytes returns gotype UUID as []byte, or a ConversionError if UUID cannot convert
+func (u UUID) TryBytes() ([]byte, error) {
+	return tryBytes(u)
+}
+
+// TryTime returns gotype UUID as TIME, or a ConversionError if UUID cannot convert
+func (u UUID) TryTime() (TIME, error) {
+	return tryTime(u)
+}
+
+// TryUUID returns gotype UUID as UUID, or a ConversionError if UUID cannot convert
+func (u UUID) TryUUID() (UUID, error) {
+	return tryUUID(u)
+}
+
+// CanBool evaluates whether gotype UUID can convert to bool, see TryBool
+func (u UUID) CanBool() bool {
+	return can(u.TryBool())
+}
+
+// CanInt evaluates whether gotype UUID can convert to int, see TryInt
+func (u UUID) CanInt() bool {
+	return can(u.TryInt())
+}
+
+// CanUint evaluates whether gotype UUID can convert to uint, see TryUint
+func (u UUID) CanUint() bool {
+	return can(u.TryUint())
+}
+
+// CanFloat64 evaluates whether gotype UUID can convert to float64, see TryFloat64
+func (u UUID) CanFloat64() bool {
+	return can(u.TryFloat64())
+}
+
+// CanString evaluates whether gotype UUID can convert to string, see TryString
+func (u UUID) CanString() bool {
+	return can(u.TryString())
+}
+
+// CanBytes evaluates whether gotype UUID can convert to []byte, see TryBytes
+func (u UUID) CanBytes() bool {
+	return can(u.TryBytes())
+}
+
+// CanTime evaluates whether gotype UUID can convert to TIME, see TryTime
+func (u UUID) CanTime() bool {
+	return can(u.TryTime())
+}
+
+// CanUUID evaluates whether gotype UUID can convert to UUID, see TryUUID
+func (u UUID) CanUUID() bool {
+	return can(u.TryUUID())
+}
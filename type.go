@@ -24,7 +24,8 @@ const (
 	flagIndir       flag = 1 << 7
 	flagAddr        flag = 1 << 8
 
-	tflagUncommon tflag = 1 << 0
+	tflagUncommon    tflag = 1 << 0
+	tflagDirectIface tflag = 1 << 5
 )
 
 var (
@@ -99,12 +100,13 @@ func (t *TYPE) Reflect() reflect.Type {
 
 // PtrType returns a new TYPE of a pointer to the TYPE
 func (t *TYPE) PtrType() *TYPE {
-	return FromReflectType(reflect.PtrTo(toType(t)))
+	return t.ptrType()
 }
 
-// IfaceIndir returns true if the TYPE is an indirect value
+// IfaceIndir returns true if the TYPE is an indirect value,
+// direct types are flagged in kind by older runtimes and in tflag by newer
 func (t *TYPE) IfaceIndir() bool {
-	return t.kind&KindDirectIface == 0
+	return t.kind&KindDirectIface == 0 && t.tflag&tflagDirectIface == 0
 }
 
 // flag returns the flag of the TYPE
@@ -261,6 +263,72 @@ func (t *TYPE) InTypes(types ...*TYPE) bool {
 	return false
 }
 
+// ------------------------------------------------------------ /
+// TYPE CONSTRUCTORS
+// construction of new types at runtime, such as structs
+// built from schemas, usable as any type compiled in golang
+// referenced packages: reflect
+// ------------------------------------------------------------ /
+
+// FieldSpec describes a field of a struct TYPE constructed by StructTypeOf
+type FieldSpec struct {
+	Name     string // exported name of the field
+	Type     *TYPE  // type of the field
+	Tag      string // tag of the field, such as `json:"name"`
+	Embedded bool   // true if the field is embedded
+}
+
+// StructTypeOf returns the struct TYPE with fields in the order provided,
+// panics if a field is unnamed, unexported, duplicated or has no type
+func StructTypeOf(fields []FieldSpec) *TYPE {
+	fs := make([]reflect.StructField, len(fields))
+	for i, f := range fields {
+		if f.Type == nil {
+			panic("cannot construct struct field '" + f.Name + "' of nil type")
+		}
+		fs[i] = reflect.StructField{
+			Name:      f.Name,
+			Type:      toType(f.Type),
+			Tag:       reflect.StructTag(f.Tag),
+			Anonymous: f.Embedded,
+		}
+	}
+	return FromReflectType(reflect.StructOf(fs))
+}
+
+// SliceTypeOf returns the TYPE of a slice of elem
+func SliceTypeOf(elem *TYPE) *TYPE {
+	if elem == nil {
+		panic("cannot construct slice of nil type")
+	}
+	return FromReflectType(reflect.SliceOf(toType(elem)))
+}
+
+// ArrayTypeOf returns the TYPE of an array of len elem
+func ArrayTypeOf(len int, elem *TYPE) *TYPE {
+	if elem == nil {
+		panic("cannot construct array of nil type")
+	}
+	return FromReflectType(reflect.ArrayOf(len, toType(elem)))
+}
+
+// MapTypeOf returns the TYPE of a map of key to elem,
+// panics if key is not a comparable type
+func MapTypeOf(key, elem *TYPE) *TYPE {
+	if key == nil || elem == nil {
+		panic("cannot construct map of nil type")
+	}
+	return FromReflectType(reflect.MapOf(toType(key), toType(elem)))
+}
+
+// PtrTypeOf returns the TYPE of a pointer to elem
+func PtrTypeOf(elem *TYPE) *TYPE {
+	if elem == nil {
+		panic("cannot construct pointer to nil type")
+	}
+	return elem.PtrType()
+}
+
 // ------------------------------------------------------------ /
 // STURCTURED TYPES
 // implementation of golang types for data structures:
@@ -280,32 +348,17 @@ type funcType struct {
 	outCount uint16
 }
 
+// mapType includes only the fields common to all
+// runtime map implementations, the layout of the
+// remaining fields is version dependant and not accessed
 type mapType struct {
 	TYPE
-	key    *TYPE // map key type
-	elem   *TYPE // map element (value) type
-	bucket *TYPE // internal bucket structure
-	// function for hashing keys (ptr to key, seed) -> hash
-	hasher     func(unsafe.Pointer, uintptr) uintptr
-	keysize    uint8  // size of key slot
-	valuesize  uint8  // size of value slot
-	bucketsize uint16 // size of bucket
-	flags      uint32
+	key  *TYPE // map key type
+	elem *TYPE // map element (value) type
 }
 
-type bmap struct {
-	_ [bucketCnt]uint8
-}
-
-const (
-	bucketCntBits = 3
-	bucketCnt     = 1 << bucketCntBits
-	dataOffset    = unsafe.Offsetof(struct {
-		b bmap
-		v int64
-	}{}.v)
-)
-
+// hiter is the map iterator used by mapiterinit and mapiternext,
+// its size and pointer layout are kept stable by the runtime
 type hiter struct {
 	_ unsafe.Pointer    // key
 	_ unsafe.Pointer    // elem
@@ -477,18 +530,16 @@ func (t *TYPE) FieldTagValue(name string, tag string) string {
 }
 
 // TagValues returns a slice of string values for tag across fields in a struct TYPE
+// values are empty from the first field without the tag, where has is false
 func (t *TYPE) TagValues(tag string) (vals []string, has bool) {
-	fs := (*structType)(unsafe.Pointer(t)).fields
-	vals = make([]string, len(fs))
-	has = true
-	for i, f := range fs {
-		vals[i] = getTagValue(f.name.tag(), tag, 34)
-		if vals[i] == "" {
-			has = false
+	tm := t.tagValues(tag)
+	vals = make([]string, len(tm.vals))
+	for i, v := range tm.vals {
+		if vals[i] = v; v == "" {
 			break
 		}
 	}
-	return
+	return vals, tm.has
 }
 
 // ForFields iterates over the fields of a struct TYPE and calls
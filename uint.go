@@ -55,6 +55,8 @@ func (v VALUE) Uint() uint {
 			return uint(*(*uint32)(v.ptr))
 		case Uint64:
 			return uint(*(*uint64)(v.ptr))
+		case Uintptr:
+			return uint(*(*uintptr)(v.ptr))
 		case Float32:
 			return FLOAT(*(*float32)(v.ptr)).Uint()
 		case Float64:
@@ -166,7 +168,7 @@ func (u UINT) Int32() int32 {
 
 // Int64 returns gotype UINT as int64
 func (u UINT) Int64() int64 {
-	if u > math.MaxInt {
+	if u > math.MaxInt64 {
 		panic("overflow error: Uint greater than max int64")
 	}
 	return int64(u)
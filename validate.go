@@ -0,0 +1,347 @@
+// Copyright 2023 james dotter. All rights reserved.
+// Use of this source code is governed by a
+// license that can be found in the gotype LICENSE file.
+
+package gotype
+
+import (
+	"errors"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// ------------------------------------------------------------ /
+// VALIDATION IMPLEMENTATION
+// validation of struct fields using rules in the validate tag
+// eg. `validate:"required min:'1' max:'10' oneof:'a b c'"`
+// ------------------------------------------------------------ /
+
+// ValidationError is a field value that failed a validation rule
+type ValidationError struct {
+	Path  string // path to the field, eg. "Users.0.Email"
+	Rule  string // the rule that failed, eg. "min"
+	Param string // the parameter of the rule, eg. "1"
+	Err   error  // the reason the rule could not be evaluated, eg. an unknown rule
+}
+
+// Error returns the ValidationError as a string
+func (e ValidationError) Error() string {
+	s := e.Path + ": failed '" + e.Rule + "'"
+	if e.Param != "" {
+		s = e.Path + ": failed '" + e.Rule + "=" + e.Param + "'"
+	}
+	if e.Err != nil {
+		s += ": " + e.Err.Error()
+	}
+	return s
+}
+
+// Unwrap returns the reason the rule could not be evaluated
+func (e ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationErrors is a list of all the validation rules
+// failed by the fields of a struct
+type ValidationErrors []ValidationError
+
+// Error returns the ValidationErrors as a string
+func (e ValidationErrors) Error() string {
+	s := make([]string, len(e))
+	for i, v := range e {
+		s[i] = v.Error()
+	}
+	return strings.Join(s, "; ")
+}
+
+// Validate validates the struct or pointer to struct v using
+// the rules in the validate tags of the struct fields, returns
+// nil if valid or ValidationErrors listing each failed rule
+func Validate(v any) error {
+	if e := ValueOfV(v).STRUCT().Validate(); len(e) > 0 {
+		return e
+	}
+	return nil
+}
+
+// Validate returns the validation rules failed by the fields of STRUCT,
+// nested structs, slices and maps are validated recursively.
+// Rules are set in the validate tag of each field:
+//
+//	required                 value is not zero
+//	omitempty                skip other rules if value is zero
+//	min:'n', max:'n'         min or max of numbers or length of strings, slices and maps
+//	len:'n'                  length of strings, slices and maps
+//	regex:'expr'             string matches regular expression
+//	oneof:'a b c'            value is one of the space separated values
+//	email, uuid, url         string is formatted as an email, uuid or url
+//	eqfield:'F', nefield:'F' value is equal or not equal to field F
+//	gtfield:'F', gtefield:'F', ltfield:'F', ltefield:'F'
+//	                         value is greater or less than field F
+//	-                        skip the field
+//
+// Pointer fields are validated by the value they point to, nil pointers
+// are only checked by required. A rule that cannot be evaluated, such as
+// an unknown rule or a min on a bool, is returned with the reason in Err.
+func (s STRUCT) Validate() (errs ValidationErrors) {
+	validateStruct(s, "", &errs)
+	return
+}
+
+// validateStruct appends the validation errors of each field in STRUCT s to errs
+func validateStruct(s STRUCT, path string, errs *ValidationErrors) {
+	s.ForFields(true, func(i int, f FIELD) (brake bool) {
+		if !f.Visible() {
+			return
+		}
+		p := validatePath(path, f.name)
+		v := f.VALUE().SetType()
+		r := &s.typ.validateRules()[i]
+		if r.skip {
+			return
+		}
+		if len(r.rules) > 0 && !validateField(s, v, r, p, errs) {
+			return
+		}
+		validateValue(v, p, errs)
+		return
+	})
+}
+
+// fieldRules is the cached rules in the validate tag of a struct field
+type fieldRules struct {
+	skip  bool              // true if the field is skipped with "-"
+	rules map[string]string // rules and their params
+	names []string          // rule names in sorted order
+	regex *regexp.Regexp    // compiled param of the regex rule
+	err   error             // error compiling the param of the regex rule
+}
+
+// validateRules returns the cached rules in the validate tags
+// of the fields of a struct TYPE, parsed with FIELD.SubTags
+func (t *TYPE) validateRules() []fieldRules {
+	m := t.meta()
+	if r := m.rules.Load(); r != nil {
+		return *r
+	}
+	fs := t.fieldMetas()
+	r := make([]fieldRules, len(fs))
+	for i, fm := range fs {
+		if fm.rawtag == "" {
+			continue
+		}
+		f := FIELD{rawtag: fm.rawtag}
+		if f.Tag("validate") == "-" {
+			r[i].skip = true
+			continue
+		}
+		rules := f.SubTags("validate")
+		r[i].rules = rules
+		for rule := range rules {
+			r[i].names = append(r[i].names, rule)
+		}
+		sort.Strings(r[i].names)
+		if expr, ok := rules["regex"]; ok {
+			r[i].regex, r[i].err = regexp.Compile(expr)
+		}
+	}
+	m.rules.Store(&r)
+	return r
+}
+
+// validateValue validates the structs nested in VALUE v
+func validateValue(v VALUE, path string, errs *ValidationErrors) {
+	if v.typ == nil || v.IsNil() {
+		return
+	}
+	switch v.KIND() {
+	case Pointer:
+		validateValue(v.Elem().SetType(), path, errs)
+	case Struct:
+		validateStruct((STRUCT)(v), path, errs)
+	case Slice, Array:
+		for i := 0; i < v.Len(); i++ {
+			validateValue(v.Index(i), validatePath(path, strconv.Itoa(i)), errs)
+		}
+	case Map:
+		(MAP)(v).ForEach(func(i int, k string, e VALUE) (brake bool) {
+			validateValue(e, validatePath(path, k), errs)
+			return
+		})
+	}
+}
+
+// validateField appends the validation rules failed by field value v to errs,
+// returns false if the field value is empty and should not be validated further
+func validateField(s STRUCT, v VALUE, r *fieldRules, path string, errs *ValidationErrors) bool {
+	zero := v.typ == nil || v.IsZero()
+	if _, ok := r.rules["required"]; ok && zero {
+		*errs = append(*errs, ValidationError{path, "required", "", nil})
+		return false
+	}
+	if _, ok := r.rules["omitempty"]; ok && zero {
+		return false
+	}
+	if v = validateElem(v); v.typ == nil {
+		return true
+	}
+	for _, rule := range r.names {
+		if ok, err := validateRule(s, v, r, rule, r.rules[rule]); !ok {
+			*errs = append(*errs, ValidationError{path, rule, r.rules[rule], err})
+		}
+	}
+	return true
+}
+
+// validateElem returns the value pointed to by VALUE v,
+// or a VALUE with a nil type if v is a nil pointer
+func validateElem(v VALUE) VALUE {
+	for v.typ != nil && v.KIND() == Pointer {
+		if v.IsNil() {
+			return VALUE{}
+		}
+		v = v.Elem().SetType()
+	}
+	return v
+}
+
+// validateRule evaluates whether VALUE v passes the rule provided of
+// the field rules r, returns an error if the rule cannot be evaluated for v
+func validateRule(s STRUCT, v VALUE, r *fieldRules, rule, param string) (bool, error) {
+	switch rule {
+	case "required", "omitempty":
+		return true, nil
+	case "min", "max", "len":
+		n, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return false, err
+		}
+		size, err := validateSize(v)
+		if err != nil {
+			return false, err
+		}
+		switch rule {
+		case "min":
+			return size >= n, nil
+		case "max":
+			return size <= n, nil
+		}
+		return size == n, nil
+	case "regex":
+		if r.err != nil {
+			return false, r.err
+		}
+		return r.regex.MatchString(v.String()), nil
+	case "oneof":
+		str := v.String()
+		for _, o := range strings.Fields(param) {
+			if o == str {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "email":
+		a, err := mail.ParseAddress(v.String())
+		return err == nil && a.Address == v.String(), nil
+	case "uuid":
+		if v.KIND() == Uuid {
+			return true, nil
+		}
+		_, err := uuid.Parse(v.String())
+		return err == nil, nil
+	case "url":
+		u, err := url.ParseRequestURI(v.String())
+		return err == nil && u.Scheme != "" && u.Host != "", nil
+	case "eqfield", "nefield", "gtfield", "gtefield", "ltfield", "ltefield":
+		f, ok := validateFieldByName(s, param)
+		if !ok {
+			return false, errors.New("struct field '" + param + "' does not exist")
+		}
+		if f = validateElem(f); f.typ == nil {
+			return rule == "nefield", nil
+		}
+		c, err := validateCompare(v, f)
+		if err != nil {
+			return false, err
+		}
+		switch rule {
+		case "eqfield":
+			return c == 0, nil
+		case "nefield":
+			return c != 0, nil
+		case "gtfield":
+			return c > 0, nil
+		case "gtefield":
+			return c >= 0, nil
+		case "ltfield":
+			return c < 0, nil
+		}
+		return c <= 0, nil
+	}
+	return false, errors.New("unknown validation rule")
+}
+
+// validateFieldByName returns the value of the field named n in STRUCT s
+func validateFieldByName(s STRUCT, n string) (v VALUE, ok bool) {
+	s.ForFields(false, func(i int, f FIELD) (brake bool) {
+		if f.name_.name() == n {
+			v, ok = f.VALUE().SetType(), true
+			return true
+		}
+		return
+	})
+	return
+}
+
+// validateSize returns the value of numbers
+// or the length of strings, slices and maps in VALUE v
+func validateSize(v VALUE) (float64, error) {
+	switch k := v.KIND(); {
+	case k.IsNumeric():
+		return v.Float64(), nil
+	case k == String:
+		return float64(utf8.RuneCountInString(v.String())), nil
+	case k == Slice, k == Array, k == Map, k == Bytes:
+		return float64(v.Len()), nil
+	}
+	return 0, errors.New("cannot validate size of " + v.typ.String())
+}
+
+// validateCompare returns -1, 0 or 1 if VALUE a is
+// less than, equal to or greater than VALUE b,
+// where false is less than true
+func validateCompare(a, b VALUE) (int, error) {
+	ak, bk := a.KIND(), b.KIND()
+	switch {
+	case ak.IsNumeric() && bk.IsNumeric():
+		af, bf := a.Float64(), b.Float64()
+		switch {
+		case af < bf:
+			return -1, nil
+		case af > bf:
+			return 1, nil
+		}
+		return 0, nil
+	case ak == Time && bk == Time:
+		return a.TIME().Time().Compare(b.TIME().Time()), nil
+	case ak == String && bk == String:
+		return strings.Compare(a.String(), b.String()), nil
+	case ak == Bool && bk == Bool:
+		return a.INT().Native() - b.INT().Native(), nil
+	}
+	return 0, errors.New("cannot compare " + a.typ.String() + " with " + b.typ.String())
+}
+
+func validatePath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -22,9 +22,14 @@ type VALUE struct {
 	flag
 }
 
+// ValueOf returns the VALUE of a, without
+// the use of reflect.ValueOf
 func ValueOf(a any) VALUE {
-	v := reflect.ValueOf(a)
-	return FromReflect(v)
+	e := (*VALUE)(unsafe.Pointer(&a))
+	if e.typ == nil {
+		return VALUE{}
+	}
+	return VALUE{e.typ, e.ptr, e.typ.flag()}
 }
 
 func ValueOfV(a any) VALUE {
@@ -61,20 +66,30 @@ func (v VALUE) Init() VALUE {
 
 // SetType sets the actual data type of interface VALUE
 func (v VALUE) SetType() VALUE {
-	if v.Kind() == Interface {
-		var e *VALUE
-		if (*interfaceType)(unsafe.Pointer(v.typ)).NumMethod() != 0 {
-			e = (*VALUE)(unsafe.Pointer((*interface{ M() })(v.ptr)))
-		} else {
-			e = (*VALUE)(v.ptr)
-		}
-		if e.typ != nil && e.typ.kind != 0 {
-			return VALUE{e.typ, e.ptr, e.typ.flag()}
+	if v.typ != nil && v.Kind() == Interface {
+		if e := v.iface(); e.typ != nil {
+			return e
 		}
 	}
 	return v
 }
 
+// iface returns the VALUE held by the interface VALUE v,
+// returns an empty VALUE if the interface is nil
+func (v VALUE) iface() VALUE {
+	var t *TYPE
+	if (*interfaceType)(unsafe.Pointer(v.typ)).NumMethod() == 0 {
+		t = *(**TYPE)(v.ptr)
+	} else if tab := *(*unsafe.Pointer)(v.ptr); tab != nil {
+		// itab: interface type, then concrete type
+		t = *(**TYPE)(offset(tab, ptrSize))
+	}
+	if t == nil || t.kind == 0 {
+		return VALUE{}
+	}
+	return VALUE{t, *(*unsafe.Pointer)(offset(v.ptr, ptrSize)), t.flag()}
+}
+
 // New returns a new empty value of VALUE type,
 // if ptr is nil and init != true, returns nil ptr
 func (v VALUE) New(init ...bool) VALUE {
@@ -190,6 +205,16 @@ func (v VALUE) Pointer() unsafe.Pointer {
 	return v.ptr
 }
 
+// indirect returns a pointer to the data of VALUE,
+// values stored directly in the VALUE pointer are boxed
+func (v VALUE) indirect() unsafe.Pointer {
+	if v.flag&flagIndir == 0 {
+		p := v.ptr
+		return unsafe.Pointer(&p)
+	}
+	return v.ptr
+}
+
 // PointerTo returns a pointer to the underlying value,
 // if the value is a string, returns *string as VALUE
 func (v VALUE) PointerTo() VALUE {
@@ -224,9 +249,30 @@ func (v VALUE) Len() int {
 	panic("cannot call Len on type " + v.Kind().String())
 }
 
-// Elem returns the underlying value of a pointer
+// Elem returns the underlying value of a pointer or interface,
+// returns an empty VALUE if the pointer or interface is nil
 func (v VALUE) Elem() VALUE {
-	return FromReflect(v.Reflect().Elem())
+	if v.typ != nil {
+		switch v.Kind() {
+		case Interface:
+			e := v.iface()
+			if e.typ != nil && v.flag&(flagStickyRO|flagEmbedRO) != 0 {
+				e.flag |= flagStickyRO
+			}
+			return e
+		case Pointer:
+			p := v.ptr
+			if v.flag&flagIndir != 0 {
+				p = *(*unsafe.Pointer)(p)
+			}
+			if p == nil {
+				return VALUE{}
+			}
+			t := (*ptrType)(unsafe.Pointer(v.typ)).elem
+			return VALUE{t, p, v.flag&(flagStickyRO|flagEmbedRO) | flagIndir | flagAddr | flag(t.Kind())}
+		}
+	}
+	panic("call to Elem on non pointer or interface value")
 }
 
 // ElemDeep cascades a series of pointers to return the underlying VALUE
@@ -324,7 +370,7 @@ func (v VALUE) SetIndex(key any, val any) VALUE {
 	case Pointer:
 		v.ElemDeep().SetIndex(key, val)
 	case Map:
-		(MAP)(v).Set(k.String(), val)
+		(MAP)(v).Set(k, val)
 	case Slice:
 		return (SLICE)(v).Set(k.Int(), val).VALUE()
 	case String:
@@ -344,10 +390,15 @@ func (v VALUE) SetIndex(key any, val any) VALUE {
 	return v
 }
 
-// Set updates the VALUE to a and returns VALUE
+// Set updates the VALUE to a and returns VALUE,
+// numbers are converted using the DefaultConversion policy
 func (v VALUE) Set(a any) VALUE {
-	v = v.SetType()
-	n := ValueOfV(a).SetType()
+	return v.SetType().set(ValueOfV(a), ConvertDefault.policy())
+}
+
+// set updates the VALUE to n, converting numbers using policy p
+func (v VALUE) set(n VALUE, p ConversionPolicy) VALUE {
+	n = n.SetType()
 	switch {
 	case v.typ == n.typ:
 		return v.setMatched(n)
@@ -355,7 +406,7 @@ func (v VALUE) Set(a any) VALUE {
 		v.Elem().setMatched(n)
 		return v
 	default:
-		return v.setUnmatched(n)
+		return v.setUnmatched(n, p)
 	}
 }
 
@@ -369,12 +420,27 @@ func (v VALUE) setMatched(n VALUE) VALUE {
 		*(*[4]byte)(v.ptr) = *(*[4]byte)(n.ptr)
 	case Int, Int64, Uint, Uint64, Uintptr, Float64, Complex64:
 		*(*[8]byte)(v.ptr) = *(*[8]byte)(n.ptr)
+	case Complex128:
+		*(*[16]byte)(v.ptr) = *(*[16]byte)(n.ptr)
 	case Interface:
 		*(*any)(v.ptr) = *(*any)(n.ptr)
-	case Map: // hmap header size
-		*(*[48]byte)(v.Pointer()) = *(*[48]byte)(n.Pointer())
+	case Map:
+		if v.flag&flagIndir != 0 {
+			*(*unsafe.Pointer)(v.ptr) = n.Pointer()
+		} else if d, s := v.Pointer(), n.Pointer(); d != s {
+			// map held directly in VALUE, replace its contents
+			mapclear(v.typ, d)
+			(MAP)(n).ForEachKey(func(i int, k, e VALUE) (brake bool) {
+				(MAP)(v).Set(k, e)
+				return
+			})
+		}
 	case Pointer:
-		*(*unsafe.Pointer)(&v.ptr) = n.ptr
+		if v.flag&flagIndir != 0 {
+			*(*unsafe.Pointer)(v.ptr) = n.Pointer()
+		} else {
+			*(*unsafe.Pointer)(&v.ptr) = n.ptr
+		}
 	case Slice: // slice header size
 		*(*[24]byte)(v.ptr) = *(*[24]byte)(n.ptr)
 	case String: // string header size
@@ -385,7 +451,13 @@ func (v VALUE) setMatched(n VALUE) VALUE {
 	return v
 }
 
-func (v VALUE) setUnmatched(n VALUE) VALUE {
+func (v VALUE) setUnmatched(n VALUE, p ConversionPolicy) VALUE {
+	if k := v.KIND(); k.IsNumeric() || k == Uintptr {
+		if err := convertNumber(v, n, p); err != nil {
+			panic(err)
+		}
+		return v
+	}
 	switch v.KIND() {
 	case Bool:
 		*(*bool)(v.ptr) = n.Bool()
@@ -413,6 +485,10 @@ func (v VALUE) setUnmatched(n VALUE) VALUE {
 		*(*float32)(v.ptr) = n.FLOAT().Float32()
 	case Float64:
 		*(*float64)(v.ptr) = n.Float64()
+	case Complex64:
+		*(*complex64)(v.ptr) = n.Complex64()
+	case Complex128:
+		*(*complex128)(v.ptr) = n.Complex128()
 	case Array, Map, Slice:
 		// must have identical type match
 		panic("type mismatch on set value")
@@ -421,7 +497,7 @@ func (v VALUE) setUnmatched(n VALUE) VALUE {
 		if v.typ == n.typ {
 			v.setMatched(n)
 		} else {
-			v.setUnmatched(n)
+			v.setUnmatched(n, p)
 		}
 	case Interface:
 		*(*any)(v.ptr) = n.Interface()
@@ -474,7 +550,7 @@ func (v VALUE) IsZero() bool {
 	if v.IsNil() {
 		return true
 	}
-	switch v.Kind() {
+	switch v.KIND() {
 	case Bool:
 		return !*(*bool)(v.ptr)
 	case Int:
@@ -552,8 +628,10 @@ func (v VALUE) IsNil() bool {
 	switch v.Kind() {
 	case Pointer:
 		return v.Elem().IsNil()
-	case Slice, Interface, UnsafePointer, Map:
+	case Slice, Interface:
 		return *(*unsafe.Pointer)(v.ptr) == nil
+	case UnsafePointer, Map:
+		return v.Pointer() == nil
 	}
 	return false
 }
@@ -563,12 +641,32 @@ func (v VALUE) IsNil() bool {
 // implementation of functions to convert values to new types
 // ------------------------------------------------------------ /
 
+// Cast returns VALUE cast to KIND k, numbers are
+// converted using the DefaultConversion policy
 func (v VALUE) Cast(k KIND) any {
+	return v.cast(k, ConvertDefault.policy())
+}
+
+// cast returns VALUE cast to KIND k, converting numbers using policy p
+func (v VALUE) cast(k KIND, p ConversionPolicy) any {
 	vk := v.typ.KIND()
 	if vk == k {
 		return v.Interface()
 	}
-	if !vk.IsBasic() || !k.IsBasic() {
+	if k.IsNumeric() || k == Uintptr {
+		d := k.NewValue().Elem()
+		if err := convertNumber(d, v, p); err != nil {
+			panic(err)
+		}
+		return d.Interface()
+	}
+	switch k {
+	case Complex64:
+		return v.Complex64()
+	case Complex128:
+		return v.Complex128()
+	}
+	if !(vk.IsBasic() || isComplexKind(vk)) || !k.IsBasic() {
 		panic("cannot convert to type")
 	}
 	switch k {
@@ -647,6 +745,8 @@ func (v VALUE) json() string {
 		return (*STRING)(v.ptr).json()
 	case Bytes:
 		return (*BYTES)(v.ptr).STRING().json()
+	case Complex64, Complex128:
+		return STRING(v.String()).json()
 	case Array:
 		return (ARRAY)(v).json(ancestor{v.typ, v.Uintptr()})
 	case Map:
@@ -678,7 +778,7 @@ func (v VALUE) jsonSafe(ancestry ...ancestor) (s string, recursive bool) {
 		return "null", false
 	}
 	k := v.KIND()
-	if k.IsBasic() {
+	if k.IsBasic() || isComplexKind(k) {
 		return v.json(), false
 	}
 	if k != Array && k != Struct && v.Pointer() == nil {
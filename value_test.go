@@ -5,10 +5,25 @@
 package gotype
 
 import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
 	"math"
+	"net/url"
+	"os"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	test "github.com/jcdotter/gtest"
 )
 
@@ -222,7 +237,7 @@ func TestValueMarshalJson(t *testing.T) {
 	gt.Equal(`["s"]`, ValueOf(&pa1).Marshal(JsonMarshaler).String(), "*array(1)")
 	gt.Equal(`{"V1":"s"}`, ValueOf(&pd1).Marshal(JsonMarshaler).String(), "*struct(1)")
 
-	ms := JsonMarshaler
+	ms := JsonMarshaler.New()
 	ms.UnmarshalTyped = true
 	ms.Format = true
 	ms.Init()
@@ -233,6 +248,17 @@ func TestValueMarshalJson(t *testing.T) {
 		gt.NotEqual("", js, n)
 		gt.Equal(js, cjs, n)
 	}
+
+	// IsZero reads the gotype kind, so zero UUID, TIME and
+	// bytes fields are excluded along with other zero values
+	type zeros struct {
+		ID UUID
+		At TIME
+		B  []byte
+		N  int
+	}
+	gt.Equal(`{"N":1}`, ValueOf(zeros{N: 1}).Marshal(JsonMarshaler).String(), "zero uuid, time and bytes")
+	gt.Equal(`{"ID":"01000000-0000-0000-0000-000000000000","N":1}`, ValueOf(zeros{ID: UUID{1}, N: 1}).Marshal(JsonMarshaler).String(), "non zero uuid")
 }
 
 func TestValueMarshalPrint(t *testing.T) {
@@ -274,15 +300,15 @@ func TestValueSetTyped(t *testing.T) {
 		d1 = string_struct_single{"updated"}
 	)
 
-	gt.Equal(b, ValueOf(vars["bool"]).Set(b).Interface(), "bool", "bool")
-	gt.Equal(i, ValueOf(vars["int"]).Set(i).Interface(), "int", "int")
-	gt.Equal(s, ValueOf(vars["string"]).Set(s).Interface(), "string", "string")
-	gt.Equal(a, ValueOf(vars["[2]string"]).Set(a).Interface(), "array", "array")
-	gt.Equal(l, ValueOf(vars["[]string{2}"]).Set(l).Interface(), "slice", "slice")
-	gt.Equal(m, ValueOf(vars["map[string]string{2}"]).Set(m).Interface(), "map", "map")
-	gt.Equal(d, ValueOf(vars["struct(string){2}"]).Set(d).Interface(), "struct", "struct")
-	gt.Equal(a1, ValueOf(vars["[1]string"]).Set(a1).Interface(), "array(1)", "array(1)")
-	gt.Equal(d1, ValueOf(vars["struct(string){1}"]).Set(d1).Interface(), "struct(1)", "struct(1)")
+	gt.Equal(b, settable(vars["bool"]).Set(b).Interface(), "bool", "bool")
+	gt.Equal(i, settable(vars["int"]).Set(i).Interface(), "int", "int")
+	gt.Equal(s, settable(vars["string"]).Set(s).Interface(), "string", "string")
+	gt.Equal(a, settable(vars["[2]string"]).Set(a).Interface(), "array", "array")
+	gt.Equal(l, settable(vars["[]string{2}"]).Set(l).Interface(), "slice", "slice")
+	gt.Equal(m, settable(vars["map[string]string{2}"]).Set(m).Interface(), "map", "map")
+	gt.Equal(d, settable(vars["struct(string){2}"]).Set(d).Interface(), "struct", "struct")
+	gt.Equal(a1, settable(vars["[1]string"]).Set(a1).Interface(), "array(1)", "array(1)")
+	gt.Equal(d1, settable(vars["struct(string){1}"]).Set(d1).Interface(), "struct(1)", "struct(1)")
 
 }
 
@@ -341,6 +367,23 @@ func TestValueSetPtrVal(t *testing.T) {
 
 }
 
+func TestValueSetPointerField(t *testing.T) {
+	gt := test.New(t, config)
+	gt.Msg = "Testing VALUE.Set(%s)"
+	type ptrs struct {
+		I *int
+		S []*string
+	}
+	i, s := 8, "updated"
+	p := &ptrs{S: make([]*string, 2)}
+	ValueOf(p).Elem().STRUCT().Set(0, &i)
+	gt.True(p.I == &i, "struct field")
+	ValueOf(p.S).SLICE().Set(1, &s)
+	gt.True(p.S[0] == nil && p.S[1] == &s, "slice elem")
+	ValueOf(p).Elem().STRUCT().Set(0, (*int)(nil))
+	gt.True(p.I == nil, "nil pointer")
+}
+
 func TestValueSetUntyped(t *testing.T) {
 	gt := test.New(t, config)
 	gt.Msg = "Testing ValueOf(%s).Set(%s)"
@@ -427,83 +470,83 @@ func TestValueSetUntyped(t *testing.T) {
 	)
 
 	// Test return values of Set()
-	gt.Equal(u_bytes_bool, ValueOf(o_bytes_bool).Set(u_bytes_bool).Interface(), "bytes", "bytes")
-	gt.Equal(u_bytes_int, ValueOf(o_bytes_int).Set(u_int).Interface(), "bytes", "int")
-	gt.Equal(u_bytes_uint, ValueOf(o_bytes_uint).Set(u_uint).Interface(), "bytes", "uint")
-	gt.Equal(u_bytes_float, ValueOf(o_bytes_float).Set(u_float).Interface(), "bytes", "float")
-	gt.Equal(u_bytes_array, ValueOf(o_bytes_array).Set(u_array).Interface(), "bytes", "array")
-	gt.Equal(u_bytes_slice, ValueOf(o_bytes_slice).Set(u_slice).Interface(), "bytes", "slice")
-	gt.Equal(u_bytes_string, ValueOf(o_bytes_string).Set(u_str_bool).Interface(), "bytes", "string")
-	gt.Equal(u_bytes_map, ValueOf(o_bytes_map).Set(u_map).Interface(), "bytes", "map")
-	gt.Equal(u_bytes_struct, ValueOf(o_bytes_struct).Set(u_struct).Interface(), "bytes", "struct")
-	gt.Equal(u_bytes_time, ValueOf(o_bytes_time).Set(u_time).Interface(), "bytes", "time")
-	gt.Equal(u_bytes_uuid, ValueOf(o_bytes_uuid).Set(u_uuid).Interface(), "bytes", "uuid")
-
-	gt.Equal(u_bool, ValueOf(o_bool).Set(u_bytes_bool).Interface(), "bool", "bytes")
-	gt.Equal(u_bool, ValueOf(o_bool).Set(u_bool).Interface(), "bool", "bool")
-	gt.Equal(u_bool, ValueOf(o_bool).Set(u_int).Interface(), "bool", "int")
-	gt.Equal(u_bool, ValueOf(o_bool).Set(u_uint).Interface(), "bool", "uint")
-	gt.Equal(u_bool, ValueOf(o_bool).Set(u_float).Interface(), "bool", "float")
-	gt.Equal(u_bool, ValueOf(o_bool).Set(u_array).Interface(), "bool", "array")
-	gt.Equal(u_bool, ValueOf(o_bool).Set(u_slice).Interface(), "bool", "slice")
-	gt.Equal(u_bool, ValueOf(o_bool).Set(u_str_bool).Interface(), "bool", "string")
-	gt.Equal(u_bool, ValueOf(o_bool).Set(u_map).Interface(), "bool", "map")
-	gt.Equal(u_bool, ValueOf(o_bool).Set(u_struct).Interface(), "bool", "struct")
-	gt.Equal(u_bool, ValueOf(o_bool).Set(u_time).Interface(), "bool", "time")
-	gt.Equal(u_bool, ValueOf(o_bool).Set(u_uuid).Interface(), "bool", "uuid")
-
-	gt.Equal(u_int, ValueOf(o_int).Set(u_bytes_int).Interface(), "int", "bytes")
-	gt.Equal(u_int_bool, ValueOf(o_int).Set(u_bool).Interface(), "int", "bool")
-	gt.Equal(u_int, ValueOf(o_int).Set(u_int).Interface(), "int", "int")
-	gt.Equal(u_int, ValueOf(o_int).Set(u_uint).Interface(), "int", "uint")
-	gt.Equal(u_int, ValueOf(o_int).Set(u_float).Interface(), "int", "float")
-	gt.Equal(u_int, ValueOf(o_int).Set(u_str_int).Interface(), "int", "string")
-	gt.Equal(u_int, ValueOf(o_int).Set(u_time).Interface(), "int", "time")
-
-	gt.Equal(u_uint, ValueOf(o_uint).Set(u_bytes_uint).Interface(), "uint", "bytes")
-	gt.Equal(u_uint_bool, ValueOf(o_uint).Set(u_bool).Interface(), "uint", "bool")
-	gt.Equal(u_uint, ValueOf(o_uint).Set(u_int).Interface(), "uint", "int")
-	gt.Equal(u_uint, ValueOf(o_uint).Set(u_uint).Interface(), "uint", "uint")
-	gt.Equal(u_uint, ValueOf(o_uint).Set(u_float).Interface(), "uint", "float")
-	gt.Equal(u_uint, ValueOf(o_uint).Set(u_str_uint).Interface(), "uint", "string")
-	gt.Equal(u_uint, ValueOf(o_uint).Set(u_time).Interface(), "uint", "time")
-
-	gt.Equal(u_float, ValueOf(o_float).Set(u_bytes_float).Interface(), "float", "bytes")
-	gt.Equal(u_float_bool, ValueOf(o_float).Set(u_bool).Interface(), "float", "bool")
-	gt.Equal(u_float, ValueOf(o_float).Set(u_int).Interface(), "float", "int")
-	gt.Equal(u_float, ValueOf(o_float).Set(u_uint).Interface(), "float", "uint")
-	gt.Equal(u_float, ValueOf(o_float).Set(u_float).Interface(), "float", "float")
-	gt.Equal(u_float, ValueOf(o_float).Set(u_str_float).Interface(), "float", "string")
-	gt.Equal(u_float, ValueOf(o_float).Set(u_time).Interface(), "float", "time")
-
-	gt.Equal(u_str_bool, ValueOf(o_str_bool).Set(u_bytes_string).Interface(), "string", "bytes")
-	gt.Equal(u_str_bool, ValueOf(o_str_bool).Set(u_bool).Interface(), "string", "bool")
-	gt.Equal(u_str_int, ValueOf(o_str_int).Set(u_int).Interface(), "string", "int")
-	gt.Equal(u_str_uint, ValueOf(o_str_uint).Set(u_uint).Interface(), "string", "uint")
-	gt.Equal(u_str_float1, ValueOf(o_str_float).Set(u_float1).Interface(), "string", "float")
-	gt.Equal(u_str_array, ValueOf(o_str_array).Set(u_array).Interface(), "string", "array")
-	gt.Equal(u_str_slice, ValueOf(o_str_slice).Set(u_slice).Interface(), "string", "slice")
-	gt.Equal(u_str_bool, ValueOf(o_str_bool).Set(u_str_bool).Interface(), "string", "string")
-	gt.Equal(u_str_map, ValueOf(o_str_map).Set(u_map).Interface(), "string", "map")
-	gt.Equal(u_str_struct, ValueOf(o_str_struct).Set(u_struct).Interface(), "string", "struct")
-	gt.Equal(u_str_time, ValueOf(o_str_time).Set(u_time_str).Interface(), "string", "time")
-	gt.Equal(u_str_uuid, ValueOf(o_str_uuid).Set(u_uuid).Interface(), "string", "uuid")
-
-	gt.Equal(u_time, ValueOf(o_time).Set(u_bytes_time).Interface(), "time", "bytes")
-	gt.Equal(u_time, ValueOf(o_time).Set(u_int).Interface(), "time", "int")
-	gt.Equal(u_time, ValueOf(o_time).Set(u_uint).Interface(), "time", "uint")
-	gt.Equal(u_time, ValueOf(o_time).Set(u_float).Interface(), "time", "float")
-	gt.Equal(u_time_str, ValueOf(o_time).Set(u_str_time).Interface(), "time", "string")
-	gt.Equal(u_time, ValueOf(o_time).Set(u_time).Interface(), "time", "time")
-
-	gt.Equal(u_uuid, ValueOf(o_uuid).Set(u_bytes_uuid).Interface(), "uuid", "bytes")
-	gt.Equal(u_uuid, ValueOf(o_uuid).Set(u_str_uuid).Interface(), "uuid", "string")
-	gt.Equal(u_uuid, ValueOf(o_uuid).Set(u_uuid).Interface(), "uuid", "uuid")
-
-	gt.Equal(u_array, ValueOf(o_array).Set(u_array).Interface(), "array", "array")
-	gt.Equal(u_slice, ValueOf(o_slice).Set(u_slice).Interface(), "slice", "slice")
-	gt.Equal(u_map, ValueOf(o_map).Set(u_map).Interface(), "map", "map")
-	gt.Equal(u_struct, ValueOf(o_struct).Set(u_struct).Interface(), "struct", "struct")
+	gt.Equal(u_bytes_bool, settable(o_bytes_bool).Set(u_bytes_bool).Interface(), "bytes", "bytes")
+	gt.Equal(u_bytes_int, settable(o_bytes_int).Set(u_int).Interface(), "bytes", "int")
+	gt.Equal(u_bytes_uint, settable(o_bytes_uint).Set(u_uint).Interface(), "bytes", "uint")
+	gt.Equal(u_bytes_float, settable(o_bytes_float).Set(u_float).Interface(), "bytes", "float")
+	gt.Equal(u_bytes_array, settable(o_bytes_array).Set(u_array).Interface(), "bytes", "array")
+	gt.Equal(u_bytes_slice, settable(o_bytes_slice).Set(u_slice).Interface(), "bytes", "slice")
+	gt.Equal(u_bytes_string, settable(o_bytes_string).Set(u_str_bool).Interface(), "bytes", "string")
+	gt.Equal(u_bytes_map, settable(o_bytes_map).Set(u_map).Interface(), "bytes", "map")
+	gt.Equal(u_bytes_struct, settable(o_bytes_struct).Set(u_struct).Interface(), "bytes", "struct")
+	gt.Equal(u_bytes_time, settable(o_bytes_time).Set(u_time).Interface(), "bytes", "time")
+	gt.Equal(u_bytes_uuid, settable(o_bytes_uuid).Set(u_uuid).Interface(), "bytes", "uuid")
+
+	gt.Equal(u_bool, settable(o_bool).Set(u_bytes_bool).Interface(), "bool", "bytes")
+	gt.Equal(u_bool, settable(o_bool).Set(u_bool).Interface(), "bool", "bool")
+	gt.Equal(u_bool, settable(o_bool).Set(u_int).Interface(), "bool", "int")
+	gt.Equal(u_bool, settable(o_bool).Set(u_uint).Interface(), "bool", "uint")
+	gt.Equal(u_bool, settable(o_bool).Set(u_float).Interface(), "bool", "float")
+	gt.Equal(u_bool, settable(o_bool).Set(u_array).Interface(), "bool", "array")
+	gt.Equal(u_bool, settable(o_bool).Set(u_slice).Interface(), "bool", "slice")
+	gt.Equal(u_bool, settable(o_bool).Set(u_str_bool).Interface(), "bool", "string")
+	gt.Equal(u_bool, settable(o_bool).Set(u_map).Interface(), "bool", "map")
+	gt.Equal(u_bool, settable(o_bool).Set(u_struct).Interface(), "bool", "struct")
+	gt.Equal(u_bool, settable(o_bool).Set(u_time).Interface(), "bool", "time")
+	gt.Equal(u_bool, settable(o_bool).Set(u_uuid).Interface(), "bool", "uuid")
+
+	gt.Equal(u_int, settable(o_int).Set(u_bytes_int).Interface(), "int", "bytes")
+	gt.Equal(u_int_bool, settable(o_int).Set(u_bool).Interface(), "int", "bool")
+	gt.Equal(u_int, settable(o_int).Set(u_int).Interface(), "int", "int")
+	gt.Equal(u_int, settable(o_int).Set(u_uint).Interface(), "int", "uint")
+	gt.Equal(u_int, settable(o_int).Set(u_float).Interface(), "int", "float")
+	gt.Equal(u_int, settable(o_int).Set(u_str_int).Interface(), "int", "string")
+	gt.Equal(u_int, settable(o_int).Set(u_time).Interface(), "int", "time")
+
+	gt.Equal(u_uint, settable(o_uint).Set(u_bytes_uint).Interface(), "uint", "bytes")
+	gt.Equal(u_uint_bool, settable(o_uint).Set(u_bool).Interface(), "uint", "bool")
+	gt.Equal(u_uint, settable(o_uint).Set(u_int).Interface(), "uint", "int")
+	gt.Equal(u_uint, settable(o_uint).Set(u_uint).Interface(), "uint", "uint")
+	gt.Equal(u_uint, settable(o_uint).Set(u_float).Interface(), "uint", "float")
+	gt.Equal(u_uint, settable(o_uint).Set(u_str_uint).Interface(), "uint", "string")
+	gt.Equal(u_uint, settable(o_uint).Set(u_time).Interface(), "uint", "time")
+
+	gt.Equal(u_float, settable(o_float).Set(u_bytes_float).Interface(), "float", "bytes")
+	gt.Equal(u_float_bool, settable(o_float).Set(u_bool).Interface(), "float", "bool")
+	gt.Equal(u_float, settable(o_float).Set(u_int).Interface(), "float", "int")
+	gt.Equal(u_float, settable(o_float).Set(u_uint).Interface(), "float", "uint")
+	gt.Equal(u_float, settable(o_float).Set(u_float).Interface(), "float", "float")
+	gt.Equal(u_float, settable(o_float).Set(u_str_float).Interface(), "float", "string")
+	gt.Equal(u_float, settable(o_float).Set(u_time).Interface(), "float", "time")
+
+	gt.Equal(u_str_bool, settable(o_str_bool).Set(u_bytes_string).Interface(), "string", "bytes")
+	gt.Equal(u_str_bool, settable(o_str_bool).Set(u_bool).Interface(), "string", "bool")
+	gt.Equal(u_str_int, settable(o_str_int).Set(u_int).Interface(), "string", "int")
+	gt.Equal(u_str_uint, settable(o_str_uint).Set(u_uint).Interface(), "string", "uint")
+	gt.Equal(u_str_float1, settable(o_str_float).Set(u_float1).Interface(), "string", "float")
+	gt.Equal(u_str_array, settable(o_str_array).Set(u_array).Interface(), "string", "array")
+	gt.Equal(u_str_slice, settable(o_str_slice).Set(u_slice).Interface(), "string", "slice")
+	gt.Equal(u_str_bool, settable(o_str_bool).Set(u_str_bool).Interface(), "string", "string")
+	gt.Equal(u_str_map, settable(o_str_map).Set(u_map).Interface(), "string", "map")
+	gt.Equal(u_str_struct, settable(o_str_struct).Set(u_struct).Interface(), "string", "struct")
+	gt.Equal(u_str_time, settable(o_str_time).Set(u_time_str).Interface(), "string", "time")
+	gt.Equal(u_str_uuid, settable(o_str_uuid).Set(u_uuid).Interface(), "string", "uuid")
+
+	gt.Equal(u_time, settable(o_time).Set(u_bytes_time).Interface(), "time", "bytes")
+	gt.Equal(u_time, settable(o_time).Set(u_int).Interface(), "time", "int")
+	gt.Equal(u_time, settable(o_time).Set(u_uint).Interface(), "time", "uint")
+	gt.Equal(u_time, settable(o_time).Set(u_float).Interface(), "time", "float")
+	gt.Equal(u_time_str, settable(o_time).Set(u_str_time).Interface(), "time", "string")
+	gt.Equal(u_time, settable(o_time).Set(u_time).Interface(), "time", "time")
+
+	gt.Equal(u_uuid, settable(o_uuid).Set(u_bytes_uuid).Interface(), "uuid", "bytes")
+	gt.Equal(u_uuid, settable(o_uuid).Set(u_str_uuid).Interface(), "uuid", "string")
+	gt.Equal(u_uuid, settable(o_uuid).Set(u_uuid).Interface(), "uuid", "uuid")
+
+	gt.Equal(u_array, settable(o_array).Set(u_array).Interface(), "array", "array")
+	gt.Equal(u_slice, settable(o_slice).Set(u_slice).Interface(), "slice", "slice")
+	gt.Equal(u_map, settable(o_map).Set(u_map).Interface(), "map", "map")
+	gt.Equal(u_struct, settable(o_struct).Set(u_struct).Interface(), "struct", "struct")
 
 	// Test pointer values after Set()
 	gt.Equal(u_bytes_bool, testSet(o_bytes_bool, u_bytes_bool), "*bytes", "bytes")
@@ -629,14 +672,20 @@ func TestValueSetIndex(t *testing.T) {
 	}
 }
 
+// settable returns an addressable copy of the value of a to be set,
+// the data of constant test vars held in interfaces may be read only
+func settable(a any) VALUE {
+	return mergeCopy(ValueOf(a))
+}
+
 func testSet(original, new any) any {
-	n := original
+	n := settable(original).Interface()
 	ValueOf(&n).Elem().Elem().Set(new)
 	return n
 }
 
 func testSetIndex(original, new any, index int) any {
-	n := original
+	n := settable(original).Interface()
 	ValueOf(&n).Elem().Elem().Index(index).Set(new)
 	return n
 }
@@ -1061,7 +1110,9 @@ func TestEncodeComplex(t *testing.T) {
 		}
 		// run test if in filter
 		if proc {
+			// set an addressable copy, constant test vars may be read only
 			sv := ValueOf(v)
+			sv = mergeCopy(sv)
 			testSetDeep(sv, false)
 			e := Encode(sv)
 			d := sv.NewDeep()
@@ -1118,3 +1169,1952 @@ func TestFuncType(t *testing.T) {
 	gt.Equal("*gotype.testStruct", TypeOf(f).In(1).String(), "TypeOf(func).In(1)")
 	gt.Equal("*gotype.testStruct", TypeOf(f).Out(0).String(), "TypeOf(func).Out(0)")
 }
+
+func TestMerge(t *testing.T) {
+	gt := test.New(t, config)
+	gt.Msg = "Testing Merge(%s)"
+
+	type item struct {
+		ID   string
+		Qty  int
+		Tags []string
+	}
+	type conf struct {
+		Name   string            `json:"name"`
+		Port   int               `json:"port"`
+		Debug  bool              `json:"debug"`
+		Hosts  []string          `json:"hosts"`
+		Extra  []string          `json:"extra" merge:"slice:'append'"`
+		Items  []item            `json:"items" merge:"slice:'key' key:'ID'"`
+		Labels map[string]string `json:"labels"`
+		Inner  *string_struct    `json:"inner"`
+		Skip   string            `json:"skip" merge:"-"`
+	}
+
+	dst := conf{
+		Name:   "app",
+		Port:   80,
+		Debug:  true,
+		Hosts:  []string{"a", "b"},
+		Extra:  []string{"x"},
+		Items:  []item{{"1", 1, nil}, {"2", 2, nil}},
+		Labels: map[string]string{"env": "dev", "team": "core"},
+		Inner:  &string_struct{"v1", "v2"},
+	}
+	src := conf{
+		Port:   8080,
+		Hosts:  []string{"c"},
+		Extra:  []string{"y"},
+		Items:  []item{{"2", 5, []string{"t"}}, {"3", 3, nil}},
+		Labels: map[string]string{"env": "prod"},
+		Inner:  &string_struct{V2: "w2"},
+		Skip:   "skip",
+	}
+	gt.Equal(nil, Merge(&dst, src), "struct")
+	gt.Equal("app", dst.Name, "struct zero kept")
+	gt.Equal(8080, dst.Port, "struct replaced")
+	gt.True(dst.Debug, "struct bool kept")
+	gt.Equal([]string{"c"}, dst.Hosts, "slice replaced")
+	gt.Equal([]string{"x", "y"}, dst.Extra, "slice appended")
+	gt.Equal([]item{{"1", 1, nil}, {"2", 5, []string{"t"}}, {"3", 3, nil}}, dst.Items, "slice by key")
+	gt.Equal(map[string]string{"env": "prod", "team": "core"}, dst.Labels, "map")
+	gt.Equal(string_struct{"v1", "w2"}, *dst.Inner, "pointer")
+	gt.Equal("", dst.Skip, "skip tag")
+
+	gt.Equal(nil, Merge(&dst, conf{Name: "", Hosts: []string{}}, MergeZeros(true)), "zeros")
+	gt.Equal("", dst.Name, "zero override")
+	gt.Equal(0, len(dst.Hosts), "zero override slice")
+
+	gt.Equal(nil, Merge(&dst, map[string]any{"name": "map", "labels": map[string]any{"team": "ops"}}), "map src")
+	gt.Equal("map", dst.Name, "map src key")
+	gt.Equal("ops", dst.Labels["team"], "map src nested")
+
+	m := map[string]any{
+		"a": 1,
+		"b": map[string]any{"c": "c", "d": []any{1}},
+	}
+	gt.Equal(nil, Merge(&m, map[string]any{
+		"b": map[string]any{"d": []any{2}, "e": "e"},
+		"f": 1.5,
+	}, MergeSlices(MergeAppend)), "map")
+	gt.Equal(map[string]any{
+		"a": 1,
+		"b": map[string]any{"c": "c", "d": []any{1, 2}, "e": "e"},
+		"f": 1.5,
+	}, m, "map nested")
+
+	g := Gmap{{"a", ValueOf(1)}}
+	gt.Equal(nil, Merge(&g, map[string]int{"a": 2, "b": 3}), "Gmap")
+	gt.Equal(2, len(g), "Gmap len")
+	gt.Equal(2, g[0].Value.Int(), "Gmap value")
+
+	gt.True(Merge(dst, src) != nil, "non pointer dst")
+	gt.True(Merge(&dst, []int{1}) != nil, "incompatible src")
+	var n int
+	gt.True(Merge(&n, "x") != nil, "conversion error")
+}
+
+func TestWalk(t *testing.T) {
+	gt := test.New(t, config)
+	gt.Msg = "Testing Walk(%s)"
+
+	type node struct {
+		Name string
+		Next *node
+		Tags []any
+		Meta map[string]any
+	}
+	n := &node{
+		Name: "root",
+		Tags: []any{"a", 1},
+		Meta: map[string]any{"k": "v"},
+	}
+	n.Next = n
+
+	var paths []string
+	var cycles []string
+	Walk(n, Visitor{
+		Pre: func(w *WalkNode) WalkAction {
+			if w.Cycle {
+				cycles = append(cycles, w.PathString())
+			}
+			if w.Value.Kind() != Pointer {
+				paths = append(paths, w.PathString())
+			}
+			return WalkContinue
+		},
+	})
+	gt.Equal([]string{"", "Name", "Tags", "Tags.0", "Tags.1", "Meta", "Meta.k"}, paths, "paths")
+	gt.Equal([]string{"Next"}, cycles, "cycle")
+
+	var post []string
+	Walk(n, Visitor{
+		Pre: func(w *WalkNode) WalkAction {
+			if w.Key() == "Tags" {
+				return WalkSkip
+			}
+			return WalkContinue
+		},
+		Post: func(w *WalkNode) WalkAction {
+			if w.Value.Kind() != Pointer {
+				post = append(post, w.PathString())
+			}
+			if w.Key() == "Meta" {
+				return WalkStop
+			}
+			return WalkContinue
+		},
+	})
+	gt.Equal([]string{"Name", "Tags", "Meta.k", "Meta"}, post, "skip and stop")
+
+	var fields []string
+	Walk(string_struct{"one", "two"}, Visitor{Pre: func(w *WalkNode) WalkAction {
+		if w.Field != nil {
+			fields = append(fields, w.Field.Name()+"="+w.Value.String())
+		}
+		return WalkContinue
+	}})
+	gt.Equal([]string{"V1=one", "V2=two"}, fields, "fields")
+
+	Walk(n, Visitor{Pre: func(w *WalkNode) WalkAction {
+		if w.Value.Kind() == String {
+			w.Replace(STRING(w.Value.String()).ToUpper())
+		}
+		if w.Value.KIND() == Int {
+			w.Replace(w.Value.Int() * 10)
+		}
+		return WalkContinue
+	}})
+	gt.Equal("ROOT", n.Name, "replace field")
+	gt.Equal([]any{"A", 10}, n.Tags, "replace slice item")
+	gt.Equal("V", n.Meta["k"], "replace map item")
+}
+
+func TestValidate(t *testing.T) {
+	gt := test.New(t, config)
+	gt.Msg = "Testing Validate(%s)"
+
+	type user struct {
+		Name  string `validate:"required min:'2' max:'5'"`
+		Email string `validate:"omitempty email"`
+		Role  string `validate:"oneof:'admin user'"`
+		Code  string `validate:"regex:'^[A-Z]{3}$'"`
+	}
+	type event struct {
+		ID    string           `validate:"uuid"`
+		Site  string           `validate:"url"`
+		Start time.Time        `validate:"required"`
+		End   time.Time        `validate:"gtfield:'Start'"`
+		Min   int              `validate:"min:'1'"`
+		Max   int              `validate:"ltefield:'Min'"`
+		Tags  []string         `validate:"len:'2'"`
+		Owner user             `json:"owner"`
+		Users []user           `validate:"max:'3'"`
+		Index map[string]*user `validate:"-"`
+		Roles map[string]*user
+		Skip  string `validate:"-"`
+	}
+	now := time.Now()
+	valid := event{
+		ID:    "267b3229-2566-4426-a826-8d80126e719a",
+		Site:  "https://example.com/path",
+		Start: now,
+		End:   now.Add(time.Hour),
+		Min:   2,
+		Max:   1,
+		Tags:  []string{"a", "b"},
+		Owner: user{"jim", "jim@example.com", "admin", "ABC"},
+		Users: []user{{"ann", "", "user", "XYZ"}},
+		Index: map[string]*user{"bad": {}},
+		Roles: map[string]*user{"a": {"bob", "", "user", "DEF"}},
+	}
+	gt.Equal(nil, Validate(valid), "valid")
+	gt.Equal(nil, Validate(&valid), "valid pointer")
+
+	invalid := event{
+		ID:    "not-a-uuid",
+		Site:  "example",
+		End:   now,
+		Max:   1,
+		Tags:  []string{"a"},
+		Owner: user{"j", "jim", "root", "abc"},
+		Users: []user{{}},
+		Roles: map[string]*user{"a": {Name: "toolong", Role: "user", Code: "DEF"}},
+	}
+	errs := StructOf(invalid).Validate()
+	gt.Equal([]string{
+		"ID: failed 'uuid'",
+		"Site: failed 'url'",
+		"Start: failed 'required'",
+		"Min: failed 'min=1'",
+		"Max: failed 'ltefield=Min'",
+		"Tags: failed 'len=2'",
+		"Owner.Name: failed 'min=2'",
+		"Owner.Email: failed 'email'",
+		"Owner.Role: failed 'oneof=admin user'",
+		"Owner.Code: failed 'regex=^[A-Z]{3}$'",
+		"Users.0.Name: failed 'required'",
+		"Users.0.Role: failed 'oneof=admin user'",
+		"Users.0.Code: failed 'regex=^[A-Z]{3}$'",
+		"Roles.a.Name: failed 'max=5'",
+	}, func() (s []string) {
+		for _, e := range errs {
+			s = append(s, e.Error())
+		}
+		return
+	}(), "errors")
+	gt.True(Validate(invalid) != nil, "invalid")
+
+	type flags struct {
+		Min   *int    `validate:"min:'1'"`
+		Max   *string `validate:"omitempty max:'3'"`
+		On    bool    `validate:"gtfield:'Off'"`
+		Off   bool    `validate:"ltfield:'On'"`
+		Size  bool    `validate:"min:'1'"`
+		Cross int     `validate:"eqfield:'Missing'"`
+		Rule  int     `validate:"unknown"`
+		Re    string  `validate:"regex:'('"`
+	}
+	zero, long := 0, "long"
+	errs = StructOf(flags{Min: &zero, Max: &long, On: true}).Validate()
+	gt.Equal([]string{
+		"Min: failed 'min=1'",
+		"Max: failed 'max=3'",
+		"Size: failed 'min=1': cannot validate size of bool",
+		"Cross: failed 'eqfield=Missing': struct field 'Missing' does not exist",
+		"Rule: failed 'unknown': unknown validation rule",
+		"Re: failed 'regex=(': error parsing regexp: missing closing ): `(`",
+	}, func() (s []string) {
+		for _, e := range errs {
+			s = append(s, e.Error())
+		}
+		return
+	}(), "rule errors")
+	one := 1
+	errs = StructOf(flags{Min: &one, On: true}).Validate()
+	gt.Equal(4, len(errs), "pointer and bool rules")
+	errs = StructOf(flags{On: false, Off: true}).Validate()
+	gt.Equal("On: failed 'gtfield=Off'", errs[0].Error(), "bool compare")
+	gt.Equal("Off: failed 'ltfield=On'", errs[1].Error(), "bool compare")
+
+	type tagged struct {
+		Code string `validate:"required  regex:'^[a-z]+$' omitempty"`
+	}
+	gt.Equal(map[string]string{"required": "", "regex": "^[a-z]+$", "omitempty": ""},
+		StructOf(tagged{}).Field("Code").SubTags("validate"), "bare flags in sub tags")
+	tr := TypeOf(tagged{}).validateRules()
+	gt.Equal(0, len(StructOf(tagged{"abc"}).Validate()), "cached regex")
+	gt.True(tr[0].regex != nil && tr[0].regex == TypeOf(tagged{}).validateRules()[0].regex, "regex compiled once")
+}
+
+func TestApplyDefaults(t *testing.T) {
+	gt := test.New(t, config)
+	gt.Msg = "Testing ApplyDefaults(%s)"
+
+	type db struct {
+		Host    string        `json:"host" default:"localhost"`
+		Port    int           `json:"port" default:"5432"`
+		Timeout time.Duration `json:"timeout" default:"5s"`
+	}
+	type conf struct {
+		Name    string            `json:"name" default:"app"`
+		Debug   bool              `json:"debug" default:"true"`
+		Rate    float64           `json:"rate" default:"0.5"`
+		Retries uint8             `json:"retries" default:"3"`
+		Start   TIME              `json:"start" default:"2023-01-02"`
+		ID      UUID              `json:"id" default:"267b3229-2566-4426-a826-8d80126e719a"`
+		Hosts   []string          `json:"hosts" default:"a, b"`
+		Ports   []int             `json:"ports" default:"80,443"`
+		Limits  map[string]int    `json:"limits" default:"a:1,b:2"`
+		Level   *int              `json:"level" default:"2"`
+		DB      db                `json:"db"`
+		Replica []db              `json:"replica"`
+		Labels  map[string]string `json:"labels"`
+	}
+	c := conf{Name: "set", Replica: []db{{Host: "replica"}}}
+	ApplyDefaults(&c)
+	gt.Equal("set", c.Name, "non zero kept")
+	gt.True(c.Debug, "bool")
+	gt.Equal(0.5, c.Rate, "float")
+	gt.Equal(uint8(3), c.Retries, "uint")
+	gt.Equal("2023-01-02", c.Start.Time().Format("2006-01-02"), "time")
+	gt.Equal("267b3229-2566-4426-a826-8d80126e719a", c.ID.String(), "uuid")
+	gt.Equal([]string{"a", "b"}, c.Hosts, "slice")
+	gt.Equal([]int{80, 443}, c.Ports, "int slice")
+	gt.Equal(map[string]int{"a": 1, "b": 2}, c.Limits, "map")
+	gt.Equal(2, *c.Level, "pointer")
+	gt.Equal(db{"localhost", 5432, 5 * time.Second}, c.DB, "nested struct")
+	gt.Equal([]db{{"replica", 5432, 5 * time.Second}}, c.Replica, "slice of structs")
+	gt.True(c.Labels == nil, "no default")
+
+	m := JsonMarshaler.New()
+	m.UnmarshalTyped = true
+	m.UnmarshalDefaults = true
+	var u conf
+	gt.Equal(nil, m.UnmarshalInto(&u, []byte(`{
+		"name": "json",
+		"debug": false,
+		"hosts": ["x"],
+		"db": {"port": 6543},
+		"replica": [{"host": "r1"}, {"port": 1}]
+	}`)), "unmarshal")
+	gt.Equal("json", u.Name, "unmarshal key")
+	gt.False(u.Debug, "unmarshal zero key")
+	gt.Equal(0.5, u.Rate, "unmarshal missing key")
+	gt.Equal([]string{"x"}, u.Hosts, "unmarshal slice")
+	gt.Equal(db{"localhost", 6543, 5 * time.Second}, u.DB, "unmarshal nested")
+	gt.Equal([]db{{"r1", 5432, 5 * time.Second}, {"localhost", 1, 5 * time.Second}}, u.Replica, "unmarshal slice of structs")
+
+	var n conf
+	m.UnmarshalDefaults = false
+	gt.Equal(nil, m.UnmarshalInto(&n, []byte(`{"name": "json", "retries": "7"}`)), "unmarshal without defaults")
+	gt.Equal(uint8(7), n.Retries, "unmarshal converted")
+	gt.Equal(0.0, n.Rate, "unmarshal without defaults")
+
+	type unsupported struct {
+		Arr   [2]int `default:"1,2"`
+		Any   any    `default:"x"`
+		Anys  []any  `default:"x,y"`
+		Inner db     `default:"x"`
+	}
+	var us unsupported
+	ApplyDefaults(&us)
+	gt.Equal([2]int{}, us.Arr, "array skipped")
+	gt.True(us.Any == nil, "interface skipped")
+	gt.True(us.Anys == nil, "interface slice skipped")
+	gt.Equal(db{"localhost", 5432, 5 * time.Second}, us.Inner, "struct skipped and nested defaults applied")
+}
+
+func TestLoadConfig(t *testing.T) {
+	gt := test.New(t, config)
+	gt.Msg = "Testing LoadConfig(%s)"
+
+	type db struct {
+		Host    string        `json:"host" yaml:"host" toml:"host" default:"localhost"`
+		Port    int           `json:"port" yaml:"port" toml:"port" default:"5432" usage:"database port"`
+		Timeout time.Duration `json:"timeout" yaml:"timeout" toml:"timeout" default:"5s"`
+	}
+	type conf struct {
+		Name   string   `json:"name" yaml:"name" toml:"name" default:"app"`
+		Debug  bool     `json:"debug" yaml:"debug" toml:"debug"`
+		Hosts  []string `json:"hosts" yaml:"hosts" toml:"hosts"`
+		Secret string   `env:"APP_SECRET_KEY" flag:"-"`
+		Local  string   `env:"-"`
+		DB     db       `env:"DATABASE"`
+		Cache  *db      `json:"cache" toml:"cache"`
+	}
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		p := dir + "/" + name
+		if err := os.WriteFile(p, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	jsonFile := write("conf.json", `{"name": "json", "hosts": ["a", "b"], "db": {"host": "jsonhost"}}`)
+	yamlFile := write("conf.yaml", "debug: true\nDB:\n  port: 1111\n  timeout: 1m\n")
+	tomlFile := write("conf.toml", `
+# toml config
+name = "toml" # comment
+
+[DB]
+host = "tomlhost"
+
+[cache]
+host = 'cachehost'
+port = 6_379
+`)
+	env := map[string]string{
+		"SVC_DATABASE_PORT":  "2222",
+		"SVC_APP_SECRET_KEY": "secret",
+		"SVC_CACHE_TIMEOUT":  "2s",
+		"SVC_LOCAL":          "ignored",
+		"SVC_HOSTS":          "x,y",
+	}
+	lookup := func(k string) (string, bool) {
+		v, ok := env[k]
+		return v, ok
+	}
+
+	var c conf
+	r, err := LoadConfig(&c,
+		ConfigFiles(jsonFile, yamlFile, tomlFile),
+		ConfigEnvPrefix("SVC"),
+		ConfigEnv(lookup),
+		ConfigArgs([]string{"-d_b.port", "3333", "-debug=false", "-local", "flag"}),
+	)
+	gt.Equal(nil, err, "error")
+	gt.Equal("toml", c.Name, "later file overrides")
+	gt.False(c.Debug, "flag overrides file")
+	gt.Equal([]string{"x", "y"}, c.Hosts, "env overrides file")
+	gt.Equal("secret", c.Secret, "env tag")
+	gt.Equal("flag", c.Local, "env excluded")
+	gt.Equal(db{"tomlhost", 3333, time.Minute}, c.DB, "nested")
+	gt.Equal(db{"cachehost", 6379, 2 * time.Second}, *c.Cache, "nested pointer")
+	gt.Equal(ConfigReport{
+		"Name":          {SourceFile, tomlFile},
+		"Debug":         {SourceFlag, "-debug"},
+		"Hosts":         {SourceEnv, "SVC_HOSTS"},
+		"Secret":        {SourceEnv, "SVC_APP_SECRET_KEY"},
+		"Local":         {SourceFlag, "-local"},
+		"DB.Host":       {SourceFile, tomlFile},
+		"DB.Port":       {SourceFlag, "-d_b.port"},
+		"DB.Timeout":    {SourceFile, yamlFile},
+		"Cache.Host":    {SourceFile, tomlFile},
+		"Cache.Port":    {SourceFile, tomlFile},
+		"Cache.Timeout": {SourceEnv, "SVC_CACHE_TIMEOUT"},
+	}, r, "report")
+
+	var d conf
+	r, err = LoadConfig(&d, ConfigEnv(lookup), ConfigPrecedence(SourceDefault))
+	gt.Equal(nil, err, "defaults")
+	gt.Equal(db{"localhost", 5432, 5 * time.Second}, d.DB, "defaults")
+	gt.Equal(ConfigOrigin{SourceDefault, ""}, r["DB.Port"], "default report")
+	gt.Equal(ConfigOrigin{}, r["Secret"], "unset report")
+
+	_, err = LoadConfig(&d, ConfigArgs([]string{"-unknown"}))
+	gt.True(err != nil, "flag error")
+	_, err = LoadConfig(&d, ConfigFiles(write("bad.toml", "a = ")))
+	gt.True(err != nil, "toml error")
+	_, err = LoadConfig(d)
+	gt.True(err != nil, "non pointer")
+	var ia struct {
+		Any any `default:"x"`
+	}
+	_, err = LoadConfig(&ia)
+	gt.True(err != nil && strings.Contains(err.Error(), "Any"), "unsupported default kind")
+
+	type excluded struct {
+		Name  string `json:"-"`
+		Token string `json:"-" yaml:"token"`
+	}
+	var x excluded
+	_, err = LoadConfig(&x, ConfigFiles(
+		write("x.json", `{"Name": "n", "name": "n", "Token": "t", "token": "t"}`),
+		write("x.yaml", "token: y\n"),
+	))
+	gt.Equal(nil, err, "excluded")
+	gt.Equal(excluded{"", "y"}, x, "excluded by format tag")
+}
+
+func TestParseToml(t *testing.T) {
+	gt := test.New(t, config)
+	gt.Msg = "Testing parseToml(%s)"
+
+	doc := `
+title = "a \"quoted\" \u00e9" # comment
+path = 'C:\dir'
+"quoted key" = 1
+site.owner.name = "x"
+hex = 0xff
+num = 1_000
+pi = 3.14
+neg = -inf
+on = true
+when = 1979-05-27 07:32:00
+ports = [ 80,
+  443, ] # trailing comma
+point = { x = 1, y = [2] }
+text = """
+line \
+  joined"""
+
+[server.alpha]
+ip = "10.0.0.1"
+
+[[products]]
+name = "a"
+
+[[products]]
+name = "b"
+[products.dims]
+w = 2
+`
+	m, err := parseToml([]byte(doc))
+	gt.Equal(nil, err, "error")
+	gt.Equal("a \"quoted\" \u00e9", m["title"], "basic string")
+	gt.Equal(`C:\dir`, m["path"], "literal string")
+	gt.Equal(1, m["quoted key"], "quoted key")
+	gt.Equal(map[string]any{"owner": map[string]any{"name": "x"}}, m["site"], "dotted key")
+	gt.Equal(255, m["hex"], "hex int")
+	gt.Equal(1000, m["num"], "underscore int")
+	gt.Equal(3.14, m["pi"], "float")
+	gt.Equal(math.Inf(-1), m["neg"], "inf")
+	gt.Equal(true, m["on"], "bool")
+	gt.Equal("1979-05-27 07:32:00", m["when"], "date time")
+	gt.Equal([]any{80, 443}, m["ports"], "array")
+	gt.Equal(map[string]any{"x": 1, "y": []any{2}}, m["point"], "inline table")
+	gt.Equal("line joined", m["text"], "multi-line string")
+	gt.Equal(map[string]any{"alpha": map[string]any{"ip": "10.0.0.1"}}, m["server"], "table")
+	gt.Equal([]any{
+		map[string]any{"name": "a"},
+		map[string]any{"name": "b", "dims": map[string]any{"w": 2}},
+	}, m["products"], "array of tables")
+
+	for _, bad := range []string{"a = ", "a = 1\na = 2", "a = \"x", "[a", "a = 1 b", "a = 1\n[a]", "a = \"\\q\"", "a = [1 2]", "a = wat"} {
+		_, err = parseToml([]byte(bad))
+		gt.True(err != nil, "error "+bad)
+	}
+	_, err = parseToml([]byte("a = 1\nb = ="))
+	gt.True(err != nil && strings.Contains(err.Error(), "line 2"), "error line")
+}
+
+func TestScanRows(t *testing.T) {
+	gt := test.New(t, config)
+	gt.Msg = "Testing ScanRows(%s)"
+
+	id := NewUUID()
+	ts := time.Date(2023, 5, 1, 12, 30, 0, 0, time.UTC)
+	fakeTables["select users"] = fakeTable{
+		cols: []string{"user_id", "name", "age", "created", "data", "raw", "nick", "other"},
+		rows: [][]driver.Value{
+			{id.String(), []byte("ann"), int64(30), ts, []byte(`{"a":1}`), []byte{1, 2}, "annie", "x"},
+			{nil, "bob", nil, "2023-05-02", nil, nil, nil, nil},
+		},
+	}
+	type user struct {
+		ID      UUID `db:"user_id"`
+		Name    string
+		Age     int
+		Created TIME
+		Data    JSON
+		Raw     BYTES
+		Nick    *string
+	}
+	db, err := sql.Open("gotypefake", "")
+	gt.Equal(nil, err, "open")
+	defer db.Close()
+
+	rows, err := db.Query("select users")
+	gt.Equal(nil, err, "query")
+	var users []user
+	gt.Equal(nil, ScanRows(rows, &users), "scan")
+	rows.Close()
+	nick := "annie"
+	gt.Equal([]user{
+		{id, "ann", 30, TIME(ts), JSON(`{"a":1}`), BYTES{1, 2}, &nick},
+		{UUID{}, "bob", 0, TIME(time.Date(2023, 5, 2, 0, 0, 0, 0, time.UTC)), nil, nil, nil},
+	}, users, "structs")
+
+	rows, _ = db.Query("select users")
+	var ptrs []*user
+	gt.Equal(nil, ScanRows(rows, &ptrs), "scan pointers")
+	rows.Close()
+	gt.Equal(2, len(ptrs), "pointers")
+	gt.Equal("bob", ptrs[1].Name, "pointers")
+
+	rows, _ = db.Query("select users")
+	gt.True(ScanRows(rows, users) != nil, "non pointer")
+	rows.Close()
+
+	v, _ := id.Value()
+	gt.Equal(id.String(), v, "uuid value")
+	v, _ = TIME(ts).Value()
+	gt.Equal(ts, v, "time value")
+	v, _ = JSON(nil).Value()
+	gt.Equal(nil, v, "empty json value")
+	var u UUID
+	gt.True(u.Scan(123) != nil, "uuid scan error")
+	var tm TIME
+	gt.Equal(nil, tm.Scan([]byte("2023-05-01T12:30:00Z")), "time scan")
+	gt.Equal(TIME(ts), tm, "time scan")
+
+	fakeTables["select docs"] = fakeTable{
+		cols: []string{"id", "doc"},
+		rows: [][]driver.Value{
+			{int64(1), []byte(`{"a":1}`)},
+			{int64(2), nil},
+			{int64(3), `[1,2]`},
+		},
+	}
+	type doc struct {
+		ID  int
+		Doc NullJSON
+	}
+	rows, _ = db.Query("select docs")
+	var docs []doc
+	gt.Equal(nil, ScanRows(rows, &docs), "scan null json")
+	rows.Close()
+	gt.Equal([]doc{
+		{1, NullJSON{JSON(`{"a":1}`), true}},
+		{2, NullJSON{}},
+		{3, NullJSON{JSON(`[1,2]`), true}},
+	}, docs, "scan null json")
+	var nj NullJSON
+	gt.Equal(nil, db.QueryRow("select docs").Scan(new(int), &nj), "row scan null json")
+	gt.Equal(NullJSON{JSON(`{"a":1}`), true}, nj, "row scan null json")
+	v, _ = nj.Value()
+	gt.Equal(`{"a":1}`, v, "null json value")
+	v, _ = NullJSON{}.Value()
+	gt.Equal(nil, v, "null json value")
+	gt.True(nj.Scan(1) != nil, "null json scan error")
+}
+
+func TestSQLDialect(t *testing.T) {
+	gt := test.New(t, config)
+	gt.Msg = "Testing SQLDialect(%s)"
+
+	type user struct {
+		ID      int    `db:"id,pk,auto"`
+		Name    string `db:"name"`
+		Email   string
+		Age     int `db:"age"`
+		private string
+		Skip    string `db:"-"`
+	}
+	u := user{ID: 7, Name: "ann", Email: "a@b.c"}
+
+	gt.Equal(`"id", "name", "email", "age"`, SQLPostgres.Columns(u), "columns")
+	gt.Equal("SELECT `id`, `name`, `email`, `age` FROM `app`.`users`", SQLMySQL.Select("app.users", &u), "select")
+
+	q, args, err := SQLPostgres.Insert("users", u)
+	gt.Equal(nil, err, "insert")
+	gt.Equal(`INSERT INTO "users" ("id", "name", "email", "age") VALUES ($1, $2, $3, $4)`, q, "insert")
+	gt.Equal([]any{7, "ann", "a@b.c", 0}, args, "insert args")
+	q, args, _ = SQLMySQL.Insert("users", user{Name: "bob"})
+	gt.Equal("INSERT INTO `users` (`name`, `email`, `age`) VALUES (?, ?, ?)", q, "insert auto")
+	gt.Equal(3, len(args), "insert auto args")
+
+	q, args, err = SQLPostgres.Update("users", u)
+	gt.Equal(nil, err, "update")
+	gt.Equal(`UPDATE "users" SET "name" = $1, "email" = $2 WHERE "id" = $3`, q, "update non zero")
+	gt.Equal([]any{"ann", "a@b.c", 7}, args, "update args")
+	prev := u
+	u.Email, u.Age = "x@y.z", 0
+	named := SQLDialect{Placeholder: PlaceholderNamed}
+	q, args, _ = named.Update("users", u, prev)
+	gt.Equal(`UPDATE users SET email = :email WHERE id = :id`, q, "update changed")
+	gt.Equal([]any{sql.Named("email", "x@y.z"), sql.Named("id", 7)}, args, "update named args")
+	_, _, err = named.Update("users", u, u)
+	gt.True(err != nil, "update unchanged")
+	_, _, err = SQLPostgres.Update("users", struct{ A int }{1})
+	gt.True(err != nil, "update without pk")
+
+	q, _, _ = SQLPostgres.Upsert("users", u)
+	gt.Equal(`INSERT INTO "users" ("id", "name", "email", "age") VALUES ($1, $2, $3, $4) ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name", "email" = EXCLUDED."email", "age" = EXCLUDED."age"`, q, "upsert on conflict")
+	q, _, _ = SQLMySQL.Upsert("users", u)
+	gt.Equal("INSERT INTO `users` (`id`, `name`, `email`, `age`) VALUES (?, ?, ?, ?) ON DUPLICATE KEY UPDATE `name` = VALUES(`name`), `email` = VALUES(`email`), `age` = VALUES(`age`)", q, "upsert on duplicate key")
+	gt.Equal(`"a""b"`, SQLSQLite.Quote(`a"b`), "quote")
+
+	type row struct {
+		Key     string `db:"key,pk"`
+		Seq     int    `db:"seq,auto"`
+		Value   string `db:"value"`
+		Created TIME   `db:"created,auto"`
+	}
+	q, _, _ = SQLPostgres.Upsert("rows", row{Key: "k", Seq: 2, Value: "v"})
+	gt.Equal(`INSERT INTO "rows" ("key", "seq", "value") VALUES ($1, $2, $3) ON CONFLICT ("key") DO UPDATE SET "value" = EXCLUDED."value"`, q, "upsert auto")
+	q, _, _ = SQLMySQL.Upsert("rows", row{Key: "k"})
+	gt.Equal("INSERT INTO `rows` (`key`, `value`) VALUES (?, ?) ON DUPLICATE KEY UPDATE `value` = VALUES(`value`)", q, "upsert auto")
+}
+
+func TestForm(t *testing.T) {
+	gt := test.New(t, config)
+	gt.Msg = "Testing Form(%s)"
+
+	type address struct {
+		City string `form:"city"`
+		Zip  int    `form:"zip,omitempty"`
+	}
+	type item struct {
+		Name string
+		Qty  uint8
+	}
+	type user struct {
+		Name    string   `form:"name"`
+		Age     int      `form:"age"`
+		Admin   bool     `form:"admin"`
+		Tags    []string `form:"tags"`
+		Address *address `form:"address"`
+		Items   []item   `form:"items"`
+		Created TIME     `form:"created"`
+		Skip    string   `form:"-"`
+	}
+
+	form := url.Values{
+		"name":                   {"ann"},
+		"age":                    {"30"},
+		"admin":                  {"true"},
+		"tags[]":                 {"a", "b"},
+		"address[city]":          {"Paris"},
+		"address.zip":            {"75001"},
+		"items[1][Name]":         {"pen"},
+		"items[0].name":          {"ink"},
+		"items[0][qty]":          {"2"},
+		"created":                {"2023-05-01"},
+		"Skip":                   {"skip"},
+		"unknown[nested][value]": {"x"},
+	}
+	var u user
+	gt.Equal(nil, DecodeForm(form, &u), "decode")
+	gt.Equal(user{
+		Name:    "ann",
+		Age:     30,
+		Admin:   true,
+		Tags:    []string{"a", "b"},
+		Address: &address{"Paris", 75001},
+		Items:   []item{{"ink", 2}, {"pen", 0}},
+		Created: TIME(time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)),
+	}, u, "decode struct")
+
+	var m map[string]any
+	gt.Equal(nil, DecodeForm(url.Values{"a": {"1"}, "b[c]": {"2"}, "d": {"3", "4"}}, &m), "decode map")
+	gt.Equal(map[string]any{"a": "1", "b": map[string]any{"c": "2"}, "d": []string{"3", "4"}}, m, "decode map")
+
+	gt.True(DecodeForm(url.Values{"age": {"x"}}, &u) != nil, "conversion error")
+	gt.True(DecodeForm(form, u) != nil, "non pointer")
+
+	u.Address.Zip, u.Created = 0, TIME{}
+	v, err := EncodeForm(u, FormBrackets)
+	gt.Equal(nil, err, "encode")
+	gt.Equal("address%5Bcity%5D=Paris&admin=true&age=30&created=&items%5B0%5D%5BName%5D=ink&items%5B0%5D%5BQty%5D=2&items%5B1%5D%5BName%5D=pen&items%5B1%5D%5BQty%5D=0&name=ann&tags=a&tags=b", v.Encode(), "encode brackets")
+	v, _ = EncodeForm(&u, FormDots)
+	gt.Equal("Paris", v.Get("address.city"), "encode dots")
+	gt.Equal("pen", v.Get("items.1.Name"), "encode dots")
+
+	var r user
+	gt.Equal(nil, DecodeForm(v, &r), "round trip")
+	gt.Equal(u, r, "round trip")
+	_, err = EncodeForm(1, FormDots)
+	gt.True(err != nil, "encode non struct")
+
+	m = nil
+	gt.Equal(nil, DecodeForm(url.Values{"user[a.b]": {"1"}, "user.c[d.e]": {"2"}}, &m), "dots in brackets")
+	gt.Equal(map[string]any{"user": map[string]any{"a.b": "1", "c": map[string]any{"d.e": "2"}}}, m, "dots in brackets")
+	var sparse user
+	gt.Equal(nil, DecodeForm(url.Values{"items[2][name]": {"pen"}, "tags[1]": {"b"}}, &sparse), "sparse indexes")
+	gt.Equal([]item{{}, {}, {"pen", 0}}, sparse.Items, "sparse indexes")
+	gt.Equal([]string{"", "b"}, sparse.Tags, "sparse indexes")
+	gt.True(DecodeForm(url.Values{"tags[1000000000]": {"x"}}, &sparse) != nil, "index over MaxFormIndex")
+}
+
+func TestGeneric(t *testing.T) {
+	gt := test.New(t, config)
+	gt.Msg = "Testing Generic(%s)"
+
+	i, err := As[int]("42")
+	gt.Equal(nil, err, "As")
+	gt.Equal(42, i, "As string to int")
+	s, _ := As[string](3.5)
+	gt.Equal("3.5", s, "As float to string")
+	b, _ := As[bool]("true")
+	gt.True(b, "As string to bool")
+	u8, _ := As[uint8](int64(7))
+	gt.Equal(uint8(7), u8, "As int64 to uint8")
+	ts, _ := As[TIME]("2023-05-01")
+	gt.Equal(TIME(time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)), ts, "As string to TIME")
+	p, _ := As[*int]("5")
+	gt.Equal(5, *p, "As string to *int")
+	z, err := As[int](nil)
+	gt.Equal(0, z, "As nil")
+	gt.Equal(nil, err, "As nil")
+	_, err = As[int]("abc")
+	gt.True(err != nil, "As invalid")
+	_, err = As[error]("abc")
+	gt.True(err != nil, "As interface")
+	gt.Equal(int64(9), Must[int64]("9"), "Must")
+
+	type user struct {
+		Name string
+		Tags []string
+		Meta map[string]any
+		Next *user
+	}
+	v := &user{"ann", []string{"a", "b"}, map[string]any{"age": "30", "nil": nil}, &user{Name: "bob"}}
+	name, err := Get[string](v, "Name")
+	gt.Equal("ann", name, "Get field")
+	gt.Equal(nil, err, "Get field")
+	tag, _ := Get[string](v, "Tags.1")
+	gt.Equal("b", tag, "Get index")
+	age, _ := Get[int](v, "Meta.age")
+	gt.Equal(30, age, "Get map key")
+	next, _ := Get[string](v, "Next.Name")
+	gt.Equal("bob", next, "Get pointer")
+	n, err := Get[*int](v, "Meta.nil")
+	gt.True(n == nil && err == nil, "Get nil")
+	_, err = Get[string](v, "Tags.5")
+	gt.True(err != nil, "Get out of range")
+	_, err = Get[string](v, "Next.Next.Name")
+	gt.True(err != nil, "Get nil pointer")
+	_, err = Get[string](v, "Missing")
+	gt.True(err != nil, "Get missing")
+
+	ints, err := SliceAs[int]([]any{"1", 2, 3.0})
+	gt.Equal(nil, err, "SliceAs")
+	gt.Equal(SLICEOF[int]{1, 2, 3}, ints, "SliceAs")
+	strs, _ := SliceAs[string]([2]int{4, 5})
+	gt.Equal(SLICEOF[string]{"4", "5"}, strs, "SliceAs array")
+	_, err = SliceAs[int]([]string{"x"})
+	gt.True(err != nil, "SliceAs invalid")
+	m, err := MapAs[string, int](map[string]string{"a": "1", "b": "2"})
+	gt.Equal(nil, err, "MapAs")
+	gt.Equal(MAPOF[string, int]{"a": 1, "b": 2}, m, "MapAs")
+	mi, _ := MapAs[int, string](map[string]any{"1": 1.5})
+	gt.Equal(MAPOF[int, string]{1: "1.5"}, mi, "MapAs int keys")
+	ms, _ := MapAs[string, any](user{Name: "cy"})
+	gt.Equal("cy", ms["Name"], "MapAs struct")
+
+	gt.Equal(nil, ints.Set(0, "7"), "SLICEOF.Set")
+	gt.Equal(7, ints.Index(0), "SLICEOF.Set")
+	gt.True(ints.Set(0, "x") != nil, "SLICEOF.Set invalid")
+	ints, err = ints.Append("4", 5.0)
+	gt.Equal(nil, err, "SLICEOF.Append")
+	gt.Equal([]int{7, 2, 3, 4, 5}, ints.Slice(), "SLICEOF.Append")
+	gt.Equal(5, ints.SLICE().Len(), "SLICEOF.SLICE")
+	gt.Equal(nil, m.Set("c", "3"), "MAPOF.Set")
+	gt.Equal(3, m.Index("c"), "MAPOF.Index")
+	gt.Equal(0, m.Index("z"), "MAPOF.Index missing")
+	gt.Equal(nil, mi.Set("2", 2), "MAPOF.Set key")
+	gt.Equal("2", mi.Index(2), "MAPOF.Set key")
+	gt.Equal(map[int]string{1: "1.5", 2: "2"}, mi.Map(), "MAPOF.Map")
+	gt.Equal(3, m.MAP().Len(), "MAPOF.MAP")
+
+	z, err = As[int]((*int)(nil))
+	gt.True(z == 0 && err == nil, "As nil pointer")
+}
+
+func TestTry(t *testing.T) {
+	gt := test.New(t, config)
+	gt.Msg = "Testing Try(%s)"
+
+	i, err := ValueOf("12").TryInt()
+	gt.Equal(12, i, "VALUE.TryInt")
+	gt.Equal(nil, err, "VALUE.TryInt")
+	_, err = ValueOf("abc").TryInt()
+	var ce *ConversionError
+	gt.True(errors.As(err, &ce), "VALUE.TryInt error")
+	gt.Equal(String, ce.From, "error source kind")
+	gt.Equal(Int, ce.To, "error target kind")
+	gt.Equal("abc", ce.Input, "error input")
+	gt.Equal("cannot convert string 'abc' to int: cannot convert string to int", err.Error(), "error message")
+	_, err = VALUE{}.TryBool()
+	gt.True(errors.As(err, &ce) && ce.From == Invalid, "nil VALUE")
+
+	f, _ := STRING("1.5").TryFloat64()
+	gt.Equal(1.5, f, "STRING.TryFloat64")
+	b, _ := STRING("true").TryBool()
+	gt.True(b, "STRING.TryBool")
+	_, err = STRING("yes").TryBool()
+	gt.True(err != nil, "STRING.TryBool error")
+	tm, _ := STRING("2023-05-01").TryTime()
+	gt.Equal(TIME(time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)), tm, "STRING.TryTime")
+	_, err = STRING("may first").TryTime()
+	gt.True(err != nil, "STRING.TryTime error")
+	id := NewUUID()
+	u, _ := STRING(id.String()).TryUUID()
+	gt.Equal(id, u, "STRING.TryUUID")
+	_, err = STRING("x").TryUUID()
+	gt.True(err != nil, "STRING.TryUUID error")
+	u, _ = id.TryUUID()
+	gt.Equal(id, u, "UUID.TryUUID")
+	_, err = id.TryInt()
+	gt.True(errors.As(err, &ce) && ce.From == Uuid, "UUID.TryInt error")
+	s, _ := INT(5).TryString()
+	gt.Equal("5", s, "INT.TryString")
+	ui, _ := FLOAT(3).TryUint()
+	gt.Equal(uint(3), ui, "FLOAT.TryUint")
+	bs, _ := BOOL(true).TryBytes()
+	gt.Equal(1, len(bs), "BOOL.TryBytes")
+	_, err = BYTES("x").TryUUID()
+	gt.True(err != nil, "BYTES.TryUUID error")
+	_, err = ValueOf([]int{1}).SLICE().TryTime()
+	gt.True(errors.As(err, &ce) && ce.From == Slice && ce.To == Time, "SLICE.TryTime error")
+	_, err = ValueOf(map[string]int{}).MAP().TryInt()
+	gt.True(err != nil, "MAP.TryInt error")
+
+	_, err = As[int]("x")
+	gt.True(errors.As(err, &ce) && ce.To == Int, "As error")
+
+	_, err = ValueOf((*int)(nil)).TryInt()
+	gt.True(errors.As(err, &ce) && ce.From == Pointer && ce.To == Int, "nil pointer")
+	var ni any
+	_, err = ValueOf(&ni).Elem().TryString()
+	gt.True(errors.As(err, &ce), "nil interface")
+	_, err = VALUE{TypeOf(0), nil, flagIndir | flag(Int)}.TryInt()
+	gt.True(errors.As(err, &ce) && ce.Input == nil, "VALUE without data")
+	_, err = STRING("").TryUUID()
+	gt.True(errors.As(err, &ce) && ce.To == Uuid, "empty STRING.TryUUID")
+	_, err = STRING("").TryTime()
+	gt.True(errors.As(err, &ce) && ce.To == Time, "empty STRING.TryTime")
+	_, err = BYTES{1}.TryFloat64()
+	gt.True(errors.As(err, &ce) && ce.From == Bytes, "BYTES.TryFloat64 error")
+	f, _ = BYTES(ValueOf(float32(1.5)).Bytes()).TryFloat64()
+	gt.Equal(1.5, f, "BYTES.TryFloat64 float32")
+
+	gt.True(STRING("12").CanInt(), "STRING.CanInt")
+	gt.False(STRING("abc").CanInt(), "STRING.CanInt")
+	gt.False(STRING("").CanUUID(), "STRING.CanUUID")
+	gt.True(ValueOf("2023-05-01").CanTime(), "VALUE.CanTime")
+	gt.False(ValueOf((*int)(nil)).CanInt(), "VALUE.CanInt nil")
+	gt.True(INT(1).CanBool(), "INT.CanBool")
+	gt.False(id.CanFloat64(), "UUID.CanFloat64")
+	gt.True(BYTES{1, 2, 3, 4, 5, 6, 7, 8}.CanFloat64(), "BYTES.CanFloat64")
+
+	var r any
+	func() {
+		defer func() { r = recover() }()
+		try(1, Int, func() int {
+			var m map[string]int
+			m["a"] = 1
+			return 1
+		})
+	}()
+	_, runtimeErr := r.(runtime.Error)
+	gt.True(runtimeErr, "runtime error re-panicked")
+}
+
+func TestConversionPolicy(t *testing.T) {
+	gt := test.New(t, config)
+	gt.Msg = "Testing ConversionPolicy(%s)"
+
+	v := ValueOf(300)
+	r, err := v.CastWith(Int8, ConvertTruncate)
+	gt.Equal(int8(44), r, "truncate")
+	gt.Equal(nil, err, "truncate")
+	r, _ = v.CastWith(Int8, ConvertSaturate)
+	gt.Equal(int8(127), r, "saturate max")
+	r, _ = ValueOf(-300).CastWith(Int8, ConvertSaturate)
+	gt.Equal(int8(-128), r, "saturate min")
+	r, _ = ValueOf(-1).CastWith(Uint16, ConvertSaturate)
+	gt.Equal(uint16(0), r, "saturate unsigned")
+	r, _ = ValueOf(uint64(math.MaxUint64)).CastWith(Int64, ConvertSaturate)
+	gt.Equal(int64(math.MaxInt64), r, "saturate uint64")
+	_, err = v.CastWith(Int8, ConvertErrorOnOverflow)
+	gt.True(errors.Is(err, ErrOverflow), "error on overflow")
+	var ce *ConversionError
+	gt.True(errors.As(err, &ce) && ce.From == Int && ce.To == Int8, "overflow error kinds")
+	r, err = ValueOf(2.7).CastWith(Int16, ConvertErrorOnOverflow)
+	gt.Equal(int16(2), r, "fraction truncated")
+	_, err = ValueOf(2.7).CastWith(Int16, ConvertErrorOnPrecisionLoss)
+	gt.True(errors.Is(err, ErrPrecisionLoss), "error on fraction")
+	r, _ = ValueOf(2.0).CastWith(Int16, ConvertErrorOnPrecisionLoss)
+	gt.Equal(int16(2), r, "exact float")
+	_, err = ValueOf(1e40).CastWith(Float32, ConvertErrorOnOverflow)
+	gt.True(errors.Is(err, ErrOverflow), "float32 overflow")
+	_, err = ValueOf(0.1).CastWith(Float32, ConvertErrorOnPrecisionLoss)
+	gt.True(errors.Is(err, ErrPrecisionLoss), "float32 precision")
+	_, err = ValueOf(int64(1<<53+1)).CastWith(Float64, ConvertErrorOnPrecisionLoss)
+	gt.True(errors.Is(err, ErrPrecisionLoss), "int to float precision")
+	r, _ = ValueOf("70000").CastWith(Uint16, ConvertSaturate)
+	gt.Equal(uint16(math.MaxUint16), r, "string saturate")
+	_, err = ValueOf(math.NaN()).CastWith(Int, ConvertErrorOnOverflow)
+	gt.True(errors.Is(err, ErrOverflow), "NaN")
+
+	var i16 int16
+	p := ValueOf(&i16).Elem()
+	_, err = p.SetWith(40000, ConvertErrorOnOverflow)
+	gt.True(errors.Is(err, ErrOverflow), "SetWith overflow")
+	p.SetWith(40000, ConvertSaturate)
+	gt.Equal(int16(math.MaxInt16), i16, "SetWith saturate")
+
+	DefaultConversion = ConvertSaturate
+	p.Set(-40000)
+	gt.Equal(int16(math.MinInt16), i16, "Set default policy")
+	gt.Equal(uint8(255), ValueOf(1000).Cast(Uint8), "Cast default policy")
+	DefaultConversion = ConvertDefault
+	gt.Equal(int8(44), ValueOf(300).Cast(Int8), "Cast truncate by default")
+	var i8 int8
+	_, err = EncodeSchema(300).DecodeE(&i8)
+	gt.Equal(nil, err, "decode truncate by default")
+	gt.Equal(int8(44), i8, "decode truncate by default")
+	DefaultConversion = ConvertErrorOnOverflow
+	type small struct{ A int8 }
+	var s small
+	func() {
+		defer func() { err, _ = recover().(error) }()
+		ValueOf(map[string]any{"A": 1000}).MAP().Scan(&s)
+	}()
+	gt.True(errors.Is(err, ErrOverflow), "Scan default policy")
+	DefaultConversion = ConvertTruncate
+
+	m := JsonMarshaler.New()
+	m.UnmarshalTyped, m.Conversion = true, ConvertErrorOnOverflow
+	err = m.UnmarshalInto(&s, []byte(`{"A":1000}`))
+	gt.True(errors.Is(err, ErrOverflow), "UnmarshalInto overflow")
+	m.Conversion = ConvertSaturate
+	gt.Equal(nil, m.UnmarshalInto(&s, []byte(`{"A":1000}`)), "UnmarshalInto saturate")
+	gt.Equal(int8(127), s.A, "UnmarshalInto saturate")
+
+	gt.Equal(int8(-128), INT(-128).Int8(), "INT.Int8 min")
+	gt.Equal(int16(-32768), INT(-32768).Int16(), "INT.Int16 min")
+	gt.Equal(int32(math.MinInt32), INT(math.MinInt32).Int32(), "INT.Int32 min")
+	gt.Equal(-2, FLOAT(-1.6).Int(), "FLOAT.Int round")
+	gt.Equal(uint(0), FLOAT(-0.4).Uint(), "FLOAT.Uint round")
+	gt.Equal(int64(math.MaxInt64), UINT(math.MaxInt64).Int64(), "UINT.Int64 max")
+	for name, f := range map[string]func(){
+		"INT.Int8 below min":   func() { INT(-129).Int8() },
+		"INT.Int16 above max":  func() { INT(math.MaxInt16 + 1).Int16() },
+		"FLOAT.Int above max":  func() { FLOAT(1e19).Int() },
+		"FLOAT.Int below min":  func() { FLOAT(-1e19).Int() },
+		"FLOAT.Int NaN":        func() { FLOAT(math.NaN()).Int() },
+		"FLOAT.Uint above max": func() { FLOAT(0x1p64).Uint() },
+		"UINT.Int64 above max": func() { UINT(math.MaxInt64 + 1).Int64() },
+	} {
+		var r any
+		func() {
+			defer func() { r = recover() }()
+			f()
+		}()
+		gt.True(r != nil, name)
+	}
+}
+
+func TestMapKeys(t *testing.T) {
+	gt := test.New(t, config)
+	gt.Msg = "Testing MAP keys(%s)"
+
+	mi := map[int]string{1: "a", 2: "b"}
+	m := MapOf(mi)
+	gt.Equal("a", m.Index(1).String(), "int index")
+	gt.Equal("b", m.Index("2").String(), "string key index")
+	gt.True(m.KeyPtr(3) == nil, "missing key")
+	m.Set(3, "c").Set("4", "d")
+	gt.Equal("c", mi[3], "int set")
+	gt.Equal("d", mi[4], "string key set")
+	m.Delete(3).Delete("4")
+	gt.Equal(2, len(mi), "delete")
+	keys := m.Keys()
+	sort.Strings(keys)
+	gt.Equal([]string{"1", "2"}, keys, "keys")
+	gt.Equal(2, len(m.KeyValues()), "key values")
+	sum := 0
+	m.ForEachKey(func(i int, k, v VALUE) (brake bool) {
+		sum += k.Int()
+		return
+	})
+	gt.Equal(3, sum, "for each key")
+	gt.Equal(`{"1":"a"}`, MapOf(map[int]string{1: "a"}).String(), "marshal")
+	gt.Equal(`{"-1":"c","2":"b","10":"a"}`, MapOf(map[int]string{10: "a", 2: "b", -1: "c"}).String(), "marshal numeric order")
+	gt.Equal(`{"0.5":"b","2":"a"}`, MapOf(map[float64]string{2: "a", 0.5: "b"}).String(), "marshal float order")
+	vals := m.Strings()
+	sort.Strings(vals)
+	gt.Equal([]string{"a", "b"}, vals, "values")
+
+	u := UUID(uuid.New())
+	mu := map[UUID]int{u: 1}
+	gt.Equal(1, MapOf(mu).Index(u.String()).Int(), "uuid index")
+	gt.Equal(1, MapOf(mu).Index(u).Int(), "uuid index")
+
+	type point struct{ X, Y int }
+	ms := map[point]bool{{1, 2}: true}
+	MapOf(ms).Set(point{3, 4}, true)
+	gt.True(ms[point{3, 4}], "struct set")
+	gt.True(MapOf(ms).Index(point{1, 2}).Bool(), "struct index")
+
+	e := MapOf(map[int]string{1: "a"}).Encode()
+	gt.Equal(map[int]string{1: "a"}, e.Decodex().v.Interface(), "encode")
+	d := map[int]string{}
+	e.Decode(&d)
+	gt.Equal(map[int]string{1: "a"}, d, "decode")
+
+	var dest map[int]string
+	gt.Equal(nil, JsonMarshaler.New().UnmarshalInto(&dest, []byte(`{"1":"a","2":"b"}`)), "unmarshal")
+	gt.Equal(map[int]string{1: "a", 2: "b"}, dest, "unmarshal")
+}
+
+type cacheStringer struct{ A, B string }
+
+func (c cacheStringer) String() string { return c.A + c.B }
+func (c cacheStringer) Json() string   { return `"` + c.A + c.B + `"` }
+
+func TestTypeCache(t *testing.T) {
+	gt := test.New(t, config)
+	gt.Msg = "Testing TYPE cache(%s)"
+
+	for n, v := range getTestVars() {
+		var a any = v
+		gt.Equal(FromReflect(reflect.ValueOf(a)), ValueOf(a), "ValueOf", n)
+		p := reflect.New(reflect.TypeOf(a))
+		p.Elem().Set(reflect.ValueOf(a))
+		gt.Equal(FromReflect(p.Elem()), FromReflect(p).Elem(), "Elem", n)
+		gt.Equal(FromReflectType(p.Type()), TypeOf(v).PtrType(), "PtrType", n)
+	}
+	gt.Equal(VALUE{}, ValueOf(nil), "ValueOf(nil)")
+	gt.Equal(VALUE{}, ValueOf((*int)(nil)).Elem(), "nil Elem")
+
+	var err error = &ConversionError{From: Int, To: Bool}
+	e := ValueOf(&err).Elem()
+	gt.Equal(FromReflect(reflect.ValueOf(&err).Elem().Elem()), e.Elem(), "iface Elem")
+	gt.Equal(TypeOf(err), e.SetType().typ, "iface SetType")
+	var s fmt.Stringer = cacheStringer{"a", "b"}
+	gt.Equal("ab", ValueOf(&s).Elem().SetType().Interface().(fmt.Stringer).String(), "iface SetType")
+
+	type tagged struct {
+		A string `json:"a"`
+		B string `json:"b"`
+	}
+	vals, has := TypeOf(tagged{}).TagValues("json")
+	gt.True(has, "TagValues")
+	gt.Equal([]string{"a", "b"}, vals, "TagValues")
+	_, has = TypeOf(tagged{}).TagValues("db")
+	gt.False(has, "TagValues")
+	vals[0] = "x"
+	vals, _ = TypeOf(tagged{}).TagValues("json")
+	gt.Equal([]string{"a", "b"}, vals, "TagValues copy")
+	type partial struct {
+		A string `json:"a"`
+		B string
+		C string `json:"c"`
+	}
+	vals, has = TypeOf(partial{}).TagValues("json")
+	gt.False(has, "TagValues partial")
+	gt.Equal([]string{"a", "", ""}, vals, "TagValues partial")
+
+	gt.Equal("ab", StructOf(cacheStringer{"a", "b"}).String(), "String method")
+	m := JsonMarshaler.New()
+	m.MarshalMethods = true
+	gt.Equal(`{"C":"ab"}`, ValueOf(struct{ C cacheStringer }{cacheStringer{"a", "b"}}).Marshal(m).String(), "Marshal method")
+}
+
+func TestSyncValue(t *testing.T) {
+	gt := test.New(t, config)
+	gt.Msg = "Testing SyncValue(%s)"
+
+	const n = 100
+	var (
+		wg sync.WaitGroup
+		i  int64
+		s  []int
+		m  map[string]int
+	)
+	si, ss, sm := SyncValueOf(&i), SyncValueOf(&s), SyncValueOf(&m)
+	for g := 0; g < n; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for {
+				c := si.Interface().(int64)
+				if si.CompareAndSwap(c, c+1) {
+					break
+				}
+			}
+			ss.Append(g)
+			sm.SetIndex(fmt.Sprint(g), g)
+		}(g)
+	}
+	wg.Wait()
+	gt.Equal(int64(n), i, "CompareAndSwap")
+	gt.Equal(n, ss.Len(), "Append")
+	gt.Equal(n, sm.Len(), "SetIndex")
+	gt.Equal(7, sm.Index("7"), "Index")
+	sm.Delete("7")
+	gt.Equal(nil, sm.Index("7"), "Delete")
+	gt.Equal(int64(n), si.Swap(0), "Swap")
+	gt.Equal(int64(0), i, "Swap")
+
+	var u uint32 = 5
+	v := ValueOf(&u).Elem()
+	gt.False(v.CompareAndSwap(4, 6), "VALUE.CompareAndSwap")
+	gt.True(v.CompareAndSwap(5, 6), "VALUE.CompareAndSwap")
+	gt.Equal(uint32(6), u, "VALUE.CompareAndSwap")
+	var c int64
+	wg = sync.WaitGroup{}
+	for g := 0; g < n; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cv := ValueOf(&c).Elem()
+			for {
+				old := atomic.LoadInt64(&c)
+				if cv.CompareAndSwap(old, old+1) {
+					break
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	gt.Equal(int64(n), c, "VALUE.CompareAndSwap concurrent")
+}
+
+func TestEncodeSchema(t *testing.T) {
+	gt := test.New(t, config)
+	gt.Msg = "Testing EncodeSchema(%s)"
+
+	type inner struct {
+		X int
+		Y string
+	}
+	type v1 struct {
+		A int32
+		B string
+		C []inner
+		D map[string]inner
+		E bool
+	}
+	type v2 struct {
+		E  bool
+		B  []byte
+		F  float64
+		A  int64
+		C  []inner
+		D  map[string]*inner
+		In inner
+	}
+	a := v1{7, "b", []inner{{1, "x"}}, map[string]inner{"k": {2, "y"}}, true}
+	e := EncodeSchema(a)
+	gt.Equal(Struct, e.KIND(), "KIND")
+	gt.Equal(TypeOf(a).Fingerprint(), e.Fingerprint(), "Fingerprint")
+	gt.Equal(TypeOf(a).Schema(), e.Schema(), "Schema")
+	gt.True(TypeOf(a).Fingerprint() != TypeOf(v2{}).Fingerprint(), "Fingerprint")
+
+	var d1 v1
+	gt.Equal(len(e), e.Decode(&d1), "Decode bytes")
+	gt.Equal(a, d1, "Decode same type")
+
+	d2 := v2{F: 1.5, In: inner{3, "z"}}
+	e.Decode(&d2)
+	gt.Equal(v2{true, []byte("b"), 0, 7, []inner{{1, "x"}}, map[string]*inner{"k": {2, "y"}}, inner{}}, d2, "Decode evolved type")
+
+	var d3 struct {
+		A int
+		D map[string]struct{ X bool }
+	}
+	err := func() (err error) {
+		defer func() { err, _ = recover().(error) }()
+		e.Decode(&d3)
+		return
+	}()
+	var se *SchemaError
+	gt.True(errors.As(err, &se), "SchemaError")
+	gt.Equal("D[k].X", se.Path, "SchemaError path")
+	gt.Equal(Int, se.From, "SchemaError from")
+	gt.Equal(Bool, se.To, "SchemaError to")
+
+	e[1] = EncodingVersion + 1
+	err = func() (err error) {
+		defer func() { err, _ = recover().(error) }()
+		e.Decode(&d1)
+		return
+	}()
+	gt.Equal(ErrEncodingVersion, err, "EncodingVersion")
+}
+
+type decodeFuzz struct {
+	A int
+	B string
+	C []uint16
+	D map[string]any
+	E time.Time
+}
+
+func TestDecodeE(t *testing.T) {
+	gt := test.New(t, config)
+	gt.Msg = "Testing DecodeE(%s)"
+
+	a := decodeFuzz{1, "b", []uint16{1, 2}, map[string]any{"k": 1.5}, time.Unix(0, 1).UTC()}
+	for n, e := range map[string]ENCODING{"Encode": Encode(a), "EncodeSchema": EncodeSchema(a)} {
+		var d decodeFuzz
+		l, err := e.DecodeE(&d)
+		gt.Equal(nil, err, n)
+		gt.Equal(len(e), l, n)
+		gt.Equal(a, d, n)
+		for i := 0; i < len(e); i++ {
+			_, err := e[:i].DecodeE(&d)
+			gt.True(errors.Is(err, ErrTruncated) || errors.Is(err, ErrCorrupt), n+" truncated")
+		}
+	}
+
+	var s []int
+	_, err := Encode([]int{1}).DecodeE(s)
+	gt.True(err != nil, "non pointer dest")
+	_, err = ENCODING{byte(Slice), byte(Int), byte(Uint), 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f}.DecodeE(&s)
+	gt.True(errors.Is(err, ErrMaxLen), "ErrMaxLen")
+	_, err = ENCODING{byte(Slice), byte(Int), byte(Uint8), 0xff, byte(Int)}.DecodeE(&s)
+	gt.True(errors.Is(err, ErrTruncated), "ErrTruncated")
+	_, err = ENCODING{byte(Slice), byte(Int), byte(Uint8), 1, byte(String), byte(Uint8), 0}.DecodeE(&s)
+	gt.True(errors.Is(err, ErrCorrupt), "ErrCorrupt")
+	deep := ENCODING{}
+	for i := 0; i <= MaxDecodeDepth+1; i++ {
+		deep = append(deep, byte(Slice), byte(Slice), byte(Uint8), 1)
+	}
+	var da any
+	_, err = append(deep, byte(Slice), byte(Int), byte(Uint8), 0).DecodeE(&da)
+	gt.True(errors.Is(err, ErrMaxDepth), "ErrMaxDepth")
+}
+
+func FuzzDecodeE(f *testing.F) {
+	f.Add([]byte(Encode(decodeFuzz{1, "b", []uint16{1, 2}, map[string]any{"k": 1.5}, time.Now()})))
+	f.Add([]byte(EncodeSchema(decodeFuzz{2, "c", nil, map[string]any{"k": "v"}, time.Now()})))
+	f.Add([]byte(Encode(map[int]string{1: "a", 2: "b"})))
+	f.Add([]byte(Encode([][]any{{1, "a"}, {true}})))
+	f.Add([]byte(Encode(uuid.New())))
+	f.Add([]byte(EncodeCompressed(decodeFuzz{3, "d", []uint16{1, 2}, map[string]any{"k": "k"}, time.Now()}, CompressAll)))
+	f.Add([]byte(EncodeCompressed([]string{"ab", "ab"}, CompressDict)))
+	f.Fuzz(func(t *testing.T, b []byte) {
+		var (
+			d decodeFuzz
+			a any
+			s []any
+			m map[string]any
+		)
+		for _, dest := range []any{&d, &a, &s, &m} {
+			ENCODING(b).DecodeE(dest)
+		}
+	})
+}
+
+func TestEncodeKey(t *testing.T) {
+	gt := test.New(t, config)
+	gt.Msg = "Testing EncodeKey(%s)"
+
+	u1, u2 := uuid.MustParse("00000000-0000-4000-8000-000000000000"), uuid.MustParse("ffffffff-0000-4000-8000-000000000000")
+	sorted := map[string][]any{
+		"int":    {math.MinInt64, -256, -1, 0, 1, 255, math.MaxInt64},
+		"uint":   {uint(0), uint8(1), uint16(256), uint64(math.MaxUint64)},
+		"float":  {math.Inf(-1), -1.5, -math.SmallestNonzeroFloat64, 0.0, float32(0.5), 1.5, math.Inf(1)},
+		"string": {"", "\x00", "\x00\x00", "\x00a", "a", "a\x00", "a\x00b", "ab", "b"},
+		"bytes":  {[]byte{}, []byte{0}, []byte{0, 1}, []byte{1}, []byte{0xff}},
+		"time":   {time.Time{}, time.Unix(-1, 0), time.Unix(0, 0), time.Unix(0, 1), time.Unix(1, 0), time.Date(2500, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(9999, 12, 31, 23, 59, 59, 999999999, time.UTC)},
+		"uuid":   {u1, u2},
+		"bool":   {false, true},
+	}
+	for n, vals := range sorted {
+		for i := 1; i < len(vals); i++ {
+			a, b := EncodeKey(vals[i-1]), EncodeKey(vals[i])
+			gt.Equal(-1, bytes.Compare(a, b), n)
+			da, db := EncodeKey(Desc(vals[i-1])), EncodeKey(Desc(vals[i]))
+			gt.Equal(1, bytes.Compare(da, db), n+" desc")
+		}
+	}
+
+	// tuples compare by their first differing component
+	keys := []KEY{
+		EncodeKey("a", Desc(2), int64(1)),
+		EncodeKey("a", Desc(2), int64(2)),
+		EncodeKey("a", Desc(1), int64(0)),
+		EncodeKey("a\x00", Desc(9), int64(0)),
+		EncodeKey("b", nil, int64(0)),
+		EncodeKey("b", Desc(5), int64(0)),
+	}
+	for i := 1; i < len(keys); i++ {
+		gt.Equal(-1, bytes.Compare(keys[i-1], keys[i]), "tuple")
+	}
+
+	tm := time.Unix(100, 5).UTC()
+	k := EncodeKey(-7, Desc("x\x00y"), uint8(3), Desc(-2.5), []byte{0, 1}, Desc(tm), UUID(u1), true, nil)
+	vals, err := DecodeKey(k)
+	gt.Equal(nil, err, "DecodeKey")
+	gt.Equal([]any{int64(-7), "x\x00y", uint64(3), -2.5, []byte{0, 1}, TIME(tm), UUID(u1), true, nil}, vals, "DecodeKey")
+
+	var (
+		i  int32
+		s  string
+		u  uint
+		f  float32
+		b  []byte
+		ts time.Time
+		id uuid.UUID
+		ok bool
+		p  *int
+	)
+	gt.Equal(nil, k.Decode(&i, &s, &u, &f, &b, &ts, &id, &ok, &p), "Decode")
+	gt.Equal(int32(-7), i, "Decode")
+	gt.Equal("x\x00y", s, "Decode")
+	gt.Equal(uint(3), u, "Decode")
+	gt.Equal(float32(-2.5), f, "Decode")
+	gt.Equal(tm, ts, "Decode")
+	gt.Equal(u1, id, "Decode")
+	gt.True(ok, "Decode")
+
+	for _, tm := range []time.Time{{}, time.Date(1500, 6, 1, 0, 0, 0, 1, time.UTC), time.Date(9999, 12, 31, 23, 59, 59, 999999999, time.UTC)} {
+		vals, err := DecodeKey(EncodeKey(tm, Desc(tm)))
+		gt.Equal(nil, err, "DecodeKey time")
+		gt.Equal([]any{TIME(tm), TIME(tm)}, vals, "DecodeKey time")
+	}
+
+	_, err = DecodeKey(k[:len(k)-5])
+	gt.True(errors.Is(err, ErrTruncated), "truncated")
+	_, err = DecodeKey(KEY{0x42})
+	gt.True(errors.Is(err, ErrCorrupt), "corrupt")
+	gt.True(errors.Is(EncodeKey(1).Decode(&i, &s), ErrTruncated), "fewer components")
+}
+
+func TestEncodingStream(t *testing.T) {
+	gt := test.New(t, config)
+	gt.Msg = "Testing EncodingStream(%s)"
+
+	type record struct {
+		ID   int
+		Name string
+		Tags []string
+	}
+	recs := []record{{1, "a", []string{"x"}}, {2, strings.Repeat("b", 300), nil}, {3, "c", []string{"y", "z"}}}
+	for _, crc := range []bool{false, true} {
+		var buf bytes.Buffer
+		w := NewEncodingWriter(&buf, crc)
+		for _, r := range recs {
+			gt.Equal(nil, w.Encode(r), "Encode")
+		}
+		gt.Equal(nil, w.Write(EncodeSchema(recs[0])), "Write")
+		b := buf.Bytes()
+
+		r := NewEncodingReader(bytes.NewReader(b))
+		for _, want := range recs {
+			var got record
+			gt.Equal(nil, r.Decode(&got), "Decode")
+			gt.Equal(want, got, "Decode")
+		}
+		var got struct{ Name string }
+		gt.Equal(nil, r.Decode(&got), "Decode schema")
+		gt.Equal("a", got.Name, "Decode schema")
+		gt.Equal(io.EOF, r.Decode(&got), "EOF")
+
+		n := 0
+		gt.Equal(nil, NewEncodingReader(bytes.NewReader(b)).ForEach(func(i int, e ENCODING) (brake bool) {
+			n++
+			return
+		}), "ForEach")
+		gt.Equal(len(recs)+1, n, "ForEach")
+
+		err := NewEncodingReader(bytes.NewReader(b[:len(b)-1])).ForEach(func(i int, e ENCODING) bool { return false })
+		gt.True(errors.Is(err, ErrTruncated), "truncated")
+		if crc {
+			c := append([]byte{}, b...)
+			c[5] ^= 0xff
+			_, err = NewEncodingReader(bytes.NewReader(c)).Next()
+			gt.True(errors.Is(err, ErrChecksum), "checksum")
+		}
+	}
+}
+
+type encShape struct {
+	W int
+	H int
+}
+
+func TestEncodePointers(t *testing.T) {
+	gt := test.New(t, config)
+	gt.Msg = "Testing EncodePointers(%s)"
+
+	x, y := 1, 0
+	type ptrs struct {
+		Nil  *int
+		Zero *int
+		A, B *int
+		S    []*int
+		I    any
+	}
+	p := ptrs{nil, &y, &x, &x, []*int{&x, nil}, nil}
+	d := ptrs{Nil: &x, I: 5}
+	e := Encode(p)
+	_, err := e.DecodeE(&d)
+	gt.Equal(nil, err, "DecodeE")
+	gt.True(d.Nil == nil, "nil pointer")
+	gt.True(d.Zero != nil && *d.Zero == 0, "zero pointer")
+	gt.True(d.A == d.B && *d.A == 1, "shared pointer")
+	gt.True(d.S[0] == d.A && d.S[1] == nil, "shared pointer in slice")
+	gt.Equal(nil, d.I, "nil interface")
+
+	a := [2]string{"a", "b"}
+	var da *[2]string
+	_, err = Encode(&a).DecodeE(&da)
+	gt.Equal(nil, err, "pointer to array")
+	gt.Equal(a, *da, "pointer to array")
+	type arrPtrs struct {
+		P *[2]string
+		S []*[1]string
+		M map[string]*[1]string
+	}
+	ap := arrPtrs{&a, []*[1]string{{"s"}, nil}, map[string]*[1]string{"m": {"m"}}}
+	var dap arrPtrs
+	_, err = Encode(ap).DecodeE(&dap)
+	gt.Equal(nil, err, "pointers to arrays")
+	gt.Equal(ap, dap, "pointers to arrays")
+
+	type w struct{ A int }
+	for name, e := range map[string]ENCODING{
+		"nil pointer":        Encode((*w)(nil)),
+		"nil pointer schema": EncodeSchema((*w)(nil)),
+	} {
+		dp := &w{1}
+		_, err = e.DecodeE(&dp)
+		gt.Equal(nil, err, name)
+		gt.True(dp == nil, name)
+	}
+	for name, e := range map[string]ENCODING{
+		"nil interface":        Encode(nil),
+		"nil interface schema": EncodeSchema(nil),
+	} {
+		var di any = &w{1}
+		_, err = e.DecodeE(&di)
+		gt.Equal(nil, err, name)
+		gt.Equal(nil, di, name)
+	}
+
+	type node struct {
+		V    int
+		Next *node
+	}
+	n1 := &node{V: 1}
+	n1.Next = &node{2, n1}
+	var dn *node
+	_, err = Encode(n1).DecodeE(&dn)
+	gt.Equal(nil, err, "cycle")
+	gt.Equal(1, dn.V, "cycle")
+	gt.Equal(2, dn.Next.V, "cycle")
+	gt.True(dn.Next.Next == dn, "cycle")
+	var dv node
+	Encode(n1).Decode(&dv)
+	gt.True(dv.Next.Next == &dv, "cycle to value")
+
+	type holder struct {
+		S any
+		L []any
+		M map[string]any
+	}
+	h := holder{encShape{2, 3}, []any{encShape{4, 5}, nil}, map[string]any{"s": &encShape{6, 7}}}
+	var dh holder
+	Encode(h).Decode(&dh)
+	gt.Equal([]any{2, 3}, dh.S, "unregistered interface")
+	RegisterType(encShape{})
+	gt.Equal(TypeOf(encShape{}), TypeByName("gotype.encShape"), "TypeByName")
+	dh = holder{}
+	_, err = Encode(h).DecodeE(&dh)
+	gt.Equal(nil, err, "registered interface")
+	gt.Equal(h.S, dh.S, "registered interface")
+	gt.Equal(h.L, dh.L, "registered interface")
+	gt.Equal(encShape{6, 7}, *dh.M["s"].(*encShape), "registered interface pointer")
+
+	e = Encode(holder{S: encShape{}})
+	namedTypes.Delete("gotype.encShape")
+	_, err = e.DecodeE(&dh)
+	gt.True(errors.Is(err, ErrUnregisteredType), "unregistered type")
+	namedTypes.Store("gotype.encShape", TypeOf(encShape{}))
+}
+
+func TestEncodingView(t *testing.T) {
+	gt := test.New(t, config)
+	gt.Msg = "Testing EncodingView(%s)"
+
+	type item struct {
+		Name string
+		Qty  *int
+	}
+	type order struct {
+		ID    int
+		Items []item
+		Meta  map[string]int32
+	}
+	qty := 5
+	o := order{7, []item{{"a", nil}, {"b", &qty}, {"c", nil}}, map[string]int32{"x": 1, "y": 2}}
+
+	v, err := EncodeSchema(o).View()
+	gt.Equal(nil, err, "View")
+	gt.Equal(Struct, v.KIND(), "KIND")
+	gt.Equal(TypeOf(o).Fingerprint(), fingerprint(v.Schema().encode(nil)), "Schema")
+	it, err := v.Field("Items")
+	gt.Equal(nil, err, "Field")
+	n, err := it.Len()
+	gt.Equal(nil, err, "Len")
+	gt.Equal(3, n, "Len")
+	b, _ := it.Index(1)
+	q, err := b.Field("Qty")
+	gt.Equal(nil, err, "Field pointer")
+	gt.Equal(Int, q.KIND(), "Field pointer")
+	var i int
+	_, err = q.Decode(&i)
+	gt.Equal(nil, err, "Decode")
+	gt.Equal(5, i, "Decode")
+	var bi item
+	_, err = b.Decode(&bi)
+	gt.Equal(nil, err, "Decode struct")
+	gt.Equal("b", bi.Name, "Decode struct")
+	c, _ := it.Index(2)
+	nq, _ := c.Field("Qty")
+	gt.Equal(Invalid, nq.KIND(), "nil pointer")
+
+	m, _ := v.Field("Meta")
+	y, err := m.Key("y")
+	gt.Equal(nil, err, "Key")
+	var yv int32
+	_, err = y.Decode(&yv)
+	gt.Equal(int32(2), yv, "Key")
+	ints, _ := Encode(map[int16]string{3: "c", -1: "z"}).View()
+	z, err := ints.Key(-1)
+	gt.Equal(nil, err, "Key converted")
+	e, _ := z.Encoding()
+	gt.Equal(Encode("z"), e, "Encoding")
+
+	_, err = m.Key("w")
+	gt.True(errors.Is(err, ErrNoElem), "missing key")
+	_, err = it.Index(3)
+	gt.True(errors.Is(err, ErrNoElem), "index out of range")
+	_, err = v.Field("None")
+	gt.True(errors.Is(err, ErrNoElem), "missing field")
+	nv, _ := Encode(o).View()
+	_, err = nv.Field("ID")
+	gt.True(errors.Is(err, ErrNoElem), "field without schema")
+	f, err := nv.WithSchema(TypeOf(o).Schema()).Field("ID")
+	gt.Equal(nil, err, "WithSchema")
+	_, err = f.Decode(&i)
+	gt.Equal(7, i, "WithSchema")
+	_, err = f.Index(0)
+	gt.True(errors.Is(err, ErrNoElem), "index of int")
+
+	type refs struct{ P, Q *int }
+	ra, rb := 1, 2
+	rv, _ := Encode([]refs{{&ra, &ra}, {&rb, &ra}}).View()
+	r0, _ := rv.Index(0)
+	var rd refs
+	_, err = r0.Decode(&rd)
+	gt.True(errors.Is(err, ErrViewRef), "Decode references")
+	r1, _ := rv.Index(1)
+	_, err = r1.Decode(&rd)
+	gt.True(errors.Is(err, ErrViewRef), "Decode outer references")
+	rp, _ := r1.Index(0)
+	_, err = rp.Decode(&i)
+	gt.Equal(nil, err, "Decode without references")
+	gt.Equal(2, i, "Decode without references")
+
+	enc := EncodeSchema(o)
+	allocs := testing.AllocsPerRun(100, func() {
+		it, _ := v.Field("Items")
+		c, _ := it.Index(2)
+		e, _ := c.Encoding()
+		_ = e
+	})
+	gt.Equal(0.0, allocs, "allocations")
+	tv, err := ENCODING(enc[:len(enc)-4]).View()
+	gt.Equal(nil, err, "View truncated")
+	tm, _ := tv.Field("Meta")
+	_, err = tm.Key("w")
+	gt.True(errors.Is(err, ErrTruncated), "truncated")
+}
+
+func TestEncodeCompressed(t *testing.T) {
+	gt := test.New(t, config)
+	gt.Msg = "Testing EncodeCompressed(%s)"
+
+	type event struct {
+		ID   int64
+		Kind string
+		At   time.Time
+		Tags []string
+		Seq  []uint64
+	}
+	t0 := time.Unix(1700000000, 0).UTC()
+	evs := make([]event, 50)
+	for i := range evs {
+		evs[i] = event{int64(1000 + i), []string{"create", "update", "delete"}[i%3], t0.Add(time.Duration(i) * time.Second), []string{"user"}, []uint64{uint64(i), uint64(i + 1), uint64(i + 5)}}
+	}
+	vals := map[string]any{
+		"events":   evs,
+		"negative": []int16{-300, -2, 0, 7},
+		"unsorted": []int{5, -1, 3},
+		"times":    []time.Time{t0, t0.Add(time.Hour)},
+		"map":      map[string]int32{"alpha": 1, "beta": -2},
+		"pointers": []*event{&evs[0], &evs[0], nil},
+		"empty":    []string{"", "", "a", "a"},
+	}
+	for n, v := range vals {
+		e := Encode(v)
+		for _, c := range []Compression{0, CompressVarint, CompressDelta, CompressDict, CompressAll} {
+			z := EncodeCompressed(v, c)
+			gt.Equal(CompressedHeader, z[0], n+" header")
+			gt.Equal(e.KIND(), z.KIND(), n+" KIND")
+			d, err := z.Decompress()
+			gt.Equal(nil, err, n+" Decompress")
+			gt.Equal(e, d, n+" Decompress")
+			gt.Equal(z, d.Compress(c), n+" Compress")
+			nz, err := z.Validate()
+			gt.Equal(nil, err, n+" Validate")
+			gt.Equal(len(z), nz, n+" Validate")
+		}
+	}
+
+	var got []event
+	z := EncodeCompressed(evs, CompressAll)
+	gt.True(len(z) < len(Encode(evs))*2/3, "size")
+	n, err := z.DecodeE(&got)
+	gt.Equal(nil, err, "DecodeE")
+	gt.Equal(len(z), n, "DecodeE")
+	gt.Equal(evs, got, "DecodeE")
+	got = nil
+	gt.Equal(len(z), z.Decode(&got), "Decode")
+	gt.Equal(evs, got, "Decode")
+	gt.Equal(len(evs), len(z.Decodex().v.Interface().([]any)), "Decodex")
+
+	zs := EncodeSchema(evs[1]).Compress(CompressAll)
+	gt.Equal(TypeOf(evs[1]).Fingerprint(), zs.Fingerprint(), "Fingerprint")
+	var ev struct{ Kind string }
+	_, err = zs.DecodeE(&ev)
+	gt.Equal(nil, err, "DecodeE schema")
+	gt.Equal("update", ev.Kind, "DecodeE schema")
+	vw, err := zs.View()
+	gt.Equal(nil, err, "View")
+	at, _ := vw.Field("At")
+	var tm time.Time
+	at.Decode(&tm)
+	gt.True(tm.Equal(evs[1].At), "View")
+
+	for i := 2; i < len(z); i += 7 {
+		if _, err = ENCODING(z[:i]).DecodeE(&got); !errors.Is(err, ErrTruncated) {
+			t.Fatalf("DecodeE truncated at %d: %v", i, err)
+		}
+	}
+	_, err = ENCODING{CompressedHeader, 0xff, byte(Int8), 1}.DecodeE(&n)
+	gt.True(errors.Is(err, ErrCorrupt), "corrupt options")
+	_, err = ENCODING{CompressedHeader, byte(CompressDict), byte(String), 0, 0}.DecodeE(new(string))
+	gt.True(errors.Is(err, ErrCorrupt), "corrupt dictionary id")
+}
+
+func TestComplex(t *testing.T) {
+	gt := test.New(t, config)
+	gt.Msg = "Testing Complex(%s)"
+
+	type wave struct {
+		Amp   complex128
+		Phase complex64
+		Addr  uintptr
+	}
+	w := wave{1.5 - 2i, 0.5 + 1i, 0xdead}
+	for _, v := range []any{complex64(1 + 2i), complex128(-3.25 + 0.5i), uintptr(42), w, []complex128{1i, 2}, map[string]complex64{"a": 3i}} {
+		p := reflect.New(reflect.TypeOf(v))
+		n, err := Encode(v).DecodeE(p.Interface())
+		gt.Equal(nil, err, "DecodeE")
+		gt.Equal(len(Encode(v)), n, "DecodeE")
+		gt.Equal(v, p.Elem().Interface(), "Decode")
+		p = reflect.New(reflect.TypeOf(v))
+		EncodeCompressed(v, CompressAll).Decode(p.Interface())
+		gt.Equal(v, p.Elem().Interface(), "Decode compressed")
+	}
+	gt.Equal(complex128(1.5-2i), Encode(w.Amp).Decodex().v.Interface(), "Decodex")
+
+	var c128 complex128
+	ValueOf(&c128).Set(complex64(2 + 3i))
+	gt.Equal(complex128(2+3i), c128, "Set complex64")
+	ValueOf(&c128).Set("4-1i")
+	gt.Equal(complex128(4-1i), c128, "Set string")
+	ValueOf(&c128).Set([]float64{5, 6})
+	gt.Equal(complex128(5+6i), c128, "Set [re, im]")
+	ValueOf(&c128).Set(7)
+	gt.Equal(complex128(7), c128, "Set int")
+	var c64 complex64
+	ValueOf(&c64).Set(c128)
+	gt.Equal(complex64(7), c64, "Set complex128")
+	var up uintptr
+	ValueOf(&up).Set(int8(9))
+	gt.Equal(uintptr(9), up, "Set uintptr")
+	var f float64
+	_, err := ValueOf(&f).SetWith(1+2i, ConvertDefault)
+	gt.True(errors.Is(err, ErrPrecisionLoss), "SetWith imaginary")
+	ValueOf(&f).Set(3 + 0i)
+	gt.Equal(3.0, f, "Set real complex")
+
+	gt.Equal(complex128(1+2i), ValueOf("(1+2i)").Cast(Complex128), "Cast string")
+	gt.Equal(complex64(2), ValueOf(2).Cast(Complex64), "Cast int")
+	gt.Equal("1+2i", ValueOf(1+2i).Cast(String), "Cast to string")
+	gt.Equal(uintptr(3), ValueOf(3.0).Cast(Uintptr), "Cast uintptr")
+	gt.Equal(int8(3), ValueOf(uintptr(3)).Cast(Int8), "Cast from uintptr")
+
+	c := COMPLEX(3 + 4i)
+	gt.Equal(5.0, c.Abs(), "Abs")
+	gt.Equal(complex128(3-4i), c.Conj(), "Conj")
+	gt.Equal(complex128(4+6i), c.Add(1+2i), "Add")
+	gt.Equal(complex128(2+2i), c.Sub(1+2i), "Sub")
+	gt.Equal(complex128(-5+10i), c.Multiply(1+2i), "Multiply")
+	gt.Equal(complex128(1), c.Divide(3+4i), "Divide")
+	gt.True(COMPLEX(c.Pow(2)-(-7+24i)).Abs() < 1e-9, "Pow")
+	gt.Equal(complex128(2+1i), c.Sqrt(), "Sqrt")
+	gt.Equal([2]float64{3, 4}, c.Array(), "Array")
+	gt.Equal("3+4i", c.String(), "String")
+
+	gt.Equal(`{"Amp":"1.5-2i","Phase":"0.5+1i","Addr":57005}`, ValueOf(w).Marshal(JsonMarshaler).String(), "json string")
+	jm := JsonMarshaler.New()
+	jm.ComplexAsArray = true
+	gt.Equal(`{"Amp":[1.5,-2],"Phase":[0.5,1],"Addr":57005}`, ValueOf(w).Marshal(jm).String(), "json array")
+	for _, m := range []*Marshaler{JsonMarshaler.New(), jm, YamlMarshaler.New()} {
+		var got wave
+		b := ValueOf(w).Marshal(m).Bytes()
+		gt.Equal(nil, m.UnmarshalInto(&got, b), "UnmarshalInto "+m.Type)
+		gt.Equal(w, got, "UnmarshalInto "+m.Type)
+	}
+	gt.Equal(`["1+2i"]`, ValueOf([]any{1 + 2i}).json(), "json")
+}
+
+func TestTypeBuilders(t *testing.T) {
+	gt := test.New(t, config)
+	gt.Msg = "Testing TypeBuilders(%s)"
+
+	row := StructTypeOf([]FieldSpec{
+		{Name: "Id", Type: TypeOf(0), Tag: `json:"id" yaml:"id"`},
+		{Name: "Name", Type: TypeOf(""), Tag: `json:"name" yaml:"name"`},
+		{Name: "Tags", Type: SliceTypeOf(TypeOf("")), Tag: `json:"tags" yaml:"tags"`},
+		{Name: "Attrs", Type: MapTypeOf(TypeOf(""), TypeOf(0.0)), Tag: `json:"attrs" yaml:"attrs"`},
+		{Name: "Point", Type: ArrayTypeOf(2, TypeOf(int8(0))), Tag: `json:"point" yaml:"point"`},
+		{Name: "Next", Type: PtrTypeOf(TypeOf(0)), Tag: `json:"next" yaml:"next"`},
+	})
+	gt.Equal(Struct, row.KIND(), "StructTypeOf kind")
+	gt.Equal(6, row.NumField(), "NumField")
+	gt.Equal("name", row.Field(1).TagValue("json"), "TagValue")
+	gt.Equal(row, StructTypeOf([]FieldSpec{
+		{Name: "Id", Type: TypeOf(0), Tag: `json:"id" yaml:"id"`},
+		{Name: "Name", Type: TypeOf(""), Tag: `json:"name" yaml:"name"`},
+		{Name: "Tags", Type: SliceTypeOf(TypeOf("")), Tag: `json:"tags" yaml:"tags"`},
+		{Name: "Attrs", Type: MapTypeOf(TypeOf(""), TypeOf(0.0)), Tag: `json:"attrs" yaml:"attrs"`},
+		{Name: "Point", Type: ArrayTypeOf(2, TypeOf(int8(0))), Tag: `json:"point" yaml:"point"`},
+		{Name: "Next", Type: PtrTypeOf(TypeOf(0)), Tag: `json:"next" yaml:"next"`},
+	}), "StructTypeOf identical")
+	gt.Equal(TypeOf([]string{}), SliceTypeOf(TypeOf("")), "SliceTypeOf")
+	gt.Equal(TypeOf(map[string]float64{}), MapTypeOf(TypeOf(""), TypeOf(0.0)), "MapTypeOf")
+	gt.Equal(TypeOf([2]int8{}), ArrayTypeOf(2, TypeOf(int8(0))), "ArrayTypeOf")
+	gt.Equal(TypeOf(new(int)), PtrTypeOf(TypeOf(0)), "PtrTypeOf")
+
+	p, next := row.New(), 8
+	ValueOf(map[string]any{"Id": 7, "Name": "seven", "Tags": []string{"a", "b"}, "Attrs": map[string]float64{"x": 1.5}, "Point": [2]int8{1, -1}}).MAP().Scan(p.Interface())
+	v := p.Elem()
+	s := v.STRUCT().Set(5, &next)
+	gt.Equal(7, s.Field("Id").Interface(), "Set Id")
+	gt.Equal([]string{"a", "b"}, s.Field("Tags").Interface(), "Set Tags")
+	gt.Equal([2]int8{1, -1}, s.Field("Point").Interface(), "Set Point")
+	js := `{"id":7,"name":"seven","tags":["a","b"],"attrs":{"x":1.5},"point":[1,-1],"next":8}`
+	gt.Equal(js, v.Marshal(JsonMarshaler).String(), "Marshal")
+
+	u := row.New()
+	gt.Equal(nil, JsonMarshaler.New().UnmarshalInto(u.Interface(), []byte(js)), "UnmarshalInto")
+	gt.Equal(v.Interface(), u.Elem().Interface(), "UnmarshalInto")
+	yu := row.New()
+	gt.Equal(nil, YamlMarshaler.New().UnmarshalInto(yu.Interface(), v.Marshal(YamlMarshaler).Bytes()), "UnmarshalInto yaml")
+	gt.Equal(v.Interface(), yu.Elem().Interface(), "UnmarshalInto yaml")
+
+	d := row.New()
+	_, err := v.Encode().DecodeE(d.Interface())
+	gt.Equal(nil, err, "DecodeE")
+	gt.Equal(v.Interface(), d.Elem().Interface(), "Decode")
+
+	rows := NewSlice(row, 2)
+	rows.index(1).Set(v)
+	gt.Equal(SliceTypeOf(row), rows.typ, "NewSlice")
+	rows.index(0).Set(u.Elem())
+	gt.Equal(`[`+js+`,`+js+`]`, rows.VALUE().Marshal(JsonMarshaler).String(), "Marshal slice")
+
+	for name, f := range map[string]func(){
+		"StructTypeOf nil type":      func() { StructTypeOf([]FieldSpec{{Name: "Id"}}) },
+		"StructTypeOf unexported":    func() { StructTypeOf([]FieldSpec{{Name: "id", Type: TypeOf(0)}}) },
+		"MapTypeOf uncomparable key": func() { MapTypeOf(SliceTypeOf(TypeOf(0)), TypeOf(0)) },
+	} {
+		var r any
+		func() {
+			defer func() { r = recover() }()
+			f()
+		}()
+		gt.True(r != nil, name)
+	}
+}
+
+func TestTypeRegistry(t *testing.T) {
+	gt := test.New(t, config)
+	gt.Msg = "Testing TypeRegistry(%s)"
+
+	type invoice struct {
+		Id    int     `json:"id" yaml:"id"`
+		Total float64 `json:"total" yaml:"total"`
+	}
+	type credit float64
+	type doc struct {
+		Name  string         `json:"name" yaml:"name"`
+		Item  any            `json:"item" yaml:"item"`
+		Items []any          `json:"items" yaml:"items"`
+		Refs  map[string]any `json:"refs" yaml:"refs"`
+	}
+	RegisterTypeName("billing.Invoice", invoice{})
+	RegisterTypeName("billing.Credit", credit(0))
+	gt.Equal(TypeOf(invoice{}), TypeByName("billing.Invoice"), "TypeByName")
+	gt.Equal(TypeOf(&invoice{}), TypeByName("*billing.Invoice"), "TypeByName pointer")
+	gt.Equal((*TYPE)(nil), TypeByName("billing.Unknown"), "TypeByName unregistered")
+	n, ok := TypeOf(&invoice{}).RegisteredName()
+	gt.Equal("*billing.Invoice", n, "RegisteredName pointer")
+	gt.True(ok, "RegisteredName pointer")
+
+	d := doc{"q1", invoice{1, 9.5}, []any{&invoice{2, 3}, credit(1.25), "memo"}, map[string]any{"c": credit(-2)}}
+	js := `{"name":"q1","item":{"$type":"billing.Invoice","id":1,"total":9.5},` +
+		`"items":[{"$type":"*billing.Invoice","id":2,"total":3},{"$type":"billing.Credit","$value":1.25},"memo"],` +
+		`"refs":{"c":{"$type":"billing.Credit","$value":-2}}}`
+	jm, ym := JsonMarshaler.New(), YamlMarshaler.New()
+	gt.Equal(js, ValueOf(d).Marshal(JsonMarshaler).String(), "Marshal json preset")
+	gt.Equal(js, ValueOf(d).Marshal(jm).String(), "Marshal json")
+	for _, m := range []*Marshaler{jm, ym} {
+		var got doc
+		gt.Equal(nil, m.UnmarshalInto(&got, ValueOf(d).Marshal(m).Bytes()), "UnmarshalInto "+m.Type)
+		gt.Equal(d, got, "UnmarshalInto "+m.Type)
+	}
+
+	var got doc
+	e := Encode(d)
+	_, err := e.DecodeE(&got)
+	gt.Equal(nil, err, "DecodeE")
+	gt.Equal(d, got, "Decode")
+
+	nm := JsonMarshaler.New()
+	nm.TypeKey = ""
+	gt.Equal(`{"item":{"id":1}}`, ValueOf(doc{Item: invoice{Id: 1}}).Marshal(nm).String(), "Marshal without TypeKey")
+	var in doc
+	gt.Equal(nil, jm.UnmarshalInto(&in, []byte(`{"item":{"$type":"billing.Unknown","id":1}}`)), "UnmarshalInto unregistered")
+	gt.Equal(map[string]any{"$type": "billing.Unknown", "id": "1"}, in.Item, "UnmarshalInto unregistered")
+	var node map[string]any
+	gt.Equal(nil, JsonMarshaler.New().UnmarshalInto(&node, []byte(`{"node":{"$type":"Microsoft.Foo","v":1}}`)), "UnmarshalInto unregistered map")
+	gt.Equal(map[string]any{"$type": "Microsoft.Foo", "v": "1"}, node["node"], "UnmarshalInto unregistered map")
+	gt.Equal(nil, nm.UnmarshalInto(&in, []byte(`{"item":{"$type":"billing.Invoice","id":1}}`)), "UnmarshalInto without TypeKey")
+	gt.Equal(map[string]any{"$type": "billing.Invoice", "id": "1"}, in.Item, "UnmarshalInto without TypeKey")
+	in = doc{Item: invoice{Id: 4, Total: 1}}
+	gt.Equal(nil, Merge(&in, map[string]any{"item": map[string]any{"$type": "billing.Invoice", "total": 2}}, MergeTypeKey("$type")), "Merge into typed")
+	gt.Equal(invoice{4, 2}, in.Item, "Merge into typed")
+	in.Item = nil
+	gt.Equal(nil, Merge(&in, map[string]any{"item": map[string]any{"$type": "billing.Invoice"}}), "Merge without type key")
+	gt.Equal(map[string]any{"$type": "billing.Invoice"}, in.Item, "Merge without type key")
+
+	type other int
+	func() {
+		defer func() { gt.True(recover() != nil, "register name of another type") }()
+		RegisterTypeName("billing.Invoice", other(0))
+	}()
+	_, ok = TypeOf(other(0)).RegisteredName()
+	gt.False(ok, "failed registration not stored")
+	func() {
+		defer func() { gt.True(recover() != nil, "register type by another name") }()
+		RegisterTypeName("billing.Other", invoice{})
+	}()
+	gt.Equal((*TYPE)(nil), TypeByName("billing.Other"), "failed registration not stored")
+	n, _ = TypeOf(credit(0)).RegisteredName()
+	gt.Equal("billing.Credit", n, "failed registration not stored")
+}
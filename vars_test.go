@@ -4,6 +4,13 @@
 
 package gotype
 
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+)
+
 type bool_struct_single struct {
 	V1 bool
 }
@@ -687,3 +694,59 @@ Messages:
 	  - User.Name
 	  - User.Email
 `
+
+// fakeDriver is an in memory sql driver returning the
+// columns and rows of fakeTables keyed by query
+type fakeDriver struct{}
+
+type fakeTable struct {
+	cols []string
+	rows [][]driver.Value
+}
+
+var fakeTables = map[string]fakeTable{}
+
+func init() {
+	sql.Register("gotypefake", fakeDriver{})
+}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) { return fakeConn{}, nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{query}, nil }
+func (fakeConn) Close() error                              { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+
+type fakeStmt struct {
+	query string
+}
+
+func (fakeStmt) Close() error  { return nil }
+func (fakeStmt) NumInput() int { return -1 }
+func (fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	t, ok := fakeTables[s.query]
+	if !ok {
+		return nil, errors.New("unknown query '" + s.query + "'")
+	}
+	return &fakeRows{t, 0}, nil
+}
+
+type fakeRows struct {
+	fakeTable
+	i int
+}
+
+func (r *fakeRows) Columns() []string { return r.cols }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.i >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.i])
+	r.i++
+	return nil
+}
@@ -0,0 +1,232 @@
+// Copyright 2023 james dotter. All rights reserved.
+// Use of this source code is governed by a
+// license that can be found in the gotype LICENSE file.
+
+package gotype
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// ------------------------------------------------------------ /
+// GOTYPE ENCODING VIEWS
+// lazy random access into the elems of an encoding without
+// decoding it, by skipping over sibling elems using the
+// length prefixes of the encoding
+// ------------------------------------------------------------ /
+
+var (
+	ErrNoElem  = errors.New("encoding has no such elem")
+	ErrViewRef = errors.New("cannot view pointer reference")
+)
+
+// EncodingView is a view of a value in an encoding, which indexes into
+// the encoding without decoding or allocating. Pointers and values held
+// in interfaces are viewed as their elem, pointers encoded as references
+// to earlier pointers cannot be viewed
+type EncodingView struct {
+	e ENCODING // the encoding viewed into
+	i int      // offset of the viewed value in the encoding
+	s *Schema  // schema of the viewed value, nil if unknown
+}
+
+// View returns a view of the value of the encoding, with the schema of
+// its header if it has one. Compressed encodings are decompressed to
+// be viewed. Returns a DecodeError if the header or the prefix of the
+// value is truncated or corrupt
+func (e ENCODING) View() (EncodingView, error) {
+	e, err := e.Decompress()
+	if err != nil {
+		return EncodingView{}, err
+	}
+	n, err := e.validateHeader()
+	if err != nil {
+		return EncodingView{}, err
+	}
+	var s *Schema
+	if n > 0 {
+		s, _, _ = e.header()
+	}
+	return EncodingView{e: e}.at(n, s)
+}
+
+// WithSchema returns the view with schema s describing
+// its value, for viewing fields of encodings without a header
+func (v EncodingView) WithSchema(s *Schema) EncodingView {
+	v.s = s
+	return v
+}
+
+// Schema returns the schema of the viewed value, nil if unknown
+func (v EncodingView) Schema() *Schema {
+	return v.s
+}
+
+// Offset returns the offset of the viewed value in the encoding
+func (v EncodingView) Offset() int {
+	return v.i
+}
+
+// KIND returns the kind of the viewed value
+func (v EncodingView) KIND() KIND {
+	if v.i >= len(v.e) {
+		return Invalid
+	}
+	return KIND(v.e[v.i])
+}
+
+// at returns the view of the value at offset i of the encoding
+// with schema s, skipping pointer and type name prefixes
+func (v EncodingView) at(i int, s *Schema) (EncodingView, error) {
+	for {
+		if err := v.e.need(i + 1); err != nil {
+			return EncodingView{}, err
+		}
+		switch v.e[i] {
+		case Pointer.Byte():
+			i++
+		case encTyped:
+			l, n, err := v.e.validateLen(i+1, 1)
+			if err != nil {
+				return EncodingView{}, err
+			}
+			i = n + l
+		case encRef:
+			return EncodingView{}, &DecodeError{i, ErrViewRef}
+		default:
+			return EncodingView{v.e, i, s}, nil
+		}
+	}
+}
+
+// elems returns the kind and length of the viewed value and the
+// offset of its first elem or byte, returns a DecodeError if the
+// value is not a string, bytes, container or struct
+func (v EncodingView) elems() (k KIND, l int, n int, err error) {
+	k = v.KIND()
+	switch k {
+	case String, Bytes, Struct:
+		l, n, err = v.e.validateLen(v.i+1, 1)
+	case Array, Slice:
+		l, n, err = v.e.validateLen(v.i+2, 1)
+	case Map:
+		l, n, err = v.e.validateLen(v.i+3, 1)
+	default:
+		err = &DecodeError{v.i, fmt.Errorf("%w: %s has no elems", ErrNoElem, k)}
+	}
+	return
+}
+
+// Len returns the number of elems of the viewed container or struct,
+// or bytes of the viewed string or bytes
+func (v EncodingView) Len() (int, error) {
+	_, l, _, err := v.elems()
+	return l, err
+}
+
+// Index returns a view of elem i of the viewed array, slice or struct,
+// skipping over the elems before it. Returns a DecodeError if the value
+// has no elem i or the encoding is truncated or corrupt
+func (v EncodingView) Index(i int) (EncodingView, error) {
+	k, l, n, err := v.elems()
+	if err != nil {
+		return EncodingView{}, err
+	}
+	if k != Array && k != Slice && k != Struct {
+		return EncodingView{}, &DecodeError{v.i, fmt.Errorf("%w: %s cannot be indexed", ErrNoElem, k)}
+	}
+	if i < 0 || i >= l {
+		return EncodingView{}, &DecodeError{v.i, fmt.Errorf("%w: index %d of %s of len %d", ErrNoElem, i, k, l)}
+	}
+	for j := 0; j < i; j++ {
+		if n, err = v.e.validate(n, 0, nil); err != nil {
+			return EncodingView{}, err
+		}
+	}
+	var s *Schema
+	if v.s != nil {
+		if k != Struct {
+			s = v.s.Elem
+		} else if i < len(v.s.Fields) {
+			s = v.s.Fields[i].Schema
+		}
+	}
+	return v.at(n, s)
+}
+
+// Key returns a view of the elem of key k of the viewed map, skipping
+// over the elems before it. k is converted to the kind of the keys of
+// the map, which allocates its encoding. Returns a DecodeError if the
+// map has no key k or the encoding is truncated or corrupt, or a
+// ConversionError if k cannot be converted to the kind of the keys
+func (v EncodingView) Key(k any) (EncodingView, error) {
+	kind, l, n, err := v.elems()
+	if err != nil {
+		return EncodingView{}, err
+	}
+	if kind != Map {
+		return EncodingView{}, &DecodeError{v.i, fmt.Errorf("%w: %s has no keys", ErrNoElem, kind)}
+	}
+	kv := KIND(v.e[v.i+1]).NewValue().Elem()
+	if _, err = kv.SetWith(k, ConvertDefault); err != nil {
+		return EncodingView{}, err
+	}
+	key := kv.Encode()
+	var m int
+	for j := 0; j < l; j++ {
+		if m, err = v.e.validate(n, 0, nil); err != nil {
+			return EncodingView{}, err
+		}
+		if bytes.Equal(v.e[n:m], key) {
+			var s *Schema
+			if v.s != nil {
+				s = v.s.Elem
+			}
+			return v.at(m, s)
+		}
+		if n, err = v.e.validate(m, 0, nil); err != nil {
+			return EncodingView{}, err
+		}
+	}
+	return EncodingView{}, &DecodeError{v.i, fmt.Errorf("%w: key %v of map", ErrNoElem, k)}
+}
+
+// Field returns a view of the field of the viewed struct by name,
+// which requires the schema of the struct, see WithSchema. Returns a
+// DecodeError if the schema of the struct has no field by name or
+// is unknown, or the encoding is truncated or corrupt
+func (v EncodingView) Field(name string) (EncodingView, error) {
+	if v.s == nil || v.s.Kind != Struct {
+		return EncodingView{}, &DecodeError{v.i, fmt.Errorf("%w: field %s of value without struct schema", ErrNoElem, name)}
+	}
+	for i, f := range v.s.Fields {
+		if f.Name == name {
+			return v.Index(i)
+		}
+	}
+	return EncodingView{}, &DecodeError{v.i, fmt.Errorf("%w: field %s of struct", ErrNoElem, name)}
+}
+
+// Encoding returns the encoding of the viewed value as a slice of the
+// encoding viewed into, returns a DecodeError if it is truncated or
+// corrupt or contains references to pointers, which are numbered from
+// the start of the encoding viewed into and cannot be decoded apart
+func (v EncodingView) Encoding() (ENCODING, error) {
+	noRefs := -1
+	n, err := v.e.validate(v.i, 0, &noRefs)
+	if err != nil {
+		return nil, err
+	}
+	return v.e[v.i:n:n], nil
+}
+
+// Decode decodes the viewed value into pointer dest, see ENCODING.DecodeE
+func (v EncodingView) Decode(dest any) (int, error) {
+	e, err := v.Encoding()
+	if err != nil {
+		return 0, err
+	}
+	return e.DecodeE(dest)
+}
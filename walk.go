@@ -0,0 +1,230 @@
+// Copyright 2023 james dotter. All rights reserved.
+// Use of this source code is governed by a
+// license that can be found in the gotype LICENSE file.
+
+package gotype
+
+import (
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// ------------------------------------------------------------ /
+// WALK IMPLEMENTATION
+// traversal of a value graph with pre and post visitor hooks
+// and cycle detection on pointer identity
+// ------------------------------------------------------------ /
+
+// WalkAction instructs Walk how to proceed after visiting a node
+type WalkAction uint8
+
+const (
+	WalkContinue WalkAction = iota // continue walking into the node's children
+	WalkSkip                       // skip the node's children
+	WalkStop                       // stop walking altogether
+)
+
+// Visitor holds the hooks called by Walk on each node,
+// Pre is called before the node's children are walked
+// and Post is called after. Either hook may be nil
+type Visitor struct {
+	Pre  func(n *WalkNode) WalkAction
+	Post func(n *WalkNode) WalkAction
+}
+
+// WalkNode is a value visited by Walk
+type WalkNode struct {
+	Path  []string // keys, indexes and field names from the root to the node
+	Depth int      // number of containers between the root and the node
+	Field *FIELD   // the struct field of the node, nil if not in a struct
+	Value VALUE    // the value of the node, interfaces are unpacked
+	Cycle bool     // node references a pointer already walked in its ancestry
+	slot  VALUE    // location of the node in its container
+	mkey  string   // key of the node when in a map
+	m     *MAP     // map containing the node
+}
+
+// Key returns the last element of the node's path,
+// returns "" for the root
+func (n *WalkNode) Key() string {
+	if len(n.Path) == 0 {
+		return ""
+	}
+	return n.Path[len(n.Path)-1]
+}
+
+// PathString returns the node's path as a dot separated string
+func (n *WalkNode) PathString() string {
+	return strings.Join(n.Path, ".")
+}
+
+// Replace sets the value of the node in its container to a,
+// Walk continues into the children of the replaced value
+// if called from a Pre hook. Panics if the node is not addressable
+func (n *WalkNode) Replace(a any) {
+	if n.m != nil {
+		n.m.Set(n.mkey, a)
+		n.slot = n.m.Index(n.mkey)
+		n.Value = n.slot.SetType()
+		return
+	}
+	if n.slot.flag&flagIndir == 0 || n.slot.flag&flagAddr == 0 {
+		panic("cannot replace unaddressable value at '" + n.PathString() + "'")
+	}
+	v := ValueOfV(a).SetType()
+	if v.typ != n.slot.typ {
+		v = v.convert(n.slot.typ)
+	}
+	p := v.ptr
+	if v.flag&flagIndir == 0 {
+		p = unsafe.Pointer(&v.ptr)
+	}
+	typedmemmove(n.slot.typ, n.slot.ptr, p)
+	n.Value = n.slot.SetType()
+}
+
+// Walk traverses v depth first, calling the visitor's hooks on each value
+// including v, the values of pointers, interfaces, and the items of
+// arrays, slices, maps and structs. Values referencing a pointer already
+// walked in their ancestry are visited with Cycle set and not walked into.
+// Pass a pointer to v to replace values in place
+func Walk(v any, visitor Visitor) {
+	w := &walker{visitor: visitor, seen: map[ancestor]bool{}}
+	r := ValueOfV(v)
+	if r.typ == nil {
+		return
+	}
+	w.walk(&WalkNode{Value: r.SetType(), slot: r})
+}
+
+type walker struct {
+	visitor Visitor
+	seen    map[ancestor]bool
+}
+
+// walk visits node n and its children, returns true if walking should stop
+func (w *walker) walk(n *WalkNode) (stop bool) {
+	id, ref := walkRef(n.Value)
+	if ref && w.seen[id] {
+		n.Cycle = true
+	}
+	if w.visitor.Pre != nil {
+		switch w.visitor.Pre(n) {
+		case WalkStop:
+			return true
+		case WalkSkip:
+			return w.post(n)
+		}
+		if id, ref = walkRef(n.Value); ref && w.seen[id] {
+			n.Cycle = true
+		}
+	}
+	if n.Cycle {
+		return w.post(n)
+	}
+	if ref {
+		w.seen[id] = true
+		defer delete(w.seen, id)
+	}
+	if stop = w.children(n); stop {
+		return
+	}
+	return w.post(n)
+}
+
+func (w *walker) post(n *WalkNode) bool {
+	return w.visitor.Post != nil && w.visitor.Post(n) == WalkStop
+}
+
+// children walks the items contained in the value of node n
+func (w *walker) children(n *WalkNode) (stop bool) {
+	v := n.Value
+	if v.ptr == nil || v.typ == nil {
+		return
+	}
+	child := func(k string, slot VALUE, f *FIELD) bool {
+		return w.walk(&WalkNode{
+			Path:  append(n.Path[:len(n.Path):len(n.Path)], k),
+			Depth: n.Depth + 1,
+			Field: f,
+			Value: slot.SetType(),
+			slot:  slot,
+		})
+	}
+	switch v.Kind() {
+	case Pointer:
+		if v.IsNil() {
+			return
+		}
+		e := v.Elem()
+		return w.walk(&WalkNode{
+			Path:  n.Path,
+			Depth: n.Depth,
+			Field: n.Field,
+			Value: e.SetType(),
+			slot:  e,
+		})
+	case Struct:
+		if v.KIND() == Time {
+			return
+		}
+		(STRUCT)(v).ForFields(true, func(i int, f FIELD) (brake bool) {
+			stop = child(f.name, f.VALUE(), &f)
+			return stop
+		})
+	case Slice:
+		if v.KIND() == Bytes {
+			return
+		}
+		t, h := v.typ.Elem(), (*sliceHeader)(v.ptr)
+		for i := 0; i < h.Len && !stop; i++ {
+			e := VALUE{t, offset(h.Data, uintptr(i)*t.size), flagAddr | flagIndir | flag(t.Kind())}
+			stop = child(strconv.Itoa(i), e, nil)
+		}
+	case Array:
+		if v.KIND() == Uuid {
+			return
+		}
+		t, l := v.typ.Elem(), (ARRAY)(v).Len()
+		for i := 0; i < l && !stop; i++ {
+			e := VALUE{t, offset(v.ptr, uintptr(i)*t.size), v.flag&(flagIndir|flagAddr) | flag(t.Kind())}
+			stop = child(strconv.Itoa(i), e, nil)
+		}
+	case Map:
+		m := (MAP)(v)
+		for _, k := range m.Keys() {
+			e := m.Index(k)
+			if stop = w.walk(&WalkNode{
+				Path:  append(n.Path[:len(n.Path):len(n.Path)], k),
+				Depth: n.Depth + 1,
+				Value: e.SetType(),
+				slot:  e,
+				mkey:  k,
+				m:     &m,
+			}); stop {
+				break
+			}
+		}
+	}
+	return
+}
+
+// walkRef returns the identity of the reference held by v,
+// ref is false if v does not hold a pointer, map or slice
+func walkRef(v VALUE) (id ancestor, ref bool) {
+	if v.typ == nil || v.ptr == nil {
+		return
+	}
+	switch v.Kind() {
+	case Pointer, Map:
+		if p := v.Pointer(); p != nil {
+			return ancestor{v.typ, uintptr(p)}, true
+		}
+	case Slice:
+		if h := (*sliceHeader)(v.ptr); h.Data != nil && h.Len > 0 {
+			return ancestor{v.typ, uintptr(h.Data)}, true
+		}
+	}
+	return
+}